@@ -0,0 +1,113 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	fixed_income_core "github.com/silasstoffel/invest-tracker/apps/fixed_income/core"
+	fixed_income_service "github.com/silasstoffel/invest-tracker/apps/fixed_income/service"
+	"github.com/silasstoffel/invest-tracker/apps/shared/database"
+	"github.com/silasstoffel/invest-tracker/apps/shared/repository"
+)
+
+func accrue(db database.DB, args []string) error {
+	var date string
+	var dryRun, asJson bool
+
+	flags := flag.NewFlagSet("accrue", flag.ContinueOnError)
+	flags.StringVar(&date, "date", "", "accrue up to this date (YYYY-MM-DD), defaults to today")
+	flags.BoolVar(&dryRun, "dry-run", false, "print the values without storing them")
+	flags.BoolVar(&asJson, "json", false, "print the accrual as json")
+
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	today := time.Now()
+	if date != "" {
+		var err error
+		if today, err = time.Parse("2006-01-02", date); err != nil {
+			return fmt.Errorf("invalid date %s, use YYYY-MM-DD", date)
+		}
+	}
+
+	service := fixed_income_service.NewService(
+		repository.NewInvestmentRepository(db),
+		repository.NewSummaryRepository(db),
+		repository.NewIndexSeriesRepository(db),
+		repository.NewIssuerRepository(db),
+	)
+	report, err := service.Accrue(context.Background(), today, dryRun)
+	if err != nil {
+		return err
+	}
+
+	if asJson {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(report)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(w, "symbol\tbrokerage\tindex\trate\tstart\tdue\tbusiness days\tprincipal\tgross value\tinterest\t")
+	for _, bond := range report.Bonds {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%d\t%s\t%s\t%s\t\n",
+			bond.Symbol, bond.Brokerage, bond.BondIndex, bond.BondRate.String(), bond.StartDate, bond.DueDate,
+			bond.BusinessDays, bond.Principal.StringFixed(2), bond.GrossValue.StringFixed(2), bond.Interest.StringFixed(2))
+	}
+	fmt.Fprintf(w, "total\t\t\t\t\t\t\t%s\t%s\t%s\t\n",
+		report.Principal.StringFixed(2), report.GrossValue.StringFixed(2), report.Interest.StringFixed(2))
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	for _, skipped := range report.Skipped {
+		fmt.Printf("skipped %s\n", skipped)
+	}
+	if dryRun {
+		fmt.Println("dry run, nothing was written")
+	}
+	return nil
+}
+
+func indexSeries(db database.DB, args []string) error {
+	var index, file string
+
+	flags := flag.NewFlagSet("index-series", flag.ContinueOnError)
+	flags.StringVar(&index, "index", "", "cdi, selic or ipca")
+	flags.StringVar(&file, "file", "", "csv with a date and a value per line, such as the BCB SGS export")
+
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if index == "" || file == "" {
+		return fmt.Errorf("index and file are required")
+	}
+
+	reader, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+
+	index = strings.ToLower(index)
+	rates, err := fixed_income_core.ParseSeries(reader, index)
+	if err != nil {
+		return err
+	}
+
+	if err := repository.NewIndexSeriesRepository(db).Save(context.Background(), index, rates); err != nil {
+		return err
+	}
+
+	if len(rates) > 0 {
+		fmt.Printf("saved %d %s rate(s) from %s to %s\n", len(rates), index, rates[0].Date, rates[len(rates)-1].Date)
+	}
+	return nil
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	fixed_income_service "github.com/silasstoffel/invest-tracker/apps/fixed_income/service"
+	"github.com/silasstoffel/invest-tracker/apps/shared/database"
+	"github.com/silasstoffel/invest-tracker/apps/shared/repository"
+)
+
+func exposure(db database.DB, args []string) error {
+	var asJson bool
+
+	flags := flag.NewFlagSet("exposure", flag.ContinueOnError)
+	flags.BoolVar(&asJson, "json", false, "print the exposure as json")
+
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	service := fixed_income_service.NewService(
+		repository.NewInvestmentRepository(db),
+		repository.NewSummaryRepository(db),
+		repository.NewIndexSeriesRepository(db),
+		repository.NewIssuerRepository(db),
+	)
+	report, err := service.Exposure(context.Background(), time.Now())
+	if err != nil {
+		return err
+	}
+
+	if asJson {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(report)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(w, "conglomerate\tissuers\tbonds\tprincipal\tprojected\tfgc principal\tfgc projected\tover limit\t")
+	for _, conglomerate := range report.Conglomerates {
+		over := ""
+		if conglomerate.ExceedsFgcLimit {
+			over = "yes"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%d\t%s\t%s\t%s\t%s\t%s\t\n",
+			conglomerate.Conglomerate, strings.Join(conglomerate.Issuers, ", "), len(conglomerate.Bonds),
+			conglomerate.Principal.StringFixed(2), conglomerate.ProjectedValue.StringFixed(2),
+			conglomerate.CoveredPrincipal.StringFixed(2), conglomerate.CoveredProjectedValue.StringFixed(2), over)
+	}
+	fmt.Fprintf(w, "total\t\t\t%s\t%s\t\t\t\t\n", report.Principal.StringFixed(2), report.ProjectedValue.StringFixed(2))
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	for _, unprojected := range report.Unprojected {
+		fmt.Printf("not projected %s\n", unprojected)
+	}
+	fmt.Printf("FGC limit per conglomerate: %s\n", report.FgcLimit.StringFixed(2))
+	return nil
+}
+
+func issuer(db database.DB, args []string) error {
+	var name, conglomerate string
+
+	flags := flag.NewFlagSet("issuer", flag.ContinueOnError)
+	flags.StringVar(&name, "name", "", "issuer as informed on the investments, such as BANCO BARI")
+	flags.StringVar(&conglomerate, "conglomerate", "", "financial conglomerate the issuer belongs to")
+
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	name, conglomerate = strings.TrimSpace(name), strings.TrimSpace(conglomerate)
+	if name == "" || conglomerate == "" {
+		return fmt.Errorf("name and conglomerate are required")
+	}
+
+	err := repository.NewIssuerRepository(db).Save(context.Background(), repository.IssuerConglomerate{
+		Issuer:       strings.ToUpper(name),
+		Conglomerate: conglomerate,
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%s belongs to %s\n", strings.ToUpper(name), conglomerate)
+	return nil
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	reports_core "github.com/silasstoffel/invest-tracker/apps/reports/core"
+	reports_service "github.com/silasstoffel/invest-tracker/apps/reports/service"
+	"github.com/silasstoffel/invest-tracker/apps/shared/database"
+	"github.com/silasstoffel/invest-tracker/apps/shared/repository"
+)
+
+func income(db database.DB, args []string) error {
+	var filter repository.InvestmentFilter
+	var bySymbol, asJson bool
+
+	flags := flag.NewFlagSet("income", flag.ContinueOnError)
+	flags.StringVar(&filter.Symbol, "symbol", "", "only incomes of this symbol")
+	flags.StringVar(&filter.Type, "type", "", "only incomes of this investment type")
+	flags.StringVar(&filter.Brokerage, "brokerage", "", "only incomes in this brokerage")
+	flags.IntVar(&filter.Year, "year", 0, "only incomes in this year")
+	flags.StringVar(&filter.From, "from", "", "incomes since this date (YYYY-MM-DD)")
+	flags.StringVar(&filter.To, "to", "", "incomes until this date (YYYY-MM-DD)")
+	flags.BoolVar(&bySymbol, "by-symbol", false, "one line per symbol instead of per month and symbol")
+	flags.BoolVar(&asJson, "json", false, "print the report as json")
+
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	service := reports_service.NewIncomeService(repository.NewInvestmentRepository(db), repository.NewSummaryRepository(db))
+	report, err := service.IncomeReport(context.Background(), filter)
+	if err != nil {
+		return err
+	}
+
+	if asJson {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(report)
+	}
+
+	lines := report.Lines
+	if bySymbol {
+		lines = report.Symbols
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(w, "period\tsymbol\tincomes\tgross\twithheld\tnet\tamortization\tyield on cost %\t")
+	for _, line := range lines {
+		printIncomeLine(w, line)
+	}
+	total := report.Total
+	total.Period = "total"
+	printIncomeLine(w, total)
+
+	return w.Flush()
+}
+
+func printIncomeLine(w *tabwriter.Writer, line reports_core.IncomeLine) {
+	fmt.Fprintf(w, "%s\t%s\t%d\t%s\t%s\t%s\t%s\t%s\t\n",
+		line.Period, line.Symbol, line.Incomes,
+		line.Gross.StringFixed(2), line.TaxWithheld.StringFixed(2), line.Net.StringFixed(2),
+		line.Amortization.StringFixed(2), line.YieldOnCost.StringFixed(2))
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	fixed_income_core "github.com/silasstoffel/invest-tracker/apps/fixed_income/core"
+	fixed_income_service "github.com/silasstoffel/invest-tracker/apps/fixed_income/service"
+	"github.com/silasstoffel/invest-tracker/apps/shared/database"
+	"github.com/silasstoffel/invest-tracker/apps/shared/repository"
+)
+
+func ladder(db database.DB, args []string) error {
+	var by string
+	var asJson bool
+
+	flags := flag.NewFlagSet("ladder", flag.ContinueOnError)
+	flags.StringVar(&by, "by", "month", "month or year")
+	flags.BoolVar(&asJson, "json", false, "print the ladder as json")
+
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if by != "month" && by != "year" {
+		return fmt.Errorf("invalid by %s, use month or year", by)
+	}
+
+	service := fixed_income_service.NewService(
+		repository.NewInvestmentRepository(db),
+		repository.NewSummaryRepository(db),
+		repository.NewIndexSeriesRepository(db),
+		repository.NewIssuerRepository(db),
+	)
+	report, err := service.MaturityLadder(context.Background(), time.Now())
+	if err != nil {
+		return err
+	}
+
+	if asJson {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(report)
+	}
+
+	buckets := report.Months
+	if by == "year" {
+		buckets = report.Years
+	}
+
+	policies := []string{}
+	for policy := range report.ByRedemptionPolicy {
+		policies = append(policies, policy)
+	}
+	sort.Strings(policies)
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintf(w, "%s\tbonds\tprincipal\tprojected\t%s\t\n", by, strings.Join(policyHeaders(policies), "\t"))
+	for _, bucket := range buckets {
+		fmt.Fprintf(w, "%s\t%d\t%s\t%s\t%s\t\n", bucket.Period, bucket.Count,
+			bucket.Principal.StringFixed(2), bucket.ProjectedValue.StringFixed(2), policyColumns(bucket.ByRedemptionPolicy, policies))
+	}
+	if len(report.Undated) > 0 {
+		undated := fixed_income_core.BuildLadder(report.Date, report.Undated, nil)
+		fmt.Fprintf(w, "no due date\t%d\t%s\t%s\t%s\t\n", undated.Count,
+			undated.Principal.StringFixed(2), undated.ProjectedValue.StringFixed(2), policyColumns(undated.ByRedemptionPolicy, policies))
+	}
+	fmt.Fprintf(w, "total\t%d\t%s\t%s\t%s\t\n", report.Count,
+		report.Principal.StringFixed(2), report.ProjectedValue.StringFixed(2), policyColumns(report.ByRedemptionPolicy, policies))
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	for _, unprojected := range report.Unprojected {
+		fmt.Printf("not projected %s\n", unprojected)
+	}
+	return nil
+}
+
+func policyHeaders(policies []string) []string {
+	headers := []string{}
+	for _, policy := range policies {
+		if policy == "" {
+			policy = "no policy"
+		}
+		headers = append(headers, policy)
+	}
+	return headers
+}
+
+// policyColumns prints the projected value of each redemption policy.
+func policyColumns(totals map[string]fixed_income_core.LadderTotal, policies []string) string {
+	columns := []string{}
+	for _, policy := range policies {
+		total, ok := totals[policy]
+		if !ok {
+			columns = append(columns, "-")
+			continue
+		}
+		columns = append(columns, total.ProjectedValue.StringFixed(2))
+	}
+	return strings.Join(columns, "\t")
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	investment_core "github.com/silasstoffel/invest-tracker/apps/investments/core"
+	portfolio_core "github.com/silasstoffel/invest-tracker/apps/portfolio/core"
+	"github.com/silasstoffel/invest-tracker/apps/shared/database"
+	"github.com/silasstoffel/invest-tracker/apps/shared/repository"
+)
+
+func liquidity(db database.DB, args []string) error {
+	var verbose, asJson bool
+
+	flags := flag.NewFlagSet("liquidity", flag.ContinueOnError)
+	flags.BoolVar(&verbose, "positions", false, "list the positions of each bucket")
+	flags.BoolVar(&asJson, "json", false, "print the liquidity as json")
+
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	summaries, err := repository.NewSummaryRepository(db).Find(ctx, repository.SummaryFilter{})
+	if err != nil {
+		return err
+	}
+	bonds, err := repository.NewInvestmentRepository(db).Find(ctx, repository.InvestmentFilter{
+		Type:          investment_core.BondInvestmentType,
+		OperationType: investment_core.BuyOperationType,
+	})
+	if err != nil {
+		return err
+	}
+
+	purchases := map[string]investment_core.InvestmentEntity{}
+	for _, bond := range bonds {
+		purchases[bond.ID] = bond
+	}
+	report := portfolio_core.BuildLiquidity(summaries, purchases, time.Now())
+
+	if asJson {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(report)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(w, "liquid\tpositions\tvalue\t%\tcumulative\tcumulative %\t")
+	for _, bucket := range report.Buckets {
+		fmt.Fprintf(w, "%s\t%d\t%s\t%s\t%s\t%s\t\n", bucket.Name, len(bucket.Positions),
+			bucket.Value.StringFixed(2), bucket.Percentage.StringFixed(2),
+			bucket.Cumulative.StringFixed(2), bucket.CumulativePercentage.StringFixed(2))
+	}
+	fmt.Fprintf(w, "total\t\t%s\t\t\t\t\n", report.Total.StringFixed(2))
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	if !verbose {
+		return nil
+	}
+
+	fmt.Println()
+	w = tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(w, "liquid\tsymbol\tbrokerage\ttype\tpolicy\tavailable on\tvalue\t")
+	for _, bucket := range report.Buckets {
+		for _, position := range bucket.Positions {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t\n", bucket.Name, position.Symbol, position.Brokerage,
+				position.Type, position.RedemptionPolicyType, position.AvailableOn, position.Value.StringFixed(2))
+		}
+	}
+	return w.Flush()
+}
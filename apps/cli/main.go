@@ -0,0 +1,82 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/silasstoffel/invest-tracker/apps/shared/database"
+	appConfig "github.com/silasstoffel/invest-tracker/config"
+)
+
+const usage = `Usage: go run ./apps/cli <command> [arguments]
+
+Commands:
+  migrate up                  apply pending migrations
+  migrate status              list migrations and when they were applied
+  migrate baseline <version>  mark migrations up to <version> as applied without running them
+  rebuild [flags]             rebuild investments_summary from investments, see rebuild -h
+  symbol-change [flags]       move positions to a new ticker, see symbol-change -h
+  pnl [flags]                 realized profit and loss by month or year, see pnl -h
+  tax [flags]                 monthly income tax (IR) and DARF due, see tax -h
+  income [flags]              dividends, JCP and FII income by month and symbol, see income -h
+  valuation [flags]           market value and unrealized profit and loss, see valuation -h
+  index-series [flags]        import cdi, selic or ipca rates from a csv, see index-series -h
+  accrue [flags]              accrue bonds and store their market value, see accrue -h
+  exposure [flags]            bonds by issuer conglomerate against the FGC limit, see exposure -h
+  issuer [flags]              set the conglomerate of a bond issuer, see issuer -h
+  ladder [flags]              bonds by month or year of maturity, see ladder -h
+  liquidity [flags]           portfolio value by when it can be turned into cash, see liquidity -h
+  settle [flags]              confirm the amount of a matured bond or list pending maturities, see settle -h
+
+The database is selected by DATABASE_DRIVER (d1 or sqlite), see config/env.go.`
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Println(usage)
+		os.Exit(1)
+	}
+
+	env := appConfig.NewConfigFromEnvVars()
+	db, err := database.NewFromConfig(env)
+	if err != nil {
+		log.Fatalf("Failure to connect to database: %v", err)
+	}
+
+	switch os.Args[1] {
+	case "migrate":
+		err = migrate(db, os.Args[2:])
+	case "rebuild":
+		err = rebuild(db, os.Args[2:])
+	case "symbol-change":
+		err = symbolChange(db, os.Args[2:])
+	case "pnl":
+		err = pnl(db, os.Args[2:])
+	case "tax":
+		err = tax(db, os.Args[2:])
+	case "income":
+		err = income(db, os.Args[2:])
+	case "valuation":
+		err = valuation(db, env, os.Args[2:])
+	case "index-series":
+		err = indexSeries(db, os.Args[2:])
+	case "accrue":
+		err = accrue(db, os.Args[2:])
+	case "exposure":
+		err = exposure(db, os.Args[2:])
+	case "issuer":
+		err = issuer(db, os.Args[2:])
+	case "ladder":
+		err = ladder(db, os.Args[2:])
+	case "liquidity":
+		err = liquidity(db, os.Args[2:])
+	case "settle":
+		err = settle(db, os.Args[2:])
+	default:
+		err = fmt.Errorf("unknown command: %s\n\n%s", os.Args[1], usage)
+	}
+
+	if err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"text/tabwriter"
+
+	"github.com/silasstoffel/invest-tracker/apps/shared/database"
+)
+
+func migrate(db database.DB, args []string) error {
+	ctx := context.Background()
+
+	if len(args) == 0 {
+		return fmt.Errorf("migrate requires a subcommand: up, status or baseline")
+	}
+
+	switch args[0] {
+	case "up":
+		applied, err := database.Up(ctx, db)
+		for _, m := range applied {
+			fmt.Printf("applied %04d_%s\n", m.Version, m.Name)
+		}
+		if err != nil {
+			return err
+		}
+		if len(applied) == 0 {
+			fmt.Println("database is up to date")
+		}
+		return nil
+
+	case "status":
+		statuses, err := database.Status(ctx, db)
+		if err != nil {
+			return err
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "VERSION\tNAME\tAPPLIED AT")
+		for _, s := range statuses {
+			appliedAt := s.AppliedAt
+			if !s.Applied() {
+				appliedAt = "pending"
+			}
+			fmt.Fprintf(w, "%04d\t%s\t%s\n", s.Version, s.Name, appliedAt)
+		}
+		return w.Flush()
+
+	case "baseline":
+		if len(args) < 2 {
+			return fmt.Errorf("migrate baseline requires a version")
+		}
+		version, err := strconv.Atoi(args[1])
+		if err != nil {
+			return fmt.Errorf("invalid version: %s", args[1])
+		}
+
+		recorded, err := database.Baseline(ctx, db, version)
+		for _, m := range recorded {
+			fmt.Printf("recorded %04d_%s\n", m.Version, m.Name)
+		}
+		return err
+
+	default:
+		return fmt.Errorf("unknown migrate subcommand: %s", args[0])
+	}
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	reports_core "github.com/silasstoffel/invest-tracker/apps/reports/core"
+	reports_service "github.com/silasstoffel/invest-tracker/apps/reports/service"
+	"github.com/silasstoffel/invest-tracker/apps/shared/database"
+	"github.com/silasstoffel/invest-tracker/apps/shared/repository"
+)
+
+func pnl(db database.DB, args []string) error {
+	var filter repository.InvestmentFilter
+	var period, groupBy string
+	var asJson bool
+
+	flags := flag.NewFlagSet("pnl", flag.ContinueOnError)
+	flags.StringVar(&filter.Symbol, "symbol", "", "only sells of this symbol")
+	flags.StringVar(&filter.Type, "type", "", "only sells of this investment type")
+	flags.StringVar(&filter.Brokerage, "brokerage", "", "only sells in this brokerage")
+	flags.IntVar(&filter.Year, "year", 0, "only sells in this year")
+	flags.StringVar(&filter.From, "from", "", "sells since this date (YYYY-MM-DD)")
+	flags.StringVar(&filter.To, "to", "", "sells until this date (YYYY-MM-DD)")
+	flags.StringVar(&period, "period", reports_core.MonthlyPeriod, "month or year")
+	flags.StringVar(&groupBy, "group-by", "type,symbol,brokerage", "comma separated dimensions, empty for period totals only")
+	flags.BoolVar(&asJson, "json", false, "print the report as json")
+
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	dimensions := []string{}
+	if groupBy != "" {
+		dimensions = strings.Split(groupBy, ",")
+	}
+
+	service := reports_service.NewService(repository.NewInvestmentRepository(db))
+	report, err := service.PnlReport(context.Background(), filter, period, dimensions)
+	if err != nil {
+		return err
+	}
+
+	if asJson {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(report)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(w, "period\ttype\tsymbol\tbrokerage\tsales\tproceeds\tcost basis\tfees\ttaxes\tgross\tnet\tday trade\t")
+	for _, line := range report.Lines {
+		printPnlLine(w, line)
+	}
+	total := report.Total
+	total.Period = "total"
+	printPnlLine(w, total)
+
+	return w.Flush()
+}
+
+func printPnlLine(w *tabwriter.Writer, line reports_core.PnlLine) {
+	fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%d\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t\n",
+		line.Period, line.Type, line.Symbol, line.Brokerage, line.Sales,
+		line.Proceeds.StringFixed(2), line.CostBasis.StringFixed(2), line.Fees.StringFixed(2), line.TaxWithheld.StringFixed(2),
+		line.GrossResult.StringFixed(2), line.NetResult.StringFixed(2), line.DayTradeResult.StringFixed(2))
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+
+	investment_summary_service "github.com/silasstoffel/invest-tracker/apps/investments_summary/service"
+	"github.com/silasstoffel/invest-tracker/apps/shared/database"
+	"github.com/silasstoffel/invest-tracker/apps/shared/repository"
+)
+
+func rebuild(db database.DB, args []string) error {
+	var filter investment_summary_service.RebuildFilter
+	var dryRun, verbose bool
+
+	flags := flag.NewFlagSet("rebuild", flag.ContinueOnError)
+	flags.StringVar(&filter.Symbol, "symbol", "", "rebuild only this symbol")
+	flags.StringVar(&filter.Type, "type", "", "rebuild only this investment type")
+	flags.StringVar(&filter.Brokerage, "brokerage", "", "rebuild only this brokerage")
+	flags.StringVar(&filter.From, "from", "", "rebuild positions with operations since this date (YYYY-MM-DD)")
+	flags.StringVar(&filter.To, "to", "", "rebuild positions with operations until this date (YYYY-MM-DD)")
+	flags.BoolVar(&dryRun, "dry-run", false, "print the differences without writing them")
+	flags.BoolVar(&verbose, "verbose", false, "also print unchanged positions")
+
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	service := investment_summary_service.NewService(
+		repository.NewInvestmentRepository(db),
+		repository.NewSummaryRepository(db),
+	)
+
+	changes, err := service.Rebuild(context.Background(), filter, dryRun)
+	for _, change := range changes {
+		if change.Action == investment_summary_service.RebuildUnchanged && !verbose {
+			continue
+		}
+		fmt.Println(change.String())
+	}
+
+	return err
+}
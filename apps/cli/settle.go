@@ -0,0 +1,85 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	fixed_income_service "github.com/silasstoffel/invest-tracker/apps/fixed_income/service"
+	"github.com/silasstoffel/invest-tracker/apps/shared/database"
+	"github.com/silasstoffel/invest-tracker/apps/shared/decimal"
+	"github.com/silasstoffel/invest-tracker/apps/shared/repository"
+)
+
+// settle confirms the amount received on the maturity of a bond or, without
+// flags, lists what the settle-matured-bonds job would schedule.
+func settle(db database.DB, args []string) error {
+	var summaryID, amount string
+	var asJson bool
+
+	flags := flag.NewFlagSet("settle", flag.ContinueOnError)
+	flags.StringVar(&summaryID, "summary", "", "id of the matured bond position")
+	flags.StringVar(&amount, "amount", "", "gross amount received on the maturity")
+	flags.BoolVar(&asJson, "json", false, "print the settlements as json")
+
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	settlements := repository.NewBondSettlementRepository(db)
+
+	if summaryID != "" || amount != "" {
+		value, err := decimal.NewFromString(amount)
+		if summaryID == "" || err != nil || !value.IsPositive() {
+			return fmt.Errorf("summary and a positive amount are required")
+		}
+		if err := settlements.Confirm(ctx, summaryID, value); err != nil {
+			return err
+		}
+		fmt.Printf("confirmed %s for %s, settled on the next run of settle-matured-bonds\n", value.Money().StringFixed(2), summaryID)
+		return nil
+	}
+
+	service := fixed_income_service.NewService(
+		repository.NewInvestmentRepository(db),
+		repository.NewSummaryRepository(db),
+		repository.NewIndexSeriesRepository(db),
+		repository.NewIssuerRepository(db),
+	)
+	// a dry run never sends the redemptions
+	settler := fixed_income_service.NewSettler(service, repository.NewOperationStatusRepository(db), settlements, nil, "")
+	report, err := settler.Settle(ctx, time.Now(), true)
+	if err != nil {
+		return err
+	}
+
+	if asJson {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(report)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(w, "summary\tsymbol\tbrokerage\tdue\tprincipal\tamount\tsource\t")
+	for _, settlement := range report.Settled {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t\n", settlement.SummaryID, settlement.Symbol, settlement.Brokerage,
+			settlement.DueDate, settlement.Principal.StringFixed(2), settlement.Amount.StringFixed(2), settlement.Source)
+	}
+	for _, settlement := range report.Unconfirmed {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t\tunconfirmed\t\n", settlement.SummaryID, settlement.Symbol, settlement.Brokerage,
+			settlement.DueDate, settlement.Principal.StringFixed(2))
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	for _, skipped := range report.Skipped {
+		fmt.Printf("skipped %s\n", skipped)
+	}
+	return nil
+}
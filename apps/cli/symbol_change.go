@@ -0,0 +1,46 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+
+	investment_summary_service "github.com/silasstoffel/invest-tracker/apps/investments_summary/service"
+	"github.com/silasstoffel/invest-tracker/apps/shared/database"
+	"github.com/silasstoffel/invest-tracker/apps/shared/decimal"
+	"github.com/silasstoffel/invest-tracker/apps/shared/repository"
+)
+
+func symbolChange(db database.DB, args []string) error {
+	var change investment_summary_service.SymbolChange
+	var ratio string
+
+	flags := flag.NewFlagSet("symbol-change", flag.ContinueOnError)
+	flags.StringVar(&change.OldSymbol, "from", "", "ticker that changed")
+	flags.StringVar(&change.NewSymbol, "to", "", "new ticker")
+	flags.StringVar(&ratio, "ratio", "1", "new shares for each old share")
+	flags.StringVar(&change.Date, "date", "", "date of the change (YYYY-MM-DD), today by default")
+
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	value, err := decimal.NewFromString(ratio)
+	if err != nil {
+		return fmt.Errorf("invalid ratio %s: %w", ratio, err)
+	}
+	change.Ratio = value
+
+	service := investment_summary_service.NewSymbolChangeService(
+		repository.NewInvestmentRepository(db),
+		repository.NewSummaryRepository(db),
+		repository.NewSymbolAliasRepository(db),
+	)
+
+	changes, err := service.ChangeSymbol(context.Background(), change)
+	for _, change := range changes {
+		fmt.Println(change.String())
+	}
+
+	return err
+}
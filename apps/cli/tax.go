@@ -0,0 +1,53 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/silasstoffel/invest-tracker/apps/shared/database"
+	"github.com/silasstoffel/invest-tracker/apps/shared/repository"
+	tax_service "github.com/silasstoffel/invest-tracker/apps/tax/service"
+)
+
+func tax(db database.DB, args []string) error {
+	var year int
+	var asJson bool
+
+	flags := flag.NewFlagSet("tax", flag.ContinueOnError)
+	flags.IntVar(&year, "year", 0, "only months of this year")
+	flags.BoolVar(&asJson, "json", false, "print the months as json, with the detail of each category")
+
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	service := tax_service.NewService(repository.NewInvestmentRepository(db))
+	months, err := service.MonthlyTax(context.Background(), year)
+	if err != nil {
+		return err
+	}
+
+	if asJson {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(months)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(w, "month\tcategory\tsales\tresult\texempt\tloss used\tloss carried\tbase\ttax\twithheld\t")
+	for _, month := range months {
+		for _, category := range month.Categories {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t\n",
+				month.Month, category.Category, category.Sales.StringFixed(2), category.Result.StringFixed(2),
+				category.ExemptResult.StringFixed(2), category.LossUsed.StringFixed(2), category.LossCarried.StringFixed(2),
+				category.TaxableBase.StringFixed(2), category.Tax.StringFixed(2), category.Withheld.StringFixed(2))
+		}
+		fmt.Fprintf(w, "%s\tDARF\t\t\t\t\t\t\t%s\t(carried %s)\t\n", month.Month, month.Darf.StringFixed(2), month.CarriedDue.StringFixed(2))
+	}
+
+	return w.Flush()
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/silasstoffel/invest-tracker/apps/shared/database"
+	"github.com/silasstoffel/invest-tracker/apps/shared/quotes"
+	"github.com/silasstoffel/invest-tracker/apps/shared/repository"
+	valuation_core "github.com/silasstoffel/invest-tracker/apps/valuation/core"
+	valuation_service "github.com/silasstoffel/invest-tracker/apps/valuation/service"
+	appConfig "github.com/silasstoffel/invest-tracker/config"
+)
+
+func valuation(db database.DB, env *appConfig.Config, args []string) error {
+	var update, asJson bool
+	var source string
+
+	flags := flag.NewFlagSet("valuation", flag.ContinueOnError)
+	flags.BoolVar(&update, "update", false, "read the quotes and store the market value of the positions")
+	flags.StringVar(&source, "quotes", env.Quotes.Source, "csv file or url with the quotes, defaults to QUOTES_SOURCE")
+	flags.BoolVar(&asJson, "json", false, "print the valuation as json")
+
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	summaries := repository.NewSummaryRepository(db)
+	ctx := context.Background()
+
+	var result valuation_core.Valuation
+	var err error
+	if update {
+		config := *env
+		config.Quotes.Source = source
+		provider, err := quotes.NewFromConfig(&config)
+		if err != nil {
+			return err
+		}
+		result, err = valuation_service.NewService(summaries, provider).Revalue(ctx, time.Now())
+		if err != nil {
+			return err
+		}
+	} else {
+		result, err = valuation_service.NewService(summaries, nil).Current(ctx, time.Now())
+		if err != nil {
+			return err
+		}
+	}
+
+	if asJson {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(result)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(w, "symbol\ttype\tbrokerage\tquantity\taverage price\tprice\ttotal value\tmarket value\tunrealized\tunrealized %\t")
+	for _, position := range result.Positions {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t\n",
+			position.Symbol, position.Type, position.Brokerage, position.Quantity.String(),
+			position.AveragePrice.StringFixed(2), position.Price.StringFixed(2),
+			position.TotalValue.StringFixed(2), position.MarketValue.StringFixed(2),
+			position.UnrealizedPnl.StringFixed(2), position.UnrealizedPnlPercentage.StringFixed(2))
+	}
+	fmt.Fprintf(w, "total\t\t\t\t\t\t%s\t%s\t%s\t%s\t\n",
+		result.TotalValue.StringFixed(2), result.MarketValue.StringFixed(2),
+		result.UnrealizedPnl.StringFixed(2), result.UnrealizedPnlPercentage.StringFixed(2))
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	for _, position := range result.Unpriced {
+		fmt.Printf("no price for %s\n", position)
+	}
+	return nil
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-lambda-go/lambda"
+	fixed_income_service "github.com/silasstoffel/invest-tracker/apps/fixed_income/service"
+	"github.com/silasstoffel/invest-tracker/apps/shared/database"
+	"github.com/silasstoffel/invest-tracker/apps/shared/repository"
+	"github.com/silasstoffel/invest-tracker/apps/shared/telegram"
+	appConfig "github.com/silasstoffel/invest-tracker/config"
+)
+
+var (
+	env     *appConfig.Config
+	service *fixed_income_service.Service
+)
+
+func init() {
+	env = appConfig.NewConfigFromEnvVars()
+
+	db, err := database.NewFromConfig(env)
+	if err != nil {
+		m := fmt.Sprintf("Failure to connect to database: %v", err)
+		log.Println(m)
+		panic(m)
+	}
+
+	service = fixed_income_service.NewService(
+		repository.NewInvestmentRepository(db),
+		repository.NewSummaryRepository(db),
+		repository.NewIndexSeriesRepository(db),
+		repository.NewIssuerRepository(db),
+	)
+}
+
+func Handler(ctx context.Context) error {
+	prefix := os.Getenv("AWS_LAMBDA_FUNCTION_NAME")
+	tb := telegram.NewTelegramBot(env)
+
+	report, err := service.Accrue(ctx, time.Now(), false)
+	if err != nil {
+		log.Printf("Failure to accrue bonds: %v", err)
+		tb.SendMessage(fmt.Sprintf("*[%s] Failure to accrue bonds* ```%s```", prefix, err.Error()))
+		return err
+	}
+
+	log.Printf("Accrued %d bond(s), %d skipped. Principal %s, gross value %s", len(report.Bonds), len(report.Skipped), report.Principal, report.GrossValue)
+
+	if len(report.Skipped) > 0 {
+		tb.SendMessage(fmt.Sprintf("*[%s] %d bond(s) could not be accrued* ```%s```", prefix, len(report.Skipped), strings.Join(report.Skipped, "\n")))
+	}
+
+	return nil
+}
+
+func main() {
+	lambda.Start(Handler)
+}
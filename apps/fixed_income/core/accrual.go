@@ -0,0 +1,184 @@
+package fixed_income_core
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	investment_core "github.com/silasstoffel/invest-tracker/apps/investments/core"
+	"github.com/silasstoffel/invest-tracker/apps/shared/decimal"
+)
+
+const dateLayout = "2006-01-02"
+
+// factorPlaces bounds the precision of the accumulated factors, exact products
+// of hundreds of daily factors would grow without limit.
+const factorPlaces = 16
+
+var (
+	one          = decimal.NewFromInt(1)
+	hundred      = decimal.NewFromInt(100)
+	yearBusiness = decimal.NewFromInt(252)
+)
+
+func parseDate(value string) (time.Time, error) {
+	return time.Parse(dateLayout, value)
+}
+
+func truncateDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// Sort orders the rates of every index by date.
+func (s Series) Sort() {
+	for _, rates := range s {
+		sort.Slice(rates, func(i, j int) bool { return rates[i].Date < rates[j].Date })
+	}
+}
+
+// rateAt returns the latest rate published up to date. Days after the last
+// published rate are projected with it.
+func (s Series) rateAt(index, date string) (decimal.Decimal, bool) {
+	rates := s[index]
+	i := sort.Search(len(rates), func(i int) bool { return rates[i].Date > date })
+	if i == 0 {
+		return decimal.Zero, false
+	}
+	return rates[i-1].Rate, true
+}
+
+// BusinessDays lists the business days in [from, to). The cdi is published on
+// every B3 business day, so inside the cdi series its dates are the calendar;
+// outside of it, the weekdays that are not national holidays are counted.
+func (s Series) BusinessDays(from, to time.Time) []string {
+	cdi := s[investment_core.BondIndexCDI]
+	published := map[string]bool{}
+	for _, rate := range cdi {
+		published[rate.Date] = true
+	}
+
+	days := []string{}
+	for day := truncateDay(from); day.Before(truncateDay(to)); day = day.AddDate(0, 0, 1) {
+		date := day.Format(dateLayout)
+		if len(cdi) > 0 && date >= cdi[0].Date && date <= cdi[len(cdi)-1].Date {
+			if published[date] {
+				days = append(days, date)
+			}
+			continue
+		}
+		if isBusinessDay(day) {
+			days = append(days, date)
+		}
+	}
+	return days
+}
+
+// dailyFactor accrues a percentage of the cdi or selic: each business day
+// multiplies by 1 + daily rate × percentage.
+func (s Series) dailyFactor(index string, percentage decimal.Decimal, days []string) (decimal.Decimal, error) {
+	if percentage.IsZero() {
+		percentage = hundred
+	}
+	percentage = percentage.Div(hundred)
+
+	factor := one
+	for _, day := range days {
+		rate, ok := s.rateAt(index, day)
+		if !ok {
+			return decimal.Zero, fmt.Errorf("there is no %s rate up to %s", index, day)
+		}
+		daily := one.Add(rate.Div(hundred).Mul(percentage)).Round(8)
+		factor = factor.Mul(daily).Round(factorPlaces)
+	}
+	return factor, nil
+}
+
+// yearlyFactor compounds a rate in % per year over business days.
+func yearlyFactor(rate decimal.Decimal, businessDays int) decimal.Decimal {
+	if businessDays == 0 || rate.IsZero() {
+		return one
+	}
+	exponent := decimal.NewFromInt(int64(businessDays)).Div(yearBusiness)
+	return one.Add(rate.Div(hundred)).Pow(exponent).Round(factorPlaces)
+}
+
+// ipcaFactor accrues the monthly ipca from start to end, pro rata by calendar
+// days in the first and last months. Months not published yet are projected
+// with the last published variation.
+func (s Series) ipcaFactor(start, end time.Time) (decimal.Decimal, error) {
+	factor := one
+	for month := time.Date(start.Year(), start.Month(), 1, 0, 0, 0, 0, time.UTC); month.Before(end); month = month.AddDate(0, 1, 0) {
+		next := month.AddDate(0, 1, 0)
+		from, to := month, next
+		if start.After(from) {
+			from = start
+		}
+		if end.Before(to) {
+			to = end
+		}
+
+		reference := month.Format(dateLayout)
+		rate, ok := s.rateAt(investment_core.BondIndexIPCA, reference)
+		if !ok {
+			return decimal.Zero, fmt.Errorf("there is no ipca up to %s", month.Format("2006-01"))
+		}
+
+		monthly := one.Add(rate.Div(hundred))
+		held := decimal.NewFromInt(int64(to.Sub(from).Hours() / 24))
+		length := decimal.NewFromInt(int64(next.Sub(month).Hours() / 24))
+		if !held.Equal(length) {
+			monthly = monthly.Pow(held.Div(length))
+		}
+		factor = factor.Mul(monthly).Round(factorPlaces)
+	}
+	return factor, nil
+}
+
+// Accrue computes the gross value of a bond on today, or on its due date when
+// it has already matured, from the purchase principal and the index series.
+func Accrue(bond Bond, series Series, today time.Time) (Accrual, error) {
+	start := truncateDay(bond.StartDate)
+	end := truncateDay(today)
+	if bond.DueDate != "" {
+		due, err := parseDate(bond.DueDate)
+		if err != nil {
+			return Accrual{}, fmt.Errorf("invalid due date %s: %w", bond.DueDate, err)
+		}
+		if due.Before(end) {
+			end = due
+		}
+	}
+
+	days := []string{}
+	if start.Before(end) {
+		days = series.BusinessDays(start, end)
+	}
+
+	var factor decimal.Decimal
+	var err error
+	switch bond.Index {
+	case investment_core.BondIndexCDI, investment_core.BondIndexSELIC:
+		factor, err = series.dailyFactor(bond.Index, bond.Rate, days)
+	case investment_core.BondIndexIPCA:
+		factor = one
+		if start.Before(end) {
+			factor, err = series.ipcaFactor(start, end)
+		}
+		factor = factor.Mul(yearlyFactor(bond.Rate, len(days))).Round(factorPlaces)
+	case investment_core.BondIndexPrefix:
+		factor = yearlyFactor(bond.Rate, len(days))
+	default:
+		return Accrual{}, fmt.Errorf("unsupported bond index: %s", bond.Index)
+	}
+	if err != nil {
+		return Accrual{}, err
+	}
+
+	gross := bond.Principal.Mul(factor).Money()
+	return Accrual{
+		Factor:       factor,
+		GrossValue:   gross,
+		Interest:     gross.Sub(bond.Principal).Money(),
+		BusinessDays: len(days),
+	}, nil
+}
@@ -0,0 +1,106 @@
+package fixed_income_core
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/silasstoffel/invest-tracker/apps/shared/decimal"
+)
+
+func date(value string) time.Time {
+	t, _ := time.Parse(dateLayout, value)
+	return t
+}
+
+func TestBusinessDays(t *testing.T) {
+	// 2025-03-03 and 2025-03-04 are carnival, no cdi is published
+	series := Series{"cdi": {
+		{Date: "2025-02-28", Rate: decimal.RequireFromString("0.049037")},
+		{Date: "2025-03-05", Rate: decimal.RequireFromString("0.049037")},
+		{Date: "2025-03-06", Rate: decimal.RequireFromString("0.049037")},
+	}}
+
+	days := series.BusinessDays(date("2025-02-27"), date("2025-03-11"))
+	expected := "2025-02-27,2025-02-28,2025-03-05,2025-03-06,2025-03-07,2025-03-10"
+	if strings.Join(days, ",") != expected {
+		t.Errorf("expected %s, got %v", expected, days)
+	}
+
+	// after the series, good friday and tiradentes are skipped as well
+	days = series.BusinessDays(date("2025-04-16"), date("2025-04-23"))
+	expected = "2025-04-16,2025-04-17,2025-04-22"
+	if strings.Join(days, ",") != expected {
+		t.Errorf("expected %s, got %v", expected, days)
+	}
+}
+
+func TestAccrueCDI(t *testing.T) {
+	series := Series{"cdi": {
+		{Date: "2025-03-05", Rate: decimal.RequireFromString("0.049037")},
+		{Date: "2025-03-06", Rate: decimal.RequireFromString("0.049037")},
+	}}
+	bond := Bond{Principal: decimal.NewFromInt(10000), Index: "cdi", Rate: decimal.NewFromInt(110), StartDate: date("2025-03-05")}
+
+	// two published days and one projected with the last rate
+	accrual, err := Accrue(bond, series, date("2025-03-08"))
+	if err != nil {
+		t.Fatalf("failure to accrue: %v", err)
+	}
+	// (1 + 0.00049037 * 1.1)^3 = 1.00161907...
+	if accrual.BusinessDays != 3 || !accrual.GrossValue.Equal(decimal.RequireFromString("10016.19")) {
+		t.Errorf("expected 10016.19 after 3 business days, got %s after %d", accrual.GrossValue, accrual.BusinessDays)
+	}
+
+	if _, err := Accrue(Bond{Principal: decimal.NewFromInt(1000), Index: "cdi", StartDate: date("2025-03-03")}, Series{}, date("2025-03-10")); err == nil {
+		t.Error("expected an error without the cdi series")
+	}
+}
+
+func TestAccruePrefixed(t *testing.T) {
+	bond := Bond{Principal: decimal.NewFromInt(1000), Index: "prefixed", Rate: decimal.RequireFromString("11.3"), StartDate: date("2025-01-06"), DueDate: "2025-01-31"}
+
+	// 252 business days would be one year, the due date stops the accrual at 19 days
+	accrual, err := Accrue(bond, Series{}, date("2025-06-30"))
+	if err != nil {
+		t.Fatalf("failure to accrue: %v", err)
+	}
+	// 1.113^(19/252) = 1.00810...
+	if accrual.BusinessDays != 19 || !accrual.GrossValue.Equal(decimal.RequireFromString("1008.10")) {
+		t.Errorf("expected 1008.10 after 19 business days, got %s after %d", accrual.GrossValue, accrual.BusinessDays)
+	}
+}
+
+func TestAccrueIPCA(t *testing.T) {
+	series := Series{"ipca": {
+		{Date: "2025-01-01", Rate: decimal.RequireFromString("0.16")},
+		{Date: "2025-02-01", Rate: decimal.RequireFromString("1.31")},
+	}}
+	bond := Bond{Principal: decimal.NewFromInt(1000), Index: "ipca", StartDate: date("2025-01-01")}
+
+	// january and february in full, march projected with the february variation
+	accrual, err := Accrue(bond, series, date("2025-04-01"))
+	if err != nil {
+		t.Fatalf("failure to accrue: %v", err)
+	}
+	// 1.0016 * 1.0131 * 1.0131 = 1.02801...
+	if !accrual.GrossValue.Equal(decimal.RequireFromString("1028.01")) {
+		t.Errorf("expected 1028.01, got %s", accrual.GrossValue)
+	}
+}
+
+func TestParseSeries(t *testing.T) {
+	content := "\"data\";\"valor\"\n01/03/2025;0,049037\n05/03/2025;0,049037\n"
+	rates, err := ParseSeries(strings.NewReader(content), "cdi")
+	if err != nil {
+		t.Fatalf("failure to parse series: %v", err)
+	}
+	if len(rates) != 2 || rates[0].Date != "2025-03-01" || !rates[0].Rate.Equal(decimal.RequireFromString("0.049037")) {
+		t.Errorf("expected two cdi rates, got %+v", rates)
+	}
+
+	rates, err = ParseSeries(strings.NewReader("2025-02-15,1.31\n"), "ipca")
+	if err != nil || len(rates) != 1 || rates[0].Date != "2025-02-01" {
+		t.Errorf("expected the ipca on the first day of the month, got %+v (%v)", rates, err)
+	}
+}
@@ -0,0 +1,61 @@
+package fixed_income_core
+
+import "time"
+
+// fixedHolidays are the national holidays of the ANBIMA calendar on a fixed
+// day, as month and day.
+var fixedHolidays = map[[2]int]bool{
+	{1, 1}:   true, // confraternização universal
+	{4, 21}:  true, // tiradentes
+	{5, 1}:   true, // dia do trabalho
+	{9, 7}:   true, // independência
+	{10, 12}: true, // nossa senhora aparecida
+	{11, 2}:  true, // finados
+	{11, 15}: true, // proclamação da república
+	{12, 25}: true, // natal
+}
+
+// easter returns the easter sunday of the year (anonymous gregorian algorithm).
+func easter(year int) time.Time {
+	a := year % 19
+	b, c := year/100, year%100
+	d, e := b/4, b%4
+	f := (b + 8) / 25
+	g := (b - f + 1) / 3
+	h := (19*a + b - d - g + 15) % 30
+	i, k := c/4, c%4
+	l := (32 + 2*e + 2*i - h - k) % 7
+	m := (a + 11*h + 22*l) / 451
+	month := (h + l - 7*m + 114) / 31
+	day := (h+l-7*m+114)%31 + 1
+	return time.Date(year, time.Month(month), day, 0, 0, 0, 0, time.UTC)
+}
+
+// isHoliday tells whether day is a national holiday of the ANBIMA calendar:
+// the fixed ones, carnival, good friday and corpus christi, which move with
+// easter, and black consciousness day since 2024.
+func isHoliday(day time.Time) bool {
+	day = truncateDay(day)
+	if fixedHolidays[[2]int{int(day.Month()), day.Day()}] {
+		return true
+	}
+	if day.Year() >= 2024 && day.Month() == time.November && day.Day() == 20 {
+		return true
+	}
+
+	sunday := easter(day.Year())
+	for _, offset := range []int{-48, -47, -2, 60} {
+		if day.Equal(sunday.AddDate(0, 0, offset)) {
+			return true
+		}
+	}
+	return false
+}
+
+// isBusinessDay tells whether day is a weekday and not a national holiday.
+func isBusinessDay(day time.Time) bool {
+	if day.Weekday() == time.Saturday || day.Weekday() == time.Sunday {
+		return false
+	}
+	return !isHoliday(day)
+}
@@ -0,0 +1,33 @@
+package fixed_income_core
+
+import "testing"
+
+func TestEaster(t *testing.T) {
+	for year, expected := range map[int]string{2024: "2024-03-31", 2025: "2025-04-20", 2026: "2026-04-05", 2038: "2038-04-25"} {
+		if got := easter(year).Format(dateLayout); got != expected {
+			t.Errorf("expected easter of %d on %s, got %s", year, expected, got)
+		}
+	}
+}
+
+func TestIsBusinessDay(t *testing.T) {
+	cases := map[string]bool{
+		"2025-03-03": false, // carnival monday
+		"2025-03-04": false, // carnival tuesday
+		"2025-03-05": true,  // ash wednesday
+		"2025-04-18": false, // good friday
+		"2025-04-21": false, // tiradentes
+		"2025-06-19": false, // corpus christi
+		"2025-11-20": false, // black consciousness day
+		"2023-11-20": true,  // not a national holiday before 2024
+		"2025-12-25": false,
+		"2025-12-26": true,
+		"2025-12-27": false, // saturday
+	}
+
+	for value, expected := range cases {
+		if got := isBusinessDay(date(value)); got != expected {
+			t.Errorf("expected %s business day to be %v, got %v", value, expected, got)
+		}
+	}
+}
@@ -0,0 +1,133 @@
+package fixed_income_core
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/silasstoffel/invest-tracker/apps/shared/decimal"
+)
+
+// UnclassifiedIssuer groups the bonds bought before the issuer was informed.
+const UnclassifiedIssuer = "unclassified"
+
+// FgcLimit is the FGC guarantee per person and conglomerate.
+var FgcLimit = decimal.NewFromInt(250000)
+
+type BondExposure struct {
+	SummaryID   string          `json:"summaryId"`
+	Symbol      string          `json:"symbol"`
+	Brokerage   string          `json:"brokerage"`
+	Issuer      string          `json:"issuer"`
+	ProductKind string          `json:"productKind"`
+	DueDate     string          `json:"dueDate"`
+	FgcCovered  bool            `json:"fgcCovered"`
+	Principal   decimal.Decimal `json:"principal"`
+	// principal plus the interest projected until the due date
+	ProjectedValue decimal.Decimal `json:"projectedValue"`
+}
+
+type ConglomerateExposure struct {
+	Conglomerate          string          `json:"conglomerate"`
+	Issuers               []string        `json:"issuers"`
+	Bonds                 []BondExposure  `json:"bonds"`
+	Principal             decimal.Decimal `json:"principal"`
+	ProjectedValue        decimal.Decimal `json:"projectedValue"`
+	CoveredPrincipal      decimal.Decimal `json:"coveredPrincipal"`
+	CoveredProjectedValue decimal.Decimal `json:"coveredProjectedValue"`
+	// the covered projected value is above the FGC limit
+	ExceedsFgcLimit bool `json:"exceedsFgcLimit"`
+}
+
+type ExposureReport struct {
+	Date          string                 `json:"date"`
+	FgcLimit      decimal.Decimal        `json:"fgcLimit"`
+	Conglomerates []ConglomerateExposure `json:"conglomerates"`
+	// bonds whose interest could not be projected, valued at the principal
+	Unprojected    []string        `json:"unprojected"`
+	Principal      decimal.Decimal `json:"principal"`
+	ProjectedValue decimal.Decimal `json:"projectedValue"`
+}
+
+// BuildExposure groups the bonds by the conglomerate of their issuer.
+// conglomerates maps an upper case issuer to its conglomerate, issuers without
+// one are a conglomerate of their own.
+func BuildExposure(date string, bonds []BondExposure, conglomerates map[string]string, unprojected []string) ExposureReport {
+	report := ExposureReport{
+		Date:           date,
+		FgcLimit:       FgcLimit,
+		Conglomerates:  []ConglomerateExposure{},
+		Unprojected:    unprojected,
+		Principal:      decimal.Zero,
+		ProjectedValue: decimal.Zero,
+	}
+
+	groups := map[string]*ConglomerateExposure{}
+	issuers := map[string]map[string]bool{}
+	for _, bond := range bonds {
+		name := UnclassifiedIssuer
+		if bond.Issuer != "" {
+			name = bond.Issuer
+			if conglomerate, ok := conglomerates[strings.ToUpper(bond.Issuer)]; ok {
+				name = conglomerate
+			}
+		}
+
+		group, ok := groups[name]
+		if !ok {
+			group = &ConglomerateExposure{
+				Conglomerate:          name,
+				Issuers:               []string{},
+				Bonds:                 []BondExposure{},
+				Principal:             decimal.Zero,
+				ProjectedValue:        decimal.Zero,
+				CoveredPrincipal:      decimal.Zero,
+				CoveredProjectedValue: decimal.Zero,
+			}
+			groups[name] = group
+			issuers[name] = map[string]bool{}
+		}
+
+		if bond.Issuer != "" && !issuers[name][bond.Issuer] {
+			issuers[name][bond.Issuer] = true
+			group.Issuers = append(group.Issuers, bond.Issuer)
+		}
+		group.Bonds = append(group.Bonds, bond)
+		group.Principal = group.Principal.Add(bond.Principal)
+		group.ProjectedValue = group.ProjectedValue.Add(bond.ProjectedValue)
+		if bond.FgcCovered {
+			group.CoveredPrincipal = group.CoveredPrincipal.Add(bond.Principal)
+			group.CoveredProjectedValue = group.CoveredProjectedValue.Add(bond.ProjectedValue)
+		}
+
+		report.Principal = report.Principal.Add(bond.Principal)
+		report.ProjectedValue = report.ProjectedValue.Add(bond.ProjectedValue)
+	}
+
+	for name, group := range groups {
+		group.ExceedsFgcLimit = name != UnclassifiedIssuer && group.CoveredProjectedValue.GreaterThan(FgcLimit)
+		sort.Strings(group.Issuers)
+		sort.Slice(group.Bonds, func(i, j int) bool { return group.Bonds[i].DueDate < group.Bonds[j].DueDate })
+		report.Conglomerates = append(report.Conglomerates, *group)
+	}
+
+	sort.Slice(report.Conglomerates, func(i, j int) bool {
+		a, b := report.Conglomerates[i], report.Conglomerates[j]
+		if !a.CoveredProjectedValue.Equal(b.CoveredProjectedValue) {
+			return a.CoveredProjectedValue.GreaterThan(b.CoveredProjectedValue)
+		}
+		return a.Conglomerate < b.Conglomerate
+	})
+
+	return report
+}
+
+// OverFgcLimit returns the conglomerates above the FGC limit.
+func (r ExposureReport) OverFgcLimit() []ConglomerateExposure {
+	over := []ConglomerateExposure{}
+	for _, conglomerate := range r.Conglomerates {
+		if conglomerate.ExceedsFgcLimit {
+			over = append(over, conglomerate)
+		}
+	}
+	return over
+}
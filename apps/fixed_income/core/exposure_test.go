@@ -0,0 +1,55 @@
+package fixed_income_core
+
+import (
+	"testing"
+
+	"github.com/silasstoffel/invest-tracker/apps/shared/decimal"
+)
+
+func TestBuildExposure(t *testing.T) {
+	bonds := []BondExposure{
+		{Symbol: "CDB BTG 2027", Issuer: "BANCO BTG PACTUAL", ProductKind: "cdb", FgcCovered: true,
+			Principal: decimal.NewFromInt(150000), ProjectedValue: decimal.NewFromInt(180000), DueDate: "2027-03-05"},
+		{Symbol: "LCA BTG 2026", Issuer: "Banco Pan", ProductKind: "lca", FgcCovered: true,
+			Principal: decimal.NewFromInt(60000), ProjectedValue: decimal.NewFromInt(75000), DueDate: "2026-05-10"},
+		// not covered by the FGC
+		{Symbol: "DEBENTURE BTG", Issuer: "BANCO BTG PACTUAL", ProductKind: "debenture",
+			Principal: decimal.NewFromInt(50000), ProjectedValue: decimal.NewFromInt(60000), DueDate: "2030-01-15"},
+		{Symbol: "LCI BARI", Issuer: "BANCO BARI", ProductKind: "lci", FgcCovered: true,
+			Principal: decimal.NewFromInt(20000), ProjectedValue: decimal.NewFromInt(22000), DueDate: "2026-01-10"},
+		{Symbol: "LCA PRE BTG", FgcCovered: true,
+			Principal: decimal.NewFromInt(300000), ProjectedValue: decimal.NewFromInt(320000), DueDate: "2028-01-19"},
+	}
+	conglomerates := map[string]string{"BANCO BTG PACTUAL": "BTG Pactual", "BANCO PAN": "BTG Pactual"}
+
+	report := BuildExposure("2025-06-02", bonds, conglomerates, []string{})
+	if len(report.Conglomerates) != 3 {
+		t.Fatalf("expected 3 conglomerates, got %+v", report.Conglomerates)
+	}
+
+	unclassified, btg, bari := report.Conglomerates[0], report.Conglomerates[1], report.Conglomerates[2]
+	if unclassified.Conglomerate != UnclassifiedIssuer || unclassified.ExceedsFgcLimit {
+		t.Errorf("expected bonds without issuer unclassified and never flagged, got %+v", unclassified)
+	}
+	if btg.Conglomerate != "BTG Pactual" || len(btg.Issuers) != 2 || len(btg.Bonds) != 3 {
+		t.Fatalf("expected both issuers grouped under BTG Pactual, got %+v", btg)
+	}
+	// 180000 + 75000, the debenture is not covered
+	if !btg.CoveredProjectedValue.Equal(decimal.NewFromInt(255000)) || !btg.CoveredPrincipal.Equal(decimal.NewFromInt(210000)) {
+		t.Errorf("expected 210000 covered growing to 255000, got %s and %s", btg.CoveredPrincipal, btg.CoveredProjectedValue)
+	}
+	if !btg.ExceedsFgcLimit || bari.ExceedsFgcLimit {
+		t.Errorf("expected only BTG Pactual above the limit")
+	}
+	if bari.Conglomerate != "BANCO BARI" {
+		t.Errorf("expected an unmapped issuer to be its own conglomerate, got %s", bari.Conglomerate)
+	}
+
+	over := report.OverFgcLimit()
+	if len(over) != 1 || over[0].Conglomerate != "BTG Pactual" {
+		t.Errorf("expected BTG Pactual over the limit, got %+v", over)
+	}
+	if !report.ProjectedValue.Equal(decimal.NewFromInt(657000)) {
+		t.Errorf("expected 657000 projected, got %s", report.ProjectedValue)
+	}
+}
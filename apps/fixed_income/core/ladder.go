@@ -0,0 +1,131 @@
+package fixed_income_core
+
+import (
+	"sort"
+
+	"github.com/silasstoffel/invest-tracker/apps/shared/decimal"
+)
+
+type LadderBond struct {
+	SummaryID            string          `json:"summaryId"`
+	Symbol               string          `json:"symbol"`
+	Brokerage            string          `json:"brokerage"`
+	DueDate              string          `json:"dueDate"`
+	RedemptionPolicyType string          `json:"redemptionPolicyType"`
+	Principal            decimal.Decimal `json:"principal"`
+	// principal plus the interest projected until the due date
+	ProjectedValue decimal.Decimal `json:"projectedValue"`
+}
+
+type LadderTotal struct {
+	Count          int             `json:"count"`
+	Principal      decimal.Decimal `json:"principal"`
+	ProjectedValue decimal.Decimal `json:"projectedValue"`
+}
+
+type LadderBucket struct {
+	// YYYY-MM for months, YYYY for years
+	Period string `json:"period"`
+	LadderTotal
+	ByRedemptionPolicy map[string]LadderTotal `json:"byRedemptionPolicy"`
+	Bonds              []LadderBond           `json:"bonds"`
+}
+
+type MaturityLadder struct {
+	Date   string         `json:"date"`
+	Months []LadderBucket `json:"months"`
+	Years  []LadderBucket `json:"years"`
+	// bonds without a due date, such as daily liquidity CDBs
+	Undated []LadderBond `json:"undated"`
+	// bonds whose interest could not be projected, valued at the principal
+	Unprojected []string `json:"unprojected"`
+	LadderTotal
+	ByRedemptionPolicy map[string]LadderTotal `json:"byRedemptionPolicy"`
+}
+
+func newLadderTotal() LadderTotal {
+	return LadderTotal{Principal: decimal.Zero, ProjectedValue: decimal.Zero}
+}
+
+func (t LadderTotal) add(bond LadderBond) LadderTotal {
+	return LadderTotal{
+		Count:          t.Count + 1,
+		Principal:      t.Principal.Add(bond.Principal),
+		ProjectedValue: t.ProjectedValue.Add(bond.ProjectedValue),
+	}
+}
+
+func addByPolicy(totals map[string]LadderTotal, bond LadderBond) {
+	total, ok := totals[bond.RedemptionPolicyType]
+	if !ok {
+		total = newLadderTotal()
+	}
+	totals[bond.RedemptionPolicyType] = total.add(bond)
+}
+
+// BuildLadder buckets the bonds by the month and by the year of their due date.
+func BuildLadder(date string, bonds []LadderBond, unprojected []string) MaturityLadder {
+	ladder := MaturityLadder{
+		Date:               date,
+		Months:             []LadderBucket{},
+		Years:              []LadderBucket{},
+		Undated:            []LadderBond{},
+		Unprojected:        unprojected,
+		LadderTotal:        newLadderTotal(),
+		ByRedemptionPolicy: map[string]LadderTotal{},
+	}
+
+	months := map[string]*LadderBucket{}
+	years := map[string]*LadderBucket{}
+	for _, bond := range bonds {
+		ladder.LadderTotal = ladder.LadderTotal.add(bond)
+		addByPolicy(ladder.ByRedemptionPolicy, bond)
+
+		if len(bond.DueDate) < len(dateLayout) {
+			ladder.Undated = append(ladder.Undated, bond)
+			continue
+		}
+		addToBucket(months, bond.DueDate[:7], bond)
+		addToBucket(years, bond.DueDate[:4], bond)
+	}
+
+	ladder.Months = sortedBuckets(months)
+	ladder.Years = sortedBuckets(years)
+	sort.Slice(ladder.Undated, func(i, j int) bool { return ladder.Undated[i].Symbol < ladder.Undated[j].Symbol })
+
+	return ladder
+}
+
+func addToBucket(buckets map[string]*LadderBucket, period string, bond LadderBond) {
+	bucket, ok := buckets[period]
+	if !ok {
+		bucket = &LadderBucket{
+			Period:             period,
+			LadderTotal:        newLadderTotal(),
+			ByRedemptionPolicy: map[string]LadderTotal{},
+			Bonds:              []LadderBond{},
+		}
+		buckets[period] = bucket
+	}
+
+	bucket.LadderTotal = bucket.LadderTotal.add(bond)
+	addByPolicy(bucket.ByRedemptionPolicy, bond)
+	bucket.Bonds = append(bucket.Bonds, bond)
+}
+
+func sortedBuckets(buckets map[string]*LadderBucket) []LadderBucket {
+	sorted := []LadderBucket{}
+	for _, bucket := range buckets {
+		sort.Slice(bucket.Bonds, func(i, j int) bool {
+			a, b := bucket.Bonds[i], bucket.Bonds[j]
+			if a.DueDate != b.DueDate {
+				return a.DueDate < b.DueDate
+			}
+			return a.Symbol < b.Symbol
+		})
+		sorted = append(sorted, *bucket)
+	}
+
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Period < sorted[j].Period })
+	return sorted
+}
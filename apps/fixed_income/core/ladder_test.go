@@ -0,0 +1,49 @@
+package fixed_income_core
+
+import (
+	"testing"
+
+	"github.com/silasstoffel/invest-tracker/apps/shared/decimal"
+)
+
+func TestBuildLadder(t *testing.T) {
+	bonds := []LadderBond{
+		{Symbol: "CDB BTG", DueDate: "2026-03-10", RedemptionPolicyType: "at_maturity",
+			Principal: decimal.NewFromInt(1000), ProjectedValue: decimal.NewFromInt(1100)},
+		{Symbol: "LCA BTG", DueDate: "2026-03-02", RedemptionPolicyType: "any_time",
+			Principal: decimal.NewFromInt(2000), ProjectedValue: decimal.NewFromInt(2150)},
+		{Symbol: "LCI BARI", DueDate: "2026-11-20", RedemptionPolicyType: "at_maturity",
+			Principal: decimal.NewFromInt(500), ProjectedValue: decimal.NewFromInt(560)},
+		{Symbol: "TESOURO IPCA 2035", DueDate: "2035-05-15", RedemptionPolicyType: "any_time",
+			Principal: decimal.NewFromInt(3000), ProjectedValue: decimal.NewFromInt(6000)},
+		{Symbol: "CDB LIQUIDEZ", RedemptionPolicyType: "any_time",
+			Principal: decimal.NewFromInt(700), ProjectedValue: decimal.NewFromInt(700)},
+	}
+
+	ladder := BuildLadder("2025-06-02", bonds, []string{})
+
+	if len(ladder.Months) != 3 || ladder.Months[0].Period != "2026-03" || ladder.Months[2].Period != "2035-05" {
+		t.Fatalf("expected 3 months from 2026-03 to 2035-05, got %+v", ladder.Months)
+	}
+	march := ladder.Months[0]
+	if march.Count != 2 || !march.ProjectedValue.Equal(decimal.NewFromInt(3250)) || march.Bonds[0].Symbol != "LCA BTG" {
+		t.Errorf("expected both march bonds sorted by due date, got %+v", march)
+	}
+	if !march.ByRedemptionPolicy["at_maturity"].Principal.Equal(decimal.NewFromInt(1000)) || march.ByRedemptionPolicy["any_time"].Count != 1 {
+		t.Errorf("expected march split by redemption policy, got %+v", march.ByRedemptionPolicy)
+	}
+
+	if len(ladder.Years) != 2 || ladder.Years[0].Period != "2026" || ladder.Years[0].Count != 3 {
+		t.Fatalf("expected 3 bonds in 2026 and 1 in 2035, got %+v", ladder.Years)
+	}
+	if len(ladder.Undated) != 1 {
+		t.Errorf("expected the bond without due date undated, got %+v", ladder.Undated)
+	}
+
+	if ladder.Count != 5 || !ladder.Principal.Equal(decimal.NewFromInt(7200)) {
+		t.Errorf("expected 5 bonds and 7200 of principal, got %d and %s", ladder.Count, ladder.Principal)
+	}
+	if !ladder.ByRedemptionPolicy["any_time"].ProjectedValue.Equal(decimal.NewFromInt(8850)) {
+		t.Errorf("expected 8850 projected any time, got %s", ladder.ByRedemptionPolicy["any_time"].ProjectedValue)
+	}
+}
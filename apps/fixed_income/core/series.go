@@ -0,0 +1,78 @@
+package fixed_income_core
+
+import (
+	"bufio"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	investment_core "github.com/silasstoffel/invest-tracker/apps/investments/core"
+	"github.com/silasstoffel/invest-tracker/apps/shared/decimal"
+)
+
+// ParseSeries reads the rates of an index from a csv with a date and a value
+// per line, such as the BCB SGS export ("data";"valor" with 01/03/2025;0,049037)
+// or 2025-03-01,0.049037. A header line is skipped. ipca dates are moved to the
+// first day of their month.
+func ParseSeries(reader io.Reader, index string) ([]IndexRate, error) {
+	switch index {
+	case investment_core.BondIndexCDI, investment_core.BondIndexSELIC, investment_core.BondIndexIPCA:
+	default:
+		return nil, fmt.Errorf("invalid index %s, use cdi, selic or ipca", index)
+	}
+
+	buffered := bufio.NewReader(reader)
+	first, _ := buffered.Peek(256)
+	csvReader := csv.NewReader(buffered)
+	if strings.Contains(string(first), ";") {
+		csvReader.Comma = ';'
+	}
+	csvReader.FieldsPerRecord = -1
+
+	lines, err := csvReader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("invalid series file: %w", err)
+	}
+
+	rates := []IndexRate{}
+	for n, line := range lines {
+		if len(line) < 2 || strings.TrimSpace(line[0]) == "" {
+			continue
+		}
+
+		date, err := parseSeriesDate(line[0])
+		if err != nil {
+			if n == 0 {
+				continue
+			}
+			return nil, fmt.Errorf("invalid date in line %d: %w", n+1, err)
+		}
+		if index == investment_core.BondIndexIPCA {
+			date = time.Date(date.Year(), date.Month(), 1, 0, 0, 0, 0, time.UTC)
+		}
+
+		value := strings.TrimSpace(line[1])
+		if strings.Contains(value, ",") {
+			value = strings.ReplaceAll(value, ".", "")
+			value = strings.ReplaceAll(value, ",", ".")
+		}
+		rate, err := decimal.NewFromString(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value in line %d: %w", n+1, err)
+		}
+
+		rates = append(rates, IndexRate{Date: date.Format(dateLayout), Rate: rate})
+	}
+
+	return rates, nil
+}
+
+func parseSeriesDate(value string) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if strings.Contains(value, "/") {
+		return time.Parse("02/01/2006", value)
+	}
+	return time.Parse(dateLayout, value)
+}
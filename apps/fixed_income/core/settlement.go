@@ -0,0 +1,42 @@
+package fixed_income_core
+
+import (
+	"fmt"
+
+	"github.com/silasstoffel/invest-tracker/apps/shared/decimal"
+)
+
+// settlement amount sources
+const (
+	ProjectedSettlement = "projected"
+	ConfirmedSettlement = "confirmed"
+)
+
+type Settlement struct {
+	SummaryID string `json:"summaryId"`
+	// id of the redemption sell scheduled
+	OperationID string          `json:"operationId,omitempty"`
+	Symbol      string          `json:"symbol"`
+	Brokerage   string          `json:"brokerage"`
+	DueDate     string          `json:"dueDate"`
+	Principal   decimal.Decimal `json:"principal"`
+	Amount      decimal.Decimal `json:"amount"`
+	// projected with the bond rate or confirmed by the user
+	Source string `json:"source"`
+}
+
+type SettlementReport struct {
+	Date    string       `json:"date"`
+	Settled []Settlement `json:"settled"`
+	// matured bonds waiting for the amount to be confirmed
+	Unconfirmed []Settlement `json:"unconfirmed"`
+	// bonds that could not be settled, with the reason
+	Skipped []string        `json:"skipped"`
+	Amount  decimal.Decimal `json:"amount"`
+}
+
+// SettlementKey is the idempotency key of the redemption of a matured
+// position, so it is scheduled only once.
+func SettlementKey(summaryID, dueDate string) string {
+	return fmt.Sprintf("maturity-%s-%s", summaryID, dueDate)
+}
@@ -0,0 +1,59 @@
+package fixed_income_core
+
+import (
+	"time"
+
+	"github.com/silasstoffel/invest-tracker/apps/shared/decimal"
+)
+
+// IndexRate is a published value of an index: the daily rate in % per day for
+// cdi and selic, the monthly variation in % for ipca.
+type IndexRate struct {
+	Date string          `json:"date"`
+	Rate decimal.Decimal `json:"rate"`
+}
+
+// Series holds the rates of each index sorted by date, keyed by bond index.
+type Series map[string][]IndexRate
+
+type Bond struct {
+	Principal decimal.Decimal
+	Index     string
+	// % of the index for cdi and selic (0 means 100%), % per year over the index
+	// for ipca and % per year for prefixed
+	Rate      decimal.Decimal
+	StartDate time.Time
+	// YYYY-MM-DD, the accrual stops at the due date
+	DueDate string
+}
+
+type Accrual struct {
+	Factor       decimal.Decimal
+	GrossValue   decimal.Decimal
+	Interest     decimal.Decimal
+	BusinessDays int
+}
+
+type BondValuation struct {
+	SummaryID    string          `json:"summaryId"`
+	Symbol       string          `json:"symbol"`
+	Brokerage    string          `json:"brokerage"`
+	BondIndex    string          `json:"bondIndex"`
+	BondRate     decimal.Decimal `json:"bondRate"`
+	StartDate    string          `json:"startDate"`
+	DueDate      string          `json:"dueDate"`
+	Principal    decimal.Decimal `json:"principal"`
+	GrossValue   decimal.Decimal `json:"grossValue"`
+	Interest     decimal.Decimal `json:"interest"`
+	BusinessDays int             `json:"businessDays"`
+}
+
+type AccrualReport struct {
+	Date  string          `json:"date"`
+	Bonds []BondValuation `json:"bonds"`
+	// bonds that could not be accrued, with the reason
+	Skipped    []string        `json:"skipped"`
+	Principal  decimal.Decimal `json:"principal"`
+	GrossValue decimal.Decimal `json:"grossValue"`
+	Interest   decimal.Decimal `json:"interest"`
+}
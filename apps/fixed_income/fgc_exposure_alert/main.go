@@ -0,0 +1,73 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-lambda-go/lambda"
+	fixed_income_service "github.com/silasstoffel/invest-tracker/apps/fixed_income/service"
+	"github.com/silasstoffel/invest-tracker/apps/shared/database"
+	"github.com/silasstoffel/invest-tracker/apps/shared/repository"
+	"github.com/silasstoffel/invest-tracker/apps/shared/telegram"
+	appConfig "github.com/silasstoffel/invest-tracker/config"
+)
+
+var (
+	env     *appConfig.Config
+	service *fixed_income_service.Service
+)
+
+func init() {
+	env = appConfig.NewConfigFromEnvVars()
+
+	db, err := database.NewFromConfig(env)
+	if err != nil {
+		m := fmt.Sprintf("Failure to connect to database: %v", err)
+		log.Println(m)
+		panic(m)
+	}
+
+	service = fixed_income_service.NewService(
+		repository.NewInvestmentRepository(db),
+		repository.NewSummaryRepository(db),
+		repository.NewIndexSeriesRepository(db),
+		repository.NewIssuerRepository(db),
+	)
+}
+
+func Handler(ctx context.Context) error {
+	prefix := os.Getenv("AWS_LAMBDA_FUNCTION_NAME")
+	tb := telegram.NewTelegramBot(env)
+
+	report, err := service.Exposure(ctx, time.Now())
+	if err != nil {
+		log.Printf("Failure to read fixed income exposure: %v", err)
+		tb.SendMessage(fmt.Sprintf("*[%s] Failure to read fixed income exposure* ```%s```", prefix, err.Error()))
+		return err
+	}
+
+	over := report.OverFgcLimit()
+	log.Printf("%d conglomerate(s), %d above the FGC limit of %s", len(report.Conglomerates), len(over), report.FgcLimit)
+	if len(over) == 0 {
+		return nil
+	}
+
+	lines := []string{}
+	for _, conglomerate := range over {
+		lines = append(lines, fmt.Sprintf("%s (%s): covered principal %s, projected %s",
+			conglomerate.Conglomerate, strings.Join(conglomerate.Issuers, ", "),
+			conglomerate.CoveredPrincipal.StringFixed(2), conglomerate.CoveredProjectedValue.StringFixed(2)))
+	}
+	tb.SendMessage(fmt.Sprintf("*[%s] %d conglomerate(s) above the FGC limit of %s* ```%s```",
+		prefix, len(over), report.FgcLimit.StringFixed(2), strings.Join(lines, "\n")))
+
+	return nil
+}
+
+func main() {
+	lambda.Start(Handler)
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambda"
+	fixed_income_service "github.com/silasstoffel/invest-tracker/apps/fixed_income/service"
+	"github.com/silasstoffel/invest-tracker/apps/shared/database"
+	http_helper "github.com/silasstoffel/invest-tracker/apps/shared/http_helpers"
+	"github.com/silasstoffel/invest-tracker/apps/shared/repository"
+	appConfig "github.com/silasstoffel/invest-tracker/config"
+)
+
+type ErrorOutput struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+var (
+	env     *appConfig.Config
+	service *fixed_income_service.Service
+)
+
+func init() {
+	env = appConfig.NewConfigFromEnvVars()
+
+	db, err := database.NewFromConfig(env)
+	if err != nil {
+		m := fmt.Sprintf("Failure to connect to database: %v", err)
+		log.Println(m)
+		panic(m)
+	}
+
+	service = fixed_income_service.NewService(
+		repository.NewInvestmentRepository(db),
+		repository.NewSummaryRepository(db),
+		repository.NewIndexSeriesRepository(db),
+		repository.NewIssuerRepository(db),
+	)
+}
+
+func Handler(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	report, err := service.MaturityLadder(ctx, time.Now())
+	if err != nil {
+		log.Printf("Failure to read maturity ladder: %v", err)
+		return http_helper.JsonResponse(ErrorOutput{
+			Code:    "INTERNAL_ERROR",
+			Message: "failure to read maturity ladder",
+		}, http_helper.JsonResponseOptions{StatusCode: 500}), nil
+	}
+
+	return http_helper.JsonResponse(report), nil
+}
+
+func main() {
+	lambda.Start(Handler)
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-lambda-go/lambda"
+	fixed_income_core "github.com/silasstoffel/invest-tracker/apps/fixed_income/core"
+	fixed_income_service "github.com/silasstoffel/invest-tracker/apps/fixed_income/service"
+	"github.com/silasstoffel/invest-tracker/apps/shared/database"
+	"github.com/silasstoffel/invest-tracker/apps/shared/repository"
+	"github.com/silasstoffel/invest-tracker/apps/shared/telegram"
+	appConfig "github.com/silasstoffel/invest-tracker/config"
+)
+
+// monthsAhead is how many months at least are listed one by one, up to the end
+// of the year they reach. Later maturities are listed by year.
+const monthsAhead = 12
+
+var (
+	env     *appConfig.Config
+	service *fixed_income_service.Service
+)
+
+func init() {
+	env = appConfig.NewConfigFromEnvVars()
+
+	db, err := database.NewFromConfig(env)
+	if err != nil {
+		m := fmt.Sprintf("Failure to connect to database: %v", err)
+		log.Println(m)
+		panic(m)
+	}
+
+	service = fixed_income_service.NewService(
+		repository.NewInvestmentRepository(db),
+		repository.NewSummaryRepository(db),
+		repository.NewIndexSeriesRepository(db),
+		repository.NewIssuerRepository(db),
+	)
+}
+
+func formatBucket(bucket fixed_income_core.LadderBucket) string {
+	policies := []string{}
+	for policy := range bucket.ByRedemptionPolicy {
+		policies = append(policies, policy)
+	}
+	sort.Strings(policies)
+
+	parts := []string{}
+	for _, policy := range policies {
+		if policy == "" {
+			continue
+		}
+		parts = append(parts, fmt.Sprintf("%s %s", policy, bucket.ByRedemptionPolicy[policy].ProjectedValue.StringFixed(2)))
+	}
+
+	return fmt.Sprintf("%s: %d bond(s), principal %s, projected %s (%s)", bucket.Period, bucket.Count,
+		bucket.Principal.StringFixed(2), bucket.ProjectedValue.StringFixed(2), strings.Join(parts, ", "))
+}
+
+func Handler(ctx context.Context) error {
+	prefix := os.Getenv("AWS_LAMBDA_FUNCTION_NAME")
+	tb := telegram.NewTelegramBot(env)
+
+	today := time.Now()
+	ladder, err := service.MaturityLadder(ctx, today)
+	if err != nil {
+		log.Printf("Failure to read maturity ladder: %v", err)
+		tb.SendMessage(fmt.Sprintf("*[%s] Failure to read maturity ladder* ```%s```", prefix, err.Error()))
+		return err
+	}
+
+	log.Printf("%d bond(s) maturing in %d month(s), principal %s", ladder.Count, len(ladder.Months), ladder.Principal)
+	if ladder.Count == 0 {
+		return nil
+	}
+
+	lastYear := today.AddDate(0, monthsAhead-1, 0).Format("2006")
+	lines := []string{}
+	for _, month := range ladder.Months {
+		if month.Period[:4] <= lastYear {
+			lines = append(lines, formatBucket(month))
+		}
+	}
+	for _, year := range ladder.Years {
+		if year.Period > lastYear {
+			lines = append(lines, formatBucket(year))
+		}
+	}
+	if len(ladder.Undated) > 0 {
+		lines = append(lines, fmt.Sprintf("no due date: %d bond(s)", len(ladder.Undated)))
+	}
+	lines = append(lines, fmt.Sprintf("total: principal %s, projected %s", ladder.Principal.StringFixed(2), ladder.ProjectedValue.StringFixed(2)))
+
+	tb.SendMessage(fmt.Sprintf("*[%s] Maturity ladder* ```%s```", prefix, strings.Join(lines, "\n")))
+	return nil
+}
+
+func main() {
+	lambda.Start(Handler)
+}
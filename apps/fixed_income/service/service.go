@@ -0,0 +1,315 @@
+package fixed_income_service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	fixed_income_core "github.com/silasstoffel/invest-tracker/apps/fixed_income/core"
+	investment_core "github.com/silasstoffel/invest-tracker/apps/investments/core"
+	investment_summary_core "github.com/silasstoffel/invest-tracker/apps/investments_summary/core"
+	"github.com/silasstoffel/invest-tracker/apps/shared/decimal"
+	"github.com/silasstoffel/invest-tracker/apps/shared/repository"
+)
+
+type Service struct {
+	investments repository.InvestmentRepository
+	summaries   repository.SummaryRepository
+	series      repository.IndexSeriesRepository
+	issuers     repository.IssuerRepository
+}
+
+func NewService(investments repository.InvestmentRepository, summaries repository.SummaryRepository, series repository.IndexSeriesRepository, issuers repository.IssuerRepository) *Service {
+	return &Service{investments: investments, summaries: summaries, series: series, issuers: issuers}
+}
+
+// loadSeries reads the cdi, which is also the business day calendar, and the
+// other indexes used by the bonds.
+func (s *Service) loadSeries(ctx context.Context, bonds []investment_summary_core.InvestmentSummaryEntity) (fixed_income_core.Series, error) {
+	indexes := map[string]bool{investment_core.BondIndexCDI: true}
+	for _, bond := range bonds {
+		if bond.BondIndex == investment_core.BondIndexSELIC || bond.BondIndex == investment_core.BondIndexIPCA {
+			indexes[bond.BondIndex] = true
+		}
+	}
+
+	series := fixed_income_core.Series{}
+	for index := range indexes {
+		rates, err := s.series.Find(ctx, index)
+		if err != nil {
+			return nil, fmt.Errorf("failure to read the %s series: %w", index, err)
+		}
+		series[index] = rates
+	}
+	series.Sort()
+
+	return series, nil
+}
+
+// purchases returns the bond purchases by id.
+func (s *Service) purchases(ctx context.Context) (map[string]investment_core.InvestmentEntity, error) {
+	entities, err := s.investments.Find(ctx, repository.InvestmentFilter{
+		Type:          investment_core.BondInvestmentType,
+		OperationType: investment_core.BuyOperationType,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failure to read bond purchases: %w", err)
+	}
+
+	purchases := map[string]investment_core.InvestmentEntity{}
+	for _, entity := range entities {
+		purchases[entity.ID] = entity
+	}
+	return purchases, nil
+}
+
+// openBonds returns the bond positions not redeemed. Matured positions are kept
+// until they are settled, their accrual stops on the due date.
+func (s *Service) openBonds(ctx context.Context) ([]investment_summary_core.InvestmentSummaryEntity, error) {
+	summaries, err := s.summaries.Find(ctx, repository.SummaryFilter{Type: investment_core.BondInvestmentType})
+	if err != nil {
+		return nil, fmt.Errorf("failure to read investments summary: %w", err)
+	}
+
+	bonds := []investment_summary_core.InvestmentSummaryEntity{}
+	for _, summary := range summaries {
+		if summary.Quantity.IsPositive() {
+			bonds = append(bonds, summary)
+		}
+	}
+	return bonds, nil
+}
+
+// bondOf builds the accrual input of a bond position, starting on the date of
+// its purchase.
+func bondOf(summary investment_summary_core.InvestmentSummaryEntity, purchases map[string]investment_core.InvestmentEntity) (fixed_income_core.Bond, error) {
+	if summary.InvestmentID == "" {
+		return fixed_income_core.Bond{}, fmt.Errorf("the position has no purchase")
+	}
+
+	purchase, ok := purchases[summary.InvestmentID]
+	if !ok {
+		return fixed_income_core.Bond{}, fmt.Errorf("purchase %s not found", summary.InvestmentID)
+	}
+
+	start, err := time.Parse("2006-01-02", purchase.OperationDate)
+	if err != nil {
+		return fixed_income_core.Bond{}, fmt.Errorf("invalid purchase date %s: %w", purchase.OperationDate, err)
+	}
+
+	return fixed_income_core.Bond{
+		Principal: summary.TotalValue,
+		Index:     summary.BondIndex,
+		Rate:      summary.BondRate,
+		StartDate: start,
+		DueDate:   summary.DueDate,
+	}, nil
+}
+
+func describe(summary investment_summary_core.InvestmentSummaryEntity) string {
+	return fmt.Sprintf("%s (%s) at %s", summary.Symbol, summary.ID, summary.Brokerage)
+}
+
+// Accrue computes the gross value of every open bond position on today and,
+// unless dryRun is set, stores it as the market value of the position.
+func (s *Service) Accrue(ctx context.Context, today time.Time, dryRun bool) (fixed_income_core.AccrualReport, error) {
+	bonds, err := s.openBonds(ctx)
+	if err != nil {
+		return fixed_income_core.AccrualReport{}, err
+	}
+
+	purchases, err := s.purchases(ctx)
+	if err != nil {
+		return fixed_income_core.AccrualReport{}, err
+	}
+
+	series, err := s.loadSeries(ctx, bonds)
+	if err != nil {
+		return fixed_income_core.AccrualReport{}, err
+	}
+
+	date := today.Format("2006-01-02")
+	report := fixed_income_core.AccrualReport{
+		Date:       date,
+		Bonds:      []fixed_income_core.BondValuation{},
+		Skipped:    []string{},
+		Principal:  decimal.Zero,
+		GrossValue: decimal.Zero,
+		Interest:   decimal.Zero,
+	}
+
+	for _, summary := range bonds {
+		name := describe(summary)
+
+		bond, err := bondOf(summary, purchases)
+		if err != nil {
+			report.Skipped = append(report.Skipped, fmt.Sprintf("%s: %v", name, err))
+			continue
+		}
+
+		accrual, err := fixed_income_core.Accrue(bond, series, today)
+		if err != nil {
+			report.Skipped = append(report.Skipped, fmt.Sprintf("%s: %v", name, err))
+			continue
+		}
+
+		if !dryRun {
+			if err := s.summaries.UpdateMarketValue(ctx, summary.ID, accrual.GrossValue, date); err != nil {
+				return report, fmt.Errorf("failure to update market value of %s: %w", name, err)
+			}
+		}
+
+		report.Bonds = append(report.Bonds, fixed_income_core.BondValuation{
+			SummaryID:    summary.ID,
+			Symbol:       summary.Symbol,
+			Brokerage:    summary.Brokerage,
+			BondIndex:    summary.BondIndex,
+			BondRate:     summary.BondRate,
+			StartDate:    bond.StartDate.Format("2006-01-02"),
+			DueDate:      summary.DueDate,
+			Principal:    bond.Principal,
+			GrossValue:   accrual.GrossValue,
+			Interest:     accrual.Interest,
+			BusinessDays: accrual.BusinessDays,
+		})
+		report.Principal = report.Principal.Add(bond.Principal)
+		report.GrossValue = report.GrossValue.Add(accrual.GrossValue)
+		report.Interest = report.Interest.Add(accrual.Interest)
+	}
+
+	sort.Slice(report.Bonds, func(i, j int) bool {
+		a, b := report.Bonds[i], report.Bonds[j]
+		if a.DueDate != b.DueDate {
+			return a.DueDate < b.DueDate
+		}
+		return a.Symbol < b.Symbol
+	})
+
+	return report, nil
+}
+
+// Exposure groups the open bonds by the conglomerate of their issuer, with the
+// value projected until maturity of the ones covered by the FGC.
+func (s *Service) Exposure(ctx context.Context, today time.Time) (fixed_income_core.ExposureReport, error) {
+	bonds, err := s.openBonds(ctx)
+	if err != nil {
+		return fixed_income_core.ExposureReport{}, err
+	}
+
+	purchases, err := s.purchases(ctx)
+	if err != nil {
+		return fixed_income_core.ExposureReport{}, err
+	}
+
+	series, err := s.loadSeries(ctx, bonds)
+	if err != nil {
+		return fixed_income_core.ExposureReport{}, err
+	}
+
+	issuers, err := s.issuers.FindAll(ctx)
+	if err != nil {
+		return fixed_income_core.ExposureReport{}, fmt.Errorf("failure to read issuer conglomerates: %w", err)
+	}
+	conglomerates := map[string]string{}
+	for _, issuer := range issuers {
+		conglomerates[strings.ToUpper(issuer.Issuer)] = issuer.Conglomerate
+	}
+
+	exposures := []fixed_income_core.BondExposure{}
+	unprojected := []string{}
+	for _, summary := range bonds {
+		purchase := purchases[summary.InvestmentID]
+		exposure := fixed_income_core.BondExposure{
+			SummaryID:      summary.ID,
+			Symbol:         summary.Symbol,
+			Brokerage:      summary.Brokerage,
+			Issuer:         purchase.Issuer,
+			ProductKind:    purchase.ProductKind,
+			DueDate:        summary.DueDate,
+			FgcCovered:     investment_core.IsFgcCovered(purchase.ProductKind),
+			Principal:      summary.TotalValue,
+			ProjectedValue: summary.TotalValue,
+		}
+
+		projected, err := project(summary, purchases, series, today)
+		if err != nil {
+			unprojected = append(unprojected, fmt.Sprintf("%s: %v", describe(summary), err))
+		} else {
+			exposure.ProjectedValue = projected
+		}
+
+		exposures = append(exposures, exposure)
+	}
+
+	return fixed_income_core.BuildExposure(today.Format("2006-01-02"), exposures, conglomerates, unprojected), nil
+}
+
+// MaturityLadder buckets the open bonds by the month and year of their due
+// date, with the value projected until then.
+func (s *Service) MaturityLadder(ctx context.Context, today time.Time) (fixed_income_core.MaturityLadder, error) {
+	bonds, err := s.openBonds(ctx)
+	if err != nil {
+		return fixed_income_core.MaturityLadder{}, err
+	}
+
+	purchases, err := s.purchases(ctx)
+	if err != nil {
+		return fixed_income_core.MaturityLadder{}, err
+	}
+
+	series, err := s.loadSeries(ctx, bonds)
+	if err != nil {
+		return fixed_income_core.MaturityLadder{}, err
+	}
+
+	ladder := []fixed_income_core.LadderBond{}
+	unprojected := []string{}
+	for _, summary := range bonds {
+		bond := fixed_income_core.LadderBond{
+			SummaryID:            summary.ID,
+			Symbol:               summary.Symbol,
+			Brokerage:            summary.Brokerage,
+			DueDate:              summary.DueDate,
+			RedemptionPolicyType: summary.RedemptionPolicyType,
+			Principal:            summary.TotalValue,
+			ProjectedValue:       summary.TotalValue,
+		}
+
+		projected, err := project(summary, purchases, series, today)
+		if err != nil {
+			unprojected = append(unprojected, fmt.Sprintf("%s: %v", describe(summary), err))
+		} else {
+			bond.ProjectedValue = projected
+		}
+
+		ladder = append(ladder, bond)
+	}
+
+	return fixed_income_core.BuildLadder(today.Format("2006-01-02"), ladder, unprojected), nil
+}
+
+// project returns the gross value of a bond on its due date, or on today when
+// it has none. The accrual projects the last published rates.
+func project(summary investment_summary_core.InvestmentSummaryEntity, purchases map[string]investment_core.InvestmentEntity, series fixed_income_core.Series, today time.Time) (decimal.Decimal, error) {
+	bond, err := bondOf(summary, purchases)
+	if err != nil {
+		return decimal.Zero, err
+	}
+
+	accrual, err := fixed_income_core.Accrue(bond, series, maturityOf(summary, today))
+	if err != nil {
+		return decimal.Zero, err
+	}
+	return accrual.GrossValue, nil
+}
+
+// maturityOf returns the due date of a bond, or today when it has none.
+func maturityOf(summary investment_summary_core.InvestmentSummaryEntity, today time.Time) time.Time {
+	due, err := time.Parse("2006-01-02", summary.DueDate)
+	if err != nil {
+		return today
+	}
+	return due
+}
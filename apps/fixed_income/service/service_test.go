@@ -0,0 +1,208 @@
+package fixed_income_service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	fixed_income_core "github.com/silasstoffel/invest-tracker/apps/fixed_income/core"
+	investment_core "github.com/silasstoffel/invest-tracker/apps/investments/core"
+	investment_summary_core "github.com/silasstoffel/invest-tracker/apps/investments_summary/core"
+	"github.com/silasstoffel/invest-tracker/apps/shared/database"
+	"github.com/silasstoffel/invest-tracker/apps/shared/decimal"
+	"github.com/silasstoffel/invest-tracker/apps/shared/repository"
+)
+
+func TestAccrue(t *testing.T) {
+	ctx := context.Background()
+	db, err := database.NewSQLite(":memory:")
+	if err != nil {
+		t.Fatalf("failure to open database: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	investments := repository.NewInvestmentRepository(db)
+	summaries := repository.NewSummaryRepository(db)
+	series := repository.NewIndexSeriesRepository(db)
+
+	purchase := investment_core.InvestmentEntity{
+		ID: "01", Type: "bond", Symbol: "CDB BTG", BondIndex: "cdi", BondRate: decimal.NewFromInt(100),
+		Quantity: decimal.NewFromInt(1), UnitPrice: decimal.NewFromInt(10000), TotalValue: decimal.NewFromInt(10000),
+		OperationType: "buy", OperationDate: "2025-03-05", Brokerage: "btg", DueDate: "2027-03-05",
+		RedemptionPolicyType: "at_maturity", CreatedAt: time.Now().UTC(), UpdatedAt: time.Now().UTC(),
+	}
+	if err := investments.Create(ctx, purchase); err != nil {
+		t.Fatalf("failure to create investment: %v", err)
+	}
+
+	positions := []investment_summary_core.InvestmentSummaryEntity{
+		{ID: "1", InvestmentID: "01", Type: "bond", Symbol: "CDB BTG", BondIndex: "cdi", BondRate: decimal.NewFromInt(100), Brokerage: "btg",
+			Quantity: decimal.NewFromInt(1), AveragePrice: decimal.NewFromInt(10000), TotalValue: decimal.NewFromInt(10000), DueDate: "2027-03-05"},
+		// no purchase to start the accrual from
+		{ID: "2", Type: "bond", Symbol: "LCA PRE BTG", BondIndex: "prefixed", BondRate: decimal.NewFromInt(11), Brokerage: "btg",
+			Quantity: decimal.NewFromInt(1), AveragePrice: decimal.NewFromInt(1000), TotalValue: decimal.NewFromInt(1000), DueDate: "2028-01-19"},
+	}
+	for _, position := range positions {
+		if err := summaries.Create(ctx, position); err != nil {
+			t.Fatalf("failure to create summary: %v", err)
+		}
+	}
+
+	err = series.Save(ctx, "cdi", []fixed_income_core.IndexRate{
+		{Date: "2025-03-05", Rate: decimal.RequireFromString("0.049037")},
+		{Date: "2025-03-06", Rate: decimal.RequireFromString("0.049037")},
+	})
+	if err != nil {
+		t.Fatalf("failure to save series: %v", err)
+	}
+
+	service := NewService(investments, summaries, series, repository.NewIssuerRepository(db))
+	today := time.Date(2025, 3, 7, 0, 0, 0, 0, time.UTC)
+
+	report, err := service.Accrue(ctx, today, true)
+	if err != nil {
+		t.Fatalf("failure to accrue: %v", err)
+	}
+	if len(report.Bonds) != 1 || len(report.Skipped) != 1 {
+		t.Fatalf("expected one bond accrued and one skipped, got %+v", report)
+	}
+	// (1 + 0.00049037)^2
+	if !report.GrossValue.Equal(decimal.RequireFromString("10009.81")) {
+		t.Errorf("expected 10009.81, got %s", report.GrossValue)
+	}
+	summary, _ := summaries.FindByInvestmentID(ctx, "01")
+	if !summary.MarketValue.IsZero() {
+		t.Fatalf("expected the dry run to write nothing, got %s", summary.MarketValue)
+	}
+
+	if _, err := service.Accrue(ctx, today, false); err != nil {
+		t.Fatalf("failure to accrue: %v", err)
+	}
+	summary, _ = summaries.FindByInvestmentID(ctx, "01")
+	if !summary.MarketValue.Equal(decimal.RequireFromString("10009.81")) {
+		t.Errorf("expected the gross value stored as market value, got %s", summary.MarketValue)
+	}
+}
+
+func TestExposure(t *testing.T) {
+	ctx := context.Background()
+	db, err := database.NewSQLite(":memory:")
+	if err != nil {
+		t.Fatalf("failure to open database: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	investments := repository.NewInvestmentRepository(db)
+	summaries := repository.NewSummaryRepository(db)
+	issuers := repository.NewIssuerRepository(db)
+
+	purchase := investment_core.InvestmentEntity{
+		ID: "01", Type: "bond", Symbol: "CDB PRE BARI", BondIndex: "prefixed", BondRate: decimal.NewFromInt(12),
+		Quantity: decimal.NewFromInt(1), UnitPrice: decimal.NewFromInt(240000), TotalValue: decimal.NewFromInt(240000),
+		OperationType: "buy", OperationDate: "2025-01-02", Brokerage: "btg", DueDate: "2026-01-02",
+		RedemptionPolicyType: "at_maturity", Issuer: "Banco Bari", ProductKind: "cdb",
+		CreatedAt: time.Now().UTC(), UpdatedAt: time.Now().UTC(),
+	}
+	if err := investments.Create(ctx, purchase); err != nil {
+		t.Fatalf("failure to create investment: %v", err)
+	}
+	position := investment_summary_core.InvestmentSummaryEntity{
+		ID: "1", InvestmentID: "01", Type: "bond", Symbol: "CDB PRE BARI", BondIndex: "prefixed", BondRate: decimal.NewFromInt(12), Brokerage: "btg",
+		Quantity: decimal.NewFromInt(1), AveragePrice: decimal.NewFromInt(240000), TotalValue: decimal.NewFromInt(240000), DueDate: "2026-01-02",
+	}
+	if err := summaries.Create(ctx, position); err != nil {
+		t.Fatalf("failure to create summary: %v", err)
+	}
+	if err := issuers.Save(ctx, repository.IssuerConglomerate{Issuer: "BANCO BARI", Conglomerate: "Bari"}); err != nil {
+		t.Fatalf("failure to save issuer: %v", err)
+	}
+
+	service := NewService(investments, summaries, repository.NewIndexSeriesRepository(db), issuers)
+	report, err := service.Exposure(ctx, time.Date(2025, 6, 2, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("failure to read exposure: %v", err)
+	}
+
+	if len(report.Conglomerates) != 1 || len(report.Unprojected) != 0 {
+		t.Fatalf("expected a single conglomerate, got %+v", report)
+	}
+	bari := report.Conglomerates[0]
+	// 240000 is under the limit, the 12% until the due date is not
+	if bari.Conglomerate != "Bari" || !bari.CoveredPrincipal.Equal(decimal.NewFromInt(240000)) || !bari.ExceedsFgcLimit {
+		t.Errorf("expected Bari above the FGC limit with the projected interest, got %+v", bari)
+	}
+}
+
+func TestMaturedBondUntilSettled(t *testing.T) {
+	ctx := context.Background()
+	db, err := database.NewSQLite(":memory:")
+	if err != nil {
+		t.Fatalf("failure to open database: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	investments := repository.NewInvestmentRepository(db)
+	summaries := repository.NewSummaryRepository(db)
+	series := repository.NewIndexSeriesRepository(db)
+
+	purchase := investment_core.InvestmentEntity{
+		ID: "01", Type: "bond", Symbol: "CDB BTG", BondIndex: "cdi", BondRate: decimal.NewFromInt(100),
+		Quantity: decimal.NewFromInt(1), UnitPrice: decimal.NewFromInt(10000), TotalValue: decimal.NewFromInt(10000),
+		OperationType: "buy", OperationDate: "2025-03-05", Brokerage: "btg", DueDate: "2025-03-07",
+		RedemptionPolicyType: "at_maturity", Issuer: "Banco BTG", ProductKind: "cdb",
+		CreatedAt: time.Now().UTC(), UpdatedAt: time.Now().UTC(),
+	}
+	if err := investments.Create(ctx, purchase); err != nil {
+		t.Fatalf("failure to create investment: %v", err)
+	}
+	position := investment_summary_core.InvestmentSummaryEntity{
+		ID: "1", InvestmentID: "01", Type: "bond", Symbol: "CDB BTG", BondIndex: "cdi", BondRate: decimal.NewFromInt(100), Brokerage: "btg",
+		Quantity: decimal.NewFromInt(1), AveragePrice: decimal.NewFromInt(10000), TotalValue: decimal.NewFromInt(10000), DueDate: "2025-03-07",
+	}
+	if err := summaries.Create(ctx, position); err != nil {
+		t.Fatalf("failure to create summary: %v", err)
+	}
+	err = series.Save(ctx, "cdi", []fixed_income_core.IndexRate{
+		{Date: "2025-03-05", Rate: decimal.RequireFromString("0.049037")},
+		{Date: "2025-03-06", Rate: decimal.RequireFromString("0.049037")},
+		{Date: "2025-03-07", Rate: decimal.RequireFromString("0.049037")},
+		{Date: "2025-03-10", Rate: decimal.RequireFromString("0.049037")},
+	})
+	if err != nil {
+		t.Fatalf("failure to save series: %v", err)
+	}
+
+	service := NewService(investments, summaries, series, repository.NewIssuerRepository(db))
+	// matured, not settled yet
+	today := time.Date(2025, 3, 11, 0, 0, 0, 0, time.UTC)
+
+	// (1 + 0.00049037)^2, the accrual stops on the due date
+	expected := decimal.RequireFromString("10009.81")
+	report, err := service.Accrue(ctx, today, false)
+	if err != nil {
+		t.Fatalf("failure to accrue: %v", err)
+	}
+	if len(report.Bonds) != 1 || !report.GrossValue.Equal(expected) {
+		t.Fatalf("expected the matured bond accrued until its due date, got %+v", report)
+	}
+	summary, _ := summaries.FindByInvestmentID(ctx, "01")
+	if !summary.MarketValue.Equal(expected) {
+		t.Errorf("expected the market value to be updated, got %s", summary.MarketValue)
+	}
+
+	exposure, err := service.Exposure(ctx, today)
+	if err != nil {
+		t.Fatalf("failure to read exposure: %v", err)
+	}
+	if len(exposure.Conglomerates) != 1 || !exposure.Conglomerates[0].CoveredProjectedValue.Equal(expected) {
+		t.Errorf("expected the matured bond in the exposure, got %+v", exposure)
+	}
+
+	ladder, err := service.MaturityLadder(ctx, today)
+	if err != nil {
+		t.Fatalf("failure to read the ladder: %v", err)
+	}
+	if ladder.Count != 1 || len(ladder.Months) != 1 || ladder.Months[0].Period != "2025-03" {
+		t.Errorf("expected the matured bond in the ladder, got %+v", ladder)
+	}
+}
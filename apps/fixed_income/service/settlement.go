@@ -0,0 +1,231 @@
+package fixed_income_service
+
+import (
+	"context"
+	cryptoRand "crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	"github.com/oklog/ulid/v2"
+	fixed_income_core "github.com/silasstoffel/invest-tracker/apps/fixed_income/core"
+	investment_core "github.com/silasstoffel/invest-tracker/apps/investments/core"
+	investment_summary_core "github.com/silasstoffel/invest-tracker/apps/investments_summary/core"
+	"github.com/silasstoffel/invest-tracker/apps/shared/decimal"
+	"github.com/silasstoffel/invest-tracker/apps/shared/outbox"
+	"github.com/silasstoffel/invest-tracker/apps/shared/repository"
+)
+
+// MaturityNote marks the redemption sells scheduled on the maturity of a bond.
+const MaturityNote = "maturity"
+
+// Settler schedules the redemption of matured bonds on the create investment
+// queue, the same way the schedule endpoint does.
+type Settler struct {
+	bonds       *Service
+	operations  repository.OperationStatusRepository
+	settlements repository.BondSettlementRepository
+	sender      outbox.Sender
+	queueURL    string
+}
+
+func NewSettler(bonds *Service, operations repository.OperationStatusRepository, settlements repository.BondSettlementRepository, sender outbox.Sender, queueURL string) *Settler {
+	return &Settler{bonds: bonds, operations: operations, settlements: settlements, sender: sender, queueURL: queueURL}
+}
+
+func createId() string {
+	entropy := ulid.Monotonic(cryptoRand.Reader, 0)
+	t := time.Now().UTC()
+
+	return ulid.MustNew(ulid.Timestamp(t), entropy).String()
+}
+
+// isMatured tells whether an at_maturity bond position still holds quantity on
+// or after its due date.
+func isMatured(summary investment_summary_core.InvestmentSummaryEntity, today time.Time) bool {
+	return summary.RedemptionPolicyType == investment_core.AtMaturityRedemption &&
+		summary.Quantity.IsPositive() &&
+		summary.DueDate != "" &&
+		summary.DueDate <= today.Format("2006-01-02")
+}
+
+// redemptionOf builds the sell of the whole position on its due date.
+func redemptionOf(summary investment_summary_core.InvestmentSummaryEntity, amount decimal.Decimal) investment_core.CreateInvestmentInput {
+	return investment_core.CreateInvestmentInput{
+		ID:                   createId(),
+		Type:                 investment_core.BondInvestmentType,
+		Symbol:               summary.Symbol,
+		BondIndex:            summary.BondIndex,
+		BondRate:             summary.BondRate,
+		Quantity:             summary.Quantity,
+		TotalValue:           amount,
+		Cost:                 decimal.Zero,
+		OperationType:        investment_core.SellOperationType,
+		OperationDate:        summary.DueDate,
+		DueDate:              summary.DueDate,
+		Brokerage:            summary.Brokerage,
+		Note:                 MaturityNote,
+		RedemptionPolicyType: summary.RedemptionPolicyType,
+		SellInvestmentId:     summary.InvestmentID,
+		IdempotencyKey:       fixed_income_core.SettlementKey(summary.ID, summary.DueDate),
+	}
+}
+
+// Settle schedules a redemption sell for each matured at_maturity bond. The
+// amount is the one confirmed by the user or, for prefixed bonds, the principal
+// projected with the bond rate until the due date; the other bonds wait for
+// the confirmation. Unless dryRun is set, the sells are queued.
+func (s *Settler) Settle(ctx context.Context, today time.Time, dryRun bool) (fixed_income_core.SettlementReport, error) {
+	report := fixed_income_core.SettlementReport{
+		Date:        today.Format("2006-01-02"),
+		Settled:     []fixed_income_core.Settlement{},
+		Unconfirmed: []fixed_income_core.Settlement{},
+		Skipped:     []string{},
+		Amount:      decimal.Zero,
+	}
+
+	summaries, err := s.bonds.summaries.Find(ctx, repository.SummaryFilter{
+		Type:      investment_core.BondInvestmentType,
+		DueDateTo: report.Date,
+	})
+	if err != nil {
+		return report, fmt.Errorf("failure to read investments summary: %w", err)
+	}
+
+	matured := []investment_summary_core.InvestmentSummaryEntity{}
+	for _, summary := range summaries {
+		if isMatured(summary, today) {
+			matured = append(matured, summary)
+		}
+	}
+	if len(matured) == 0 {
+		return report, nil
+	}
+
+	purchases, err := s.bonds.purchases(ctx)
+	if err != nil {
+		return report, err
+	}
+
+	series, err := s.bonds.loadSeries(ctx, matured)
+	if err != nil {
+		return report, err
+	}
+
+	for _, summary := range matured {
+		name := describe(summary)
+		key := fixed_income_core.SettlementKey(summary.ID, summary.DueDate)
+
+		// still on the way to the summary, a failed one is scheduled again unless
+		// it was already created
+		operation, err := s.operations.FindByIdempotencyKey(ctx, key)
+		if err == nil && operation.Status != investment_core.FailedOperationStatus {
+			continue
+		}
+		if err != nil && !errors.Is(err, repository.ErrNotFound) {
+			report.Skipped = append(report.Skipped, fmt.Sprintf("%s: %v", name, err))
+			continue
+		}
+		if err == nil {
+			// the create consumer skips a redemption it already persisted, so one
+			// that failed afterwards is left failed for a rebuild of the position
+			if created, err := s.persisted(ctx, key); err != nil || created {
+				if err == nil {
+					err = fmt.Errorf("redemption %s was created but failed: %s", operation.ID, operation.Reason)
+				}
+				report.Skipped = append(report.Skipped, fmt.Sprintf("%s: %v", name, err))
+				continue
+			}
+		}
+
+		settlement := fixed_income_core.Settlement{
+			SummaryID: summary.ID,
+			Symbol:    summary.Symbol,
+			Brokerage: summary.Brokerage,
+			DueDate:   summary.DueDate,
+			Principal: summary.TotalValue,
+			Amount:    decimal.Zero,
+		}
+
+		amount, err := s.settlements.Find(ctx, summary.ID)
+		switch {
+		case err == nil:
+			settlement.Amount = amount
+			settlement.Source = fixed_income_core.ConfirmedSettlement
+		case !errors.Is(err, repository.ErrNotFound):
+			report.Skipped = append(report.Skipped, fmt.Sprintf("%s: %v", name, err))
+			continue
+		case summary.BondIndex == investment_core.BondIndexPrefix:
+			projected, err := project(summary, purchases, series, today)
+			if err != nil {
+				report.Skipped = append(report.Skipped, fmt.Sprintf("%s: %v", name, err))
+				continue
+			}
+			settlement.Amount = projected.Money()
+			settlement.Source = fixed_income_core.ProjectedSettlement
+		default:
+			report.Unconfirmed = append(report.Unconfirmed, settlement)
+			continue
+		}
+
+		redemption := redemptionOf(summary, settlement.Amount)
+		if operation.ID != "" {
+			redemption.ID = operation.ID
+		}
+		settlement.OperationID = redemption.ID
+
+		if !dryRun {
+			if err := s.schedule(ctx, redemption); err != nil {
+				report.Skipped = append(report.Skipped, fmt.Sprintf("%s: %v", name, err))
+				continue
+			}
+		}
+
+		report.Settled = append(report.Settled, settlement)
+		report.Amount = report.Amount.Add(settlement.Amount)
+	}
+
+	sort.Slice(report.Settled, func(i, j int) bool { return report.Settled[i].DueDate < report.Settled[j].DueDate })
+	sort.Slice(report.Unconfirmed, func(i, j int) bool { return report.Unconfirmed[i].DueDate < report.Unconfirmed[j].DueDate })
+
+	return report, nil
+}
+
+// persisted tells whether the create consumer already stored the redemption.
+func (s *Settler) persisted(ctx context.Context, key string) (bool, error) {
+	_, err := s.bonds.investments.FindByIdempotencyKey(ctx, key)
+	if errors.Is(err, repository.ErrNotFound) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// schedule queues the redemption for the create investment consumer.
+func (s *Settler) schedule(ctx context.Context, redemption investment_core.CreateInvestmentInput) error {
+	if err := s.operations.Queue(ctx, redemption.ID, redemption.IdempotencyKey); err != nil {
+		return err
+	}
+
+	message, err := json.Marshal(redemption)
+	if err != nil {
+		return fmt.Errorf("failure to convert redemption to json: %w", err)
+	}
+
+	_, err = s.sender.SendMessage(ctx, &sqs.SendMessageInput{
+		QueueUrl:    aws.String(s.queueURL),
+		MessageBody: aws.String(string(message)),
+	})
+	if err != nil {
+		err = fmt.Errorf("failure to send redemption %s: %w", redemption.ID, err)
+		if statusErr := s.operations.UpdateStatus(ctx, redemption.ID, investment_core.FailedOperationStatus, err.Error()); statusErr != nil {
+			return fmt.Errorf("%w, %v", err, statusErr)
+		}
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,149 @@
+package fixed_income_service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	investment_core "github.com/silasstoffel/invest-tracker/apps/investments/core"
+	investment_summary_core "github.com/silasstoffel/invest-tracker/apps/investments_summary/core"
+	"github.com/silasstoffel/invest-tracker/apps/shared/database"
+	"github.com/silasstoffel/invest-tracker/apps/shared/decimal"
+	"github.com/silasstoffel/invest-tracker/apps/shared/repository"
+)
+
+type fakeSender struct {
+	messages []string
+}
+
+func (s *fakeSender) SendMessage(ctx context.Context, params *sqs.SendMessageInput, optFns ...func(*sqs.Options)) (*sqs.SendMessageOutput, error) {
+	s.messages = append(s.messages, aws.ToString(params.MessageBody))
+	return &sqs.SendMessageOutput{}, nil
+}
+
+func TestSettle(t *testing.T) {
+	ctx := context.Background()
+	db, err := database.NewSQLite(":memory:")
+	if err != nil {
+		t.Fatalf("failure to open database: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	investments := repository.NewInvestmentRepository(db)
+	summaries := repository.NewSummaryRepository(db)
+	operations := repository.NewOperationStatusRepository(db)
+	settlements := repository.NewBondSettlementRepository(db)
+
+	purchases := []investment_core.InvestmentEntity{
+		{ID: "01JPREFIXED00000000000000A", Symbol: "CDB PRE BARI", BondIndex: "prefixed", BondRate: decimal.NewFromInt(12), DueDate: "2025-06-02"},
+		{ID: "01JCDI000000000000000000AA", Symbol: "CDB BTG", BondIndex: "cdi", BondRate: decimal.NewFromInt(100), DueDate: "2025-06-02"},
+		{ID: "01JANYTIME00000000000000AA", Symbol: "LCA BTG", BondIndex: "cdi", BondRate: decimal.NewFromInt(95), DueDate: "2025-06-02"},
+	}
+	for i, purchase := range purchases {
+		purchase.Type = "bond"
+		purchase.Quantity = decimal.NewFromInt(1)
+		purchase.UnitPrice = decimal.NewFromInt(10000)
+		purchase.TotalValue = decimal.NewFromInt(10000)
+		purchase.OperationType = "buy"
+		purchase.OperationDate = "2024-06-03"
+		purchase.Brokerage = "btg"
+		purchase.RedemptionPolicyType = "at_maturity"
+		if i == 2 {
+			purchase.RedemptionPolicyType = "any_time"
+		}
+		purchase.CreatedAt, purchase.UpdatedAt = time.Now().UTC(), time.Now().UTC()
+		if err := investments.Create(ctx, purchase); err != nil {
+			t.Fatalf("failure to create investment: %v", err)
+		}
+
+		err := summaries.Create(ctx, investment_summary_core.InvestmentSummaryEntity{
+			ID: fmt.Sprint(i + 1), InvestmentID: purchase.ID, Type: "bond", Symbol: purchase.Symbol, BondIndex: purchase.BondIndex,
+			BondRate: purchase.BondRate, Brokerage: "btg", Quantity: decimal.NewFromInt(1), AveragePrice: decimal.NewFromInt(10000),
+			TotalValue: decimal.NewFromInt(10000), DueDate: purchase.DueDate, RedemptionPolicyType: purchase.RedemptionPolicyType,
+		})
+		if err != nil {
+			t.Fatalf("failure to create summary: %v", err)
+		}
+	}
+
+	sender := &fakeSender{}
+	service := NewService(investments, summaries, repository.NewIndexSeriesRepository(db), repository.NewIssuerRepository(db))
+	settler := NewSettler(service, operations, settlements, sender, "create-investment")
+	today := time.Date(2025, 6, 3, 0, 0, 0, 0, time.UTC)
+
+	report, err := settler.Settle(ctx, today, false)
+	if err != nil {
+		t.Fatalf("failure to settle: %v", err)
+	}
+	if len(report.Settled) != 1 || len(report.Unconfirmed) != 1 || len(sender.messages) != 1 {
+		t.Fatalf("expected the prefixed bond settled and the cdi one unconfirmed, got %+v", report)
+	}
+	prefixed := report.Settled[0]
+	if prefixed.SummaryID != "1" || prefixed.Source != "projected" || !prefixed.Amount.GreaterThan(decimal.RequireFromString("11100")) || !prefixed.Amount.LessThan(decimal.RequireFromString("11300")) {
+		t.Errorf("expected about 12%% a year over the principal, got %+v", prefixed)
+	}
+
+	var redemption investment_core.CreateInvestmentInput
+	if err := json.Unmarshal([]byte(sender.messages[0]), &redemption); err != nil {
+		t.Fatalf("failure to read the redemption: %v", err)
+	}
+	if redemption.OperationType != "sell" || redemption.SellInvestmentId != purchases[0].ID || redemption.OperationDate != "2025-06-02" ||
+		!redemption.Quantity.Equal(decimal.NewFromInt(1)) || !redemption.TotalValue.Equal(prefixed.Amount) {
+		t.Errorf("expected a sell of the whole position on the due date, got %+v", redemption)
+	}
+	if operation, err := operations.Find(ctx, redemption.ID); err != nil || operation.Status != "queued" {
+		t.Errorf("expected the redemption queued, got %+v (%v)", operation, err)
+	}
+
+	if err := settlements.Confirm(ctx, "2", decimal.RequireFromString("10987.65")); err != nil {
+		t.Fatalf("failure to confirm: %v", err)
+	}
+	report, err = settler.Settle(ctx, today, false)
+	if err != nil {
+		t.Fatalf("failure to settle: %v", err)
+	}
+	// the prefixed one is already on its way
+	if len(report.Settled) != 1 || report.Settled[0].Source != "confirmed" || !report.Amount.Equal(decimal.RequireFromString("10987.65")) || len(sender.messages) != 2 {
+		t.Errorf("expected only the confirmed bond settled, got %+v", report)
+	}
+
+	// failed before reaching the queue, the same operation is sent again
+	if err := operations.UpdateStatus(ctx, redemption.ID, "failed", "queue unavailable"); err != nil {
+		t.Fatalf("failure to update status: %v", err)
+	}
+	report, err = settler.Settle(ctx, today, false)
+	if err != nil {
+		t.Fatalf("failure to settle: %v", err)
+	}
+	if len(report.Settled) != 1 || report.Settled[0].OperationID != redemption.ID || len(sender.messages) != 3 {
+		t.Fatalf("expected the failed redemption sent again, got %+v", report)
+	}
+
+	// created and failed afterwards, sending it again would be skipped as a duplicate
+	err = investments.Create(ctx, investment_core.InvestmentEntity{
+		ID: redemption.ID, Type: "bond", Symbol: redemption.Symbol, Quantity: redemption.Quantity, UnitPrice: redemption.TotalValue,
+		TotalValue: redemption.TotalValue, OperationType: "sell", OperationDate: redemption.OperationDate, Brokerage: "btg",
+		SellInvestmentId: redemption.SellInvestmentId, IdempotencyKey: redemption.IdempotencyKey,
+		CreatedAt: time.Now().UTC(), UpdatedAt: time.Now().UTC(),
+	})
+	if err != nil {
+		t.Fatalf("failure to create investment: %v", err)
+	}
+	if err := operations.UpdateStatus(ctx, redemption.ID, "failed", "summary unavailable"); err != nil {
+		t.Fatalf("failure to update status: %v", err)
+	}
+	report, err = settler.Settle(ctx, today, false)
+	if err != nil {
+		t.Fatalf("failure to settle: %v", err)
+	}
+	if len(report.Settled) != 0 || len(report.Skipped) != 1 || len(sender.messages) != 3 {
+		t.Errorf("expected the created redemption skipped, got %+v", report)
+	}
+	if operation, err := operations.Find(ctx, redemption.ID); err != nil || operation.Status != "failed" {
+		t.Errorf("expected the redemption left failed, got %+v (%v)", operation, err)
+	}
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	fixed_income_service "github.com/silasstoffel/invest-tracker/apps/fixed_income/service"
+	"github.com/silasstoffel/invest-tracker/apps/shared/database"
+	"github.com/silasstoffel/invest-tracker/apps/shared/repository"
+	"github.com/silasstoffel/invest-tracker/apps/shared/telegram"
+	appConfig "github.com/silasstoffel/invest-tracker/config"
+)
+
+var (
+	env     *appConfig.Config
+	settler *fixed_income_service.Settler
+)
+
+func init() {
+	env = appConfig.NewConfigFromEnvVars()
+	cfg, err := config.LoadDefaultConfig(context.Background())
+	if err != nil {
+		m := fmt.Sprintf("Failure to load aws config: %v", err)
+		log.Println(m)
+		panic(m)
+	}
+
+	db, err := database.NewFromConfig(env)
+	if err != nil {
+		m := fmt.Sprintf("Failure to connect to database: %v", err)
+		log.Println(m)
+		panic(m)
+	}
+
+	service := fixed_income_service.NewService(
+		repository.NewInvestmentRepository(db),
+		repository.NewSummaryRepository(db),
+		repository.NewIndexSeriesRepository(db),
+		repository.NewIssuerRepository(db),
+	)
+	settler = fixed_income_service.NewSettler(
+		service,
+		repository.NewOperationStatusRepository(db),
+		repository.NewBondSettlementRepository(db),
+		sqs.NewFromConfig(cfg),
+		env.CreateInvestmentQueueURL,
+	)
+}
+
+func Handler(ctx context.Context) error {
+	prefix := os.Getenv("AWS_LAMBDA_FUNCTION_NAME")
+	tb := telegram.NewTelegramBot(env)
+
+	report, err := settler.Settle(ctx, time.Now(), false)
+	if err != nil {
+		log.Printf("Failure to settle matured bonds: %v", err)
+		tb.SendMessage(fmt.Sprintf("*[%s] Failure to settle matured bonds* ```%s```", prefix, err.Error()))
+		return err
+	}
+
+	log.Printf("Settled %d matured bond(s) for %s, %d waiting for confirmation, %d skipped",
+		len(report.Settled), report.Amount, len(report.Unconfirmed), len(report.Skipped))
+
+	if len(report.Settled) > 0 {
+		lines := []string{}
+		for _, settlement := range report.Settled {
+			lines = append(lines, fmt.Sprintf("%s at %s due %s: principal %s, redeemed %s (%s)",
+				settlement.Symbol, settlement.Brokerage, settlement.DueDate,
+				settlement.Principal.StringFixed(2), settlement.Amount.StringFixed(2), settlement.Source))
+		}
+		tb.SendMessage(fmt.Sprintf("*[%s] %d bond(s) matured* ```%s```", prefix, len(report.Settled), strings.Join(lines, "\n")))
+	}
+
+	if len(report.Unconfirmed) > 0 {
+		lines := []string{}
+		for _, settlement := range report.Unconfirmed {
+			lines = append(lines, fmt.Sprintf("%s at %s due %s: principal %s, confirm with settle --summary %s --amount <amount>",
+				settlement.Symbol, settlement.Brokerage, settlement.DueDate, settlement.Principal.StringFixed(2), settlement.SummaryID))
+		}
+		tb.SendMessage(fmt.Sprintf("*[%s] %d matured bond(s) waiting for the amount received* ```%s```", prefix, len(report.Unconfirmed), strings.Join(lines, "\n")))
+	}
+
+	if len(report.Skipped) > 0 {
+		tb.SendMessage(fmt.Sprintf("*[%s] %d matured bond(s) could not be settled* ```%s```", prefix, len(report.Skipped), strings.Join(report.Skipped, "\n")))
+	}
+
+	return nil
+}
+
+func main() {
+	lambda.Start(Handler)
+}
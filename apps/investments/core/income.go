@@ -0,0 +1,22 @@
+package investment_core
+
+import "github.com/silasstoffel/invest-tracker/apps/shared/decimal"
+
+// JcpWithholdingRate is the income tax withheld at source on juros sobre capital próprio.
+var JcpWithholdingRate = decimal.RequireFromString("0.15")
+
+// TaxWithheld returns the income tax withheld at source on an income. An
+// informed value is kept, otherwise JCP is withheld at JcpWithholdingRate and
+// the other incomes are exempt.
+func TaxWithheld(input CreateInvestmentInput) decimal.Decimal {
+	if input.OperationType != IncomeOperationType {
+		return decimal.Zero
+	}
+	if input.TaxWithheld.IsPositive() {
+		return input.TaxWithheld.Money()
+	}
+	if input.OperationSubtype == JcpIncome {
+		return input.TotalValue.Mul(JcpWithholdingRate).Money()
+	}
+	return decimal.Zero
+}
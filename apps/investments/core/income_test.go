@@ -0,0 +1,45 @@
+package investment_core
+
+import (
+	"testing"
+
+	"github.com/silasstoffel/invest-tracker/apps/shared/decimal"
+)
+
+func TestTaxWithheld(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    CreateInvestmentInput
+		expected string
+	}{
+		{
+			name:     "jcp is withheld at 15%",
+			input:    CreateInvestmentInput{OperationType: IncomeOperationType, OperationSubtype: JcpIncome, TotalValue: decimal.RequireFromString("123.45")},
+			expected: "18.52",
+		},
+		{
+			name:     "dividends are exempt",
+			input:    CreateInvestmentInput{OperationType: IncomeOperationType, OperationSubtype: DividendIncome, TotalValue: decimal.NewFromInt(100)},
+			expected: "0",
+		},
+		{
+			name:     "informed value is kept",
+			input:    CreateInvestmentInput{OperationType: IncomeOperationType, OperationSubtype: RendimentoIncome, TotalValue: decimal.NewFromInt(100), TaxWithheld: decimal.NewFromInt(20)},
+			expected: "20",
+		},
+		{
+			name:     "other operations have no withholding",
+			input:    CreateInvestmentInput{OperationType: SellOperationType, TotalValue: decimal.NewFromInt(100), TaxWithheld: decimal.NewFromInt(20)},
+			expected: "0",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result := TaxWithheld(test.input)
+			if !result.Equal(decimal.RequireFromString(test.expected)) {
+				t.Errorf("expected %s, got %s", test.expected, result)
+			}
+		})
+	}
+}
@@ -0,0 +1,22 @@
+package investment_core
+
+// IsProductKind tells whether kind is a known bond product.
+func IsProductKind(kind string) bool {
+	switch kind {
+	case CdbProduct, LciProduct, LcaProduct, CriProduct, CraProduct, DebentureProduct, TesouroProduct:
+		return true
+	default:
+		return false
+	}
+}
+
+// IsFgcCovered tells whether the product is guaranteed by the FGC (Fundo
+// Garantidor de Créditos), up to its limit per conglomerate.
+func IsFgcCovered(kind string) bool {
+	switch kind {
+	case CdbProduct, LciProduct, LcaProduct:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,14 @@
+package investment_core
+
+import "strings"
+
+// NormalizeSymbol trims the symbol and upper-cases tickers, so positions are
+// keyed the same way however the operation was informed. Bond names are kept
+// as informed, their positions are keyed by the purchase.
+func NormalizeSymbol(investmentType, symbol string) string {
+	symbol = strings.TrimSpace(symbol)
+	if investmentType == BondInvestmentType {
+		return symbol
+	}
+	return strings.ToUpper(symbol)
+}
@@ -0,0 +1,21 @@
+package investment_core
+
+import "testing"
+
+func TestNormalizeSymbol(t *testing.T) {
+	cases := []struct {
+		investmentType string
+		symbol         string
+		expected       string
+	}{
+		{StockInvestmentType, " petr4 ", "PETR4"},
+		{FiiInvestmentType, "Mxrf11", "MXRF11"},
+		{BondInvestmentType, " CDB Banco Inter ", "CDB Banco Inter"},
+	}
+
+	for _, c := range cases {
+		if got := NormalizeSymbol(c.investmentType, c.symbol); got != c.expected {
+			t.Errorf("NormalizeSymbol(%q, %q) = %q, expected %q", c.investmentType, c.symbol, got, c.expected)
+		}
+	}
+}
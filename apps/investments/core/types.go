@@ -2,6 +2,8 @@ package investment_core
 
 import (
 	"time"
+
+	"github.com/silasstoffel/invest-tracker/apps/shared/decimal"
 )
 
 const (
@@ -13,60 +15,132 @@ const (
 	EtfInvestmentType   = "etf"
 
 	// operation types
-	BuyOperationType  = "buy"
-	SellOperationType = "sell"
+	BuyOperationType             = "buy"
+	SellOperationType            = "sell"
+	CorporateActionOperationType = "corporate_action"
+	IncomeOperationType          = "income"
+
+	// corporate actions, the operation subtype of corporate_action operations
+	SplitCorporateAction        = "split"
+	ReverseSplitCorporateAction = "reverse_split"
+	BonusCorporateAction        = "bonus"
+
+	// incomes, the operation subtype of income operations
+	DividendIncome     = "dividend"
+	JcpIncome          = "jcp"
+	FiiIncome          = "fii_income"
+	RendimentoIncome   = "rendimento"
+	AmortizationIncome = "amortization"
 
 	BondIndexCDI    = "cdi"
 	BondIndexIPCA   = "ipca"
 	BondIndexSELIC  = "selic"
 	BondIndexPrefix = "prefixed"
 
+	// bond products
+	CdbProduct       = "cdb"
+	LciProduct       = "lci"
+	LcaProduct       = "lca"
+	CriProduct       = "cri"
+	CraProduct       = "cra"
+	DebentureProduct = "debenture"
+	TesouroProduct   = "tesouro"
+
 	HybridRedemption     = "hybrid"
 	AnyTimeRedemption    = "any_time"
 	AtMaturityRedemption = "at_maturity"
+
+	// operation status, from scheduling to the position update
+	QueuedOperationStatus     = "queued"
+	PersistedOperationStatus  = "persisted"
+	SummarizedOperationStatus = "summarized"
+	FailedOperationStatus     = "failed"
 )
 
 type InvestmentEntity struct {
-	ID                   string    `json:"id"`
-	Type                 string    `json:"type"`
-	Symbol               string    `json:"symbol"`
-	BondIndex            string    `json:"bondIndex,omitempty"`
-	BondRate             float64   `json:"bondRate,omitempty"`
-	Quantity             float64   `json:"quantity"`
-	UnitPrice            float64   `json:"unitPrice"`
-	TotalValue           float64   `json:"totalValue"`
-	Cost                 float64   `json:"cost"`
-	OperationType        string    `json:"operationType"`
-	OperationDate        string    `json:"operationDate"`
-	OperationYear        int       `json:"operationYear"`
-	OperationMonth       int       `json:"operationMonth"`
-	DueDate              string    `json:"dueDate"`
-	Brokerage            string    `json:"brokerage"`
-	Note                 string    `json:"note"`
-	RedemptionPolicyType string    `json:"redemptionPolicyType"`
-	SellInvestmentId     string    `json:"sellInvestmentId,omitempty"`
-	CreatedAt            time.Time `json:"createdAt"`
-	UpdatedAt            time.Time `json:"updatedAt"`
+	ID                   string          `json:"id"`
+	Type                 string          `json:"type"`
+	Symbol               string          `json:"symbol"`
+	BondIndex            string          `json:"bondIndex,omitempty"`
+	BondRate             decimal.Decimal `json:"bondRate,omitzero"`
+	Quantity             decimal.Decimal `json:"quantity"`
+	UnitPrice            decimal.Decimal `json:"unitPrice"`
+	TotalValue           decimal.Decimal `json:"totalValue"`
+	Cost                 decimal.Decimal `json:"cost"`
+	OperationType        string          `json:"operationType"`
+	OperationSubtype     string          `json:"operationSubtype,omitempty"`
+	Ratio                decimal.Decimal `json:"ratio,omitzero"`
+	OperationDate        string          `json:"operationDate"`
+	OperationYear        int             `json:"operationYear"`
+	OperationMonth       int             `json:"operationMonth"`
+	DueDate              string          `json:"dueDate"`
+	Brokerage            string          `json:"brokerage"`
+	Note                 string          `json:"note"`
+	RedemptionPolicyType string          `json:"redemptionPolicyType"`
+	Issuer               string          `json:"issuer,omitempty"`
+	ProductKind          string          `json:"productKind,omitempty"`
+	GracePeriodDate      string          `json:"gracePeriodDate,omitempty"`
+	SellInvestmentId     string          `json:"sellInvestmentId,omitempty"`
+	IdempotencyKey       string          `json:"idempotencyKey,omitempty"`
+	Pnl                  decimal.Decimal `json:"pnl"`
+	AverageSellingPrice  decimal.Decimal `json:"averageSellingPrice"`
+	DayTradeQuantity     decimal.Decimal `json:"dayTradeQuantity,omitzero"`
+	DayTradePnl          decimal.Decimal `json:"dayTradePnl,omitzero"`
+	TaxWithheld          decimal.Decimal `json:"taxWithheld,omitzero"`
+	// bond redemptions: IOF withheld and the proceeds after the taxes
+	Iof         decimal.Decimal `json:"iof,omitzero"`
+	NetProceeds decimal.Decimal `json:"netProceeds,omitzero"`
+	CreatedAt   time.Time       `json:"createdAt"`
+	UpdatedAt   time.Time       `json:"updatedAt"`
 }
 
 type CreateInvestmentInput struct {
-	Type                 string  `json:"type"`
-	Symbol               string  `json:"symbol"`
-	BondIndex            string  `json:"bondIndex,omitempty"`
-	BondRate             float64 `json:"bondRate,omitempty"`
-	Quantity             float64 `json:"quantity"`
-	TotalValue           float64 `json:"totalValue"`
-	Cost                 float64 `json:"cost"`
-	OperationType        string  `json:"operationType"`
-	OperationDate        string  `json:"operationDate"`
-	DueDate              string  `json:"dueDate"`
-	Brokerage            string  `json:"brokerage"`
-	Note                 string  `json:"note"`
-	RedemptionPolicyType string  `json:"redemptionPolicyType"`
+	// assigned by the schedule endpoint, it becomes the investment id
+	ID                   string          `json:"id,omitempty"`
+	Type                 string          `json:"type"`
+	Symbol               string          `json:"symbol"`
+	BondIndex            string          `json:"bondIndex,omitempty"`
+	BondRate             decimal.Decimal `json:"bondRate,omitzero"`
+	Quantity             decimal.Decimal `json:"quantity"`
+	TotalValue           decimal.Decimal `json:"totalValue"`
+	Cost                 decimal.Decimal `json:"cost"`
+	OperationType        string          `json:"operationType"`
+	OperationDate        string          `json:"operationDate"`
+	DueDate              string          `json:"dueDate"`
+	Brokerage            string          `json:"brokerage"`
+	Note                 string          `json:"note"`
+	RedemptionPolicyType string          `json:"redemptionPolicyType"`
+	// bonds: the institution that issued it and the product (cdb, lci, lca, cri,
+	// cra, debenture or tesouro)
+	Issuer      string `json:"issuer,omitempty"`
+	ProductKind string `json:"productKind,omitempty"`
+	// hybrid bonds: YYYY-MM-DD, end of the grace period (carência), when the
+	// bond can be redeemed at any time
+	GracePeriodDate string `json:"gracePeriodDate,omitempty"`
+	// corporate actions: split/reverse_split scale the quantity by the ratio,
+	// bonus adds ratio new shares per share held at the declared unitPrice.
+	// The total value is the cash received for the fractions left (cash in lieu).
+	OperationSubtype string          `json:"operationSubtype,omitempty"`
+	Ratio            decimal.Decimal `json:"ratio,omitzero"`
+	UnitPrice        decimal.Decimal `json:"unitPrice,omitzero"`
+	// incomes: the total value is the gross amount, the tax withheld defaults to
+	// the JCP rate
+	TaxWithheld decimal.Decimal `json:"taxWithheld,omitzero"`
 	// required for bond investment and sell operation type
 	SellInvestmentId string `json:"sellInvestmentId,omitempty"`
+	// set by the schedule endpoint from the Idempotency-Key header
+	IdempotencyKey string `json:"idempotencyKey,omitempty"`
 }
 
 type CreateInvestmentOutput struct {
 	Message string `json:"message"`
 }
+
+type OperationStatusEntity struct {
+	ID             string    `json:"id"`
+	IdempotencyKey string    `json:"idempotencyKey,omitempty"`
+	Status         string    `json:"status"`
+	Reason         string    `json:"reason,omitempty"`
+	CreatedAt      time.Time `json:"createdAt"`
+	UpdatedAt      time.Time `json:"updatedAt"`
+}
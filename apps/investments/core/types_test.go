@@ -0,0 +1,25 @@
+package investment_core
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestInvestmentEntityJSON(t *testing.T) {
+	content, err := json.Marshal(InvestmentEntity{ID: "01", OperationType: BuyOperationType})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var fields map[string]any
+	if err := json.Unmarshal(content, &fields); err != nil {
+		t.Fatal(err)
+	}
+
+	// clients read the realized result of every operation, zero included
+	for _, field := range []string{"pnl", "averageSellingPrice"} {
+		if value, ok := fields[field]; !ok || value != 0.0 {
+			t.Errorf("expected %s to be serialized as 0, got %v", field, value)
+		}
+	}
+}
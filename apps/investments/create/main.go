@@ -13,23 +13,24 @@ import (
 
 	"github.com/aws/aws-lambda-go/events"
 	"github.com/aws/aws-lambda-go/lambda"
-	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/service/sqs"
-	"github.com/cloudflare/cloudflare-go/v4"
-	"github.com/cloudflare/cloudflare-go/v4/d1"
-	"github.com/cloudflare/cloudflare-go/v4/option"
 	"github.com/oklog/ulid/v2"
 	investment_core "github.com/silasstoffel/invest-tracker/apps/investments/core"
+	"github.com/silasstoffel/invest-tracker/apps/shared/database"
+	"github.com/silasstoffel/invest-tracker/apps/shared/decimal"
+	"github.com/silasstoffel/invest-tracker/apps/shared/outbox"
+	"github.com/silasstoffel/invest-tracker/apps/shared/repository"
 	"github.com/silasstoffel/invest-tracker/apps/shared/telegram"
 	appConfig "github.com/silasstoffel/invest-tracker/config"
 )
 
 var (
-	cfClient  *cloudflare.Client
-	env       *appConfig.Config
-	sqsClient *sqs.Client
-	queueURL  string
+	env                       *appConfig.Config
+	relay                     *outbox.Relay
+	investmentRepository      repository.InvestmentRepository
+	operationStatusRepository repository.OperationStatusRepository
+	symbolAliasRepository     repository.SymbolAliasRepository
 )
 
 func init() {
@@ -42,13 +43,19 @@ func init() {
 		panic(m)
 	}
 
-	cfClient = cloudflare.NewClient(
-		option.WithAPIToken(env.Cloudflare.ApiKey),
-	)
+	db, err := database.NewFromConfig(env)
+	if err != nil {
+		m := fmt.Sprintf("Failure to connect to database: %v", err)
+		log.Println(m)
+		panic(m)
+	}
+	investmentRepository = repository.NewInvestmentRepository(db)
+	operationStatusRepository = repository.NewOperationStatusRepository(db)
+	symbolAliasRepository = repository.NewSymbolAliasRepository(db)
 
-	sqsClient = sqs.NewFromConfig(cfg)
-	config := appConfig.NewConfigFromEnvVars()
-	queueURL = config.CalculateAveragePriceQueueURL
+	relay = outbox.NewRelay(sqs.NewFromConfig(cfg), repository.NewOutboxRepository(db), map[string]string{
+		outbox.CalculateAveragePrice: env.CalculateAveragePriceQueueURL,
+	})
 }
 
 func createId() string {
@@ -58,84 +65,109 @@ func createId() string {
 	return ulid.MustNew(ulid.Timestamp(t), entropy).String()
 }
 
-func saveInvestment(entity investment_core.InvestmentEntity) error {
-	command := `INSERT INTO investments (
-		id, type, symbol, quantity, unit_price, total_value, cost, operation_type, operation_date,
-		operation_year, operation_month, due_date, created_at, updated_at, brokerage, note, redemption_policy_type, sell_investment_id {add_column_name}) VALUES (
-			?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?{add_column_value})`
-
-	params := []string{
-		entity.ID,
-		entity.Type,
-		entity.Symbol,
-		fmt.Sprintf("%v", entity.Quantity),
-		fmt.Sprintf("%v", entity.UnitPrice),
-		fmt.Sprintf("%v", entity.TotalValue),
-		fmt.Sprintf("%v", entity.Cost),
-		entity.OperationType,
-		entity.OperationDate,
-		fmt.Sprintf("%v", entity.OperationYear),
-		fmt.Sprintf("%v", entity.OperationMonth),
-		entity.DueDate,
-		entity.CreatedAt.Format(time.RFC3339),
-		entity.UpdatedAt.Format(time.RFC3339),
-		entity.Brokerage,
-		entity.Note,
-		entity.RedemptionPolicyType,
-		entity.SellInvestmentId,
-	}
-
-	if entity.BondIndex != "" {
-		command = strings.Replace(command, "{add_column_name}", ", bond_index, bond_rate", 1)
-		command = strings.Replace(command, "{add_column_value}", ",?,?", 1)
-		params = append(params, entity.BondIndex, fmt.Sprintf("%v", entity.BondRate))
-	} else {
-		command = strings.Replace(command, "{add_column_name}", "", 1)
-		command = strings.Replace(command, "{add_column_value}", "", 1)
-	}
-
-	_, err := cfClient.D1.Database.Raw(
-		context.TODO(),
-		env.Cloudflare.InvestmentTrackDbId,
-		d1.DatabaseRawParams{
-			AccountID: cloudflare.F(env.Cloudflare.AccountId),
-			Sql:       cloudflare.F(command),
-			Params:    cloudflare.F(params),
-		},
-	)
+// findDuplicate returns the investment already created for the message, when
+// the same request was scheduled or delivered more than once. It is found by
+// the idempotency key or, as SQS may deliver a message twice, by the id the
+// schedule endpoint assigned.
+func findDuplicate(ctx context.Context, data investment_core.CreateInvestmentInput) (investment_core.InvestmentEntity, bool) {
+	if data.IdempotencyKey != "" {
+		existing, err := investmentRepository.FindByIdempotencyKey(ctx, data.IdempotencyKey)
+		if err == nil {
+			log.Printf("Investment %s was already created for idempotency key %s, skipping", existing.ID, data.IdempotencyKey)
+			return existing, true
+		}
+		if !errors.Is(err, repository.ErrNotFound) {
+			log.Printf("Failure to look up idempotency key %s: %v", data.IdempotencyKey, err)
+		}
+	}
+
+	if data.ID != "" {
+		existing, err := investmentRepository.FindByID(ctx, data.ID)
+		if err == nil {
+			log.Printf("Investment %s was already created, skipping", existing.ID)
+			return existing, true
+		}
+		if !errors.Is(err, repository.ErrNotFound) {
+			log.Printf("Failure to look up investment %s: %v", data.ID, err)
+		}
+	}
 
+	return investment_core.InvestmentEntity{}, false
+}
+
+// redirectSymbol replaces a ticker that changed by the current one. Trades and
+// corporate actions dated before the change are in old shares, so quantity and
+// unit price are converted as the operations moved by the change were.
+func redirectSymbol(ctx context.Context, data *investment_core.CreateInvestmentInput) {
+	alias, err := symbolAliasRepository.FindByOldSymbol(ctx, data.Symbol)
 	if err != nil {
-		m := fmt.Sprintf("Error executing command on cloudflare. Detail: %v", err)
-		log.Printf(m)
-		log.Printf("Command: %s", command)
-		log.Printf("Params: %v", params)
-		return errors.New(m)
+		if !errors.Is(err, repository.ErrNotFound) {
+			log.Printf("Failure to look up alias of %s: %v", data.Symbol, err)
+		}
+		return
 	}
 
-	return nil
+	log.Printf("Symbol %s changed to %s on %s, redirecting the operation", alias.OldSymbol, alias.NewSymbol, alias.EffectiveDate)
+	data.Symbol = alias.NewSymbol
+
+	if alias.EffectiveDate != "" && data.OperationDate >= alias.EffectiveDate {
+		return
+	}
+	if data.Type == investment_core.BondInvestmentType || data.OperationType == investment_core.IncomeOperationType || !alias.Ratio.IsPositive() {
+		return
+	}
+	data.Quantity = data.Quantity.Mul(alias.Ratio)
+	data.UnitPrice = data.UnitPrice.Div(alias.Ratio)
 }
 
-func createInvestment(input string) (investment_core.InvestmentEntity, error) {
+func createInvestment(ctx context.Context, input string) (investment_core.InvestmentEntity, repository.OutboxMessage, error) {
 	var data investment_core.CreateInvestmentInput
 	err := json.Unmarshal([]byte(input), &data)
 
 	if err != nil {
 		log.Println("Failure to convert json input to create investment input. Detail: ", input)
-		return investment_core.InvestmentEntity{}, err
+		return investment_core.InvestmentEntity{}, repository.OutboxMessage{}, err
+	}
+	// messages queued before the schedule endpoint normalised the symbol
+	data.Symbol = investment_core.NormalizeSymbol(data.Type, data.Symbol)
+
+	if existing, found := findDuplicate(ctx, data); found {
+		return existing, repository.OutboxMessage{}, nil
+	}
+
+	redirectSymbol(ctx, &data)
+
+	// operations scheduled before the schedule endpoint assigned ids
+	id := data.ID
+	if id == "" {
+		id = createId()
+	}
+
+	// incomes have no quantity and so no unit price
+	unitPrice := decimal.Zero
+	switch {
+	case data.OperationType == investment_core.CorporateActionOperationType:
+		// the cost declared for bonus shares, the quantity comes from the position
+		unitPrice = data.UnitPrice
+	case data.Quantity.IsPositive():
+		unitPrice = data.TotalValue.Div(data.Quantity)
 	}
 
 	od, _ := time.Parse("2006-01-02", data.OperationDate)
 	entity := investment_core.InvestmentEntity{
-		ID:                   createId(),
+		ID:                   id,
 		Type:                 data.Type,
 		Symbol:               data.Symbol,
 		BondIndex:            data.BondIndex,
-		BondRate:             data.BondRate,
-		Quantity:             data.Quantity,
-		UnitPrice:            data.TotalValue / data.Quantity,
-		TotalValue:           data.TotalValue,
-		Cost:                 data.Cost,
+		BondRate:             data.BondRate.Rate(),
+		Quantity:             data.Quantity.Quantity(),
+		UnitPrice:            unitPrice.Price(),
+		TotalValue:           data.TotalValue.Money(),
+		Cost:                 data.Cost.Money(),
 		OperationType:        data.OperationType,
+		OperationSubtype:     data.OperationSubtype,
+		Ratio:                data.Ratio.Quantity(),
+		TaxWithheld:          investment_core.TaxWithheld(data),
 		OperationDate:        data.OperationDate,
 		OperationYear:        od.Year(),
 		OperationMonth:       int(od.Month()),
@@ -144,18 +176,76 @@ func createInvestment(input string) (investment_core.InvestmentEntity, error) {
 		CreatedAt:            time.Now().UTC(),
 		UpdatedAt:            time.Now().UTC(),
 		RedemptionPolicyType: data.RedemptionPolicyType,
+		Issuer:               strings.TrimSpace(data.Issuer),
+		ProductKind:          data.ProductKind,
+		GracePeriodDate:      data.GracePeriodDate,
 		Note:                 data.Note,
 		SellInvestmentId:     data.SellInvestmentId,
+		IdempotencyKey:       data.IdempotencyKey,
+	}
+
+	messageContent, err := json.Marshal(entity)
+	if err != nil {
+		log.Printf("Failure when converting entity message to json. Detail: %v", err)
+		return investment_core.InvestmentEntity{}, repository.OutboxMessage{}, err
+	}
+
+	// the message to calculate the average price is stored with the investment,
+	// so it is never lost when the queue is not reachable
+	message := repository.OutboxMessage{
+		ID:             createId(),
+		AggregateID:    entity.ID,
+		Destination:    outbox.CalculateAveragePrice,
+		MessageGroupID: strings.ReplaceAll(entity.Symbol, " ", "_"),
+		Payload:        string(messageContent),
 	}
 
-	err = saveInvestment(entity)
+	err = investmentRepository.CreateWithOutbox(ctx, entity, message)
 	if err != nil {
+		// a concurrent delivery of the same message was stored first
+		if existing, found := findDuplicate(ctx, data); found {
+			return existing, repository.OutboxMessage{}, nil
+		}
 		log.Printf("Error saving investment: %v", err)
-		return investment_core.InvestmentEntity{}, fmt.Errorf("error saving investment: %w", err)
+		return investment_core.InvestmentEntity{}, repository.OutboxMessage{}, fmt.Errorf("error saving investment: %w", err)
 	}
 
 	log.Println("Investment created successfully. ID:", entity.ID, " Symbol:", entity.Symbol, " Type:", entity.Type, " Total Value:", entity.TotalValue)
-	return entity, nil
+	return entity, message, nil
+}
+
+// operationId reads the id assigned by the schedule endpoint from a message
+// that could not be processed.
+func operationId(body string) string {
+	var data investment_core.CreateInvestmentInput
+	if err := json.Unmarshal([]byte(body), &data); err != nil {
+		return ""
+	}
+	return data.ID
+}
+
+func updateStatus(ctx context.Context, id string, status string, reason string) {
+	if id == "" {
+		return
+	}
+	if err := operationStatusRepository.UpdateStatus(ctx, id, status, reason); err != nil {
+		log.Print(err)
+	}
+}
+
+// markDuplicate gives the operation of a repeated message the status of the
+// investment created the first time, so it does not stay queued.
+func markDuplicate(ctx context.Context, id string, existing investment_core.InvestmentEntity) {
+	status, reason := investment_core.PersistedOperationStatus, ""
+	original, err := operationStatusRepository.Find(ctx, existing.ID)
+	if err == nil && original.Status != investment_core.QueuedOperationStatus {
+		status, reason = original.Status, original.Reason
+	}
+	if id != existing.ID {
+		reason = "duplicate of " + existing.ID
+	}
+
+	updateStatus(ctx, id, status, reason)
 }
 
 func Handler(ctx context.Context, sqsEvent events.SQSEvent) (events.SQSEventResponse, error) {
@@ -171,9 +261,10 @@ func Handler(ctx context.Context, sqsEvent events.SQSEvent) (events.SQSEventResp
 			continue
 		}
 
-		entity, err := createInvestment(message.Body)
+		entity, outboxMessage, err := createInvestment(ctx, message.Body)
 
 		if err != nil {
+			updateStatus(ctx, operationId(message.Body), investment_core.FailedOperationStatus, err.Error())
 			log.Printf("Error processing message %s: %v", message.MessageId, err)
 			log.Printf("Received message: %s", message.Body)
 			batchItemFailures = append(batchItemFailures, events.SQSBatchItemFailure{ItemIdentifier: message.MessageId})
@@ -181,24 +272,20 @@ func Handler(ctx context.Context, sqsEvent events.SQSEvent) (events.SQSEventResp
 			continue
 		}
 
-		messageContent, err := json.Marshal(entity)
-		if err != nil {
-			m := "Failure when converting entity message to json. Message was not sent to recalculate average price. Detail: %v"
-			log.Printf(m, err)
+		// duplicated request, the original operation already has its own outbox message
+		if outboxMessage.ID == "" {
+			markDuplicate(ctx, operationId(message.Body), entity)
 			continue
 		}
-		log.Printf("Sending message to calculate-average-price-env-queue.fifo: %s", string(messageContent))
-		_, err = sqsClient.SendMessage(ctx, &sqs.SendMessageInput{
-			QueueUrl:               aws.String(queueURL),
-			MessageBody:            aws.String(string(messageContent)),
-			MessageDeduplicationId: aws.String(entity.ID),
-			MessageGroupId:         aws.String(strings.ReplaceAll(entity.Symbol, " ", "_")),
-		})
+		updateStatus(ctx, entity.ID, investment_core.PersistedOperationStatus, "")
+
+		log.Printf("Sending message to calculate-average-price-env-queue.fifo: %s", outboxMessage.Payload)
+		err = relay.Deliver(ctx, outboxMessage)
 
 		if err != nil {
-			m := "Failure to send message to calculate-average-price-env-queue.fifo. Detail: %v"
-			log.Printf(m, err)
-			tb.SendMessage(fmt.Sprintf("*[%s] Failure when create an investment.* Detail:```sh %s```  Input:```json %s```", prefix, m, string(sqsEvent.Records[0].Body)))
+			m := fmt.Sprintf("Failure to send message to calculate-average-price-env-queue.fifo, the outbox relay will retry it. Detail: %v", err)
+			log.Print(m)
+			tb.SendMessage(fmt.Sprintf("*[%s] Investment created, average price calculation is delayed.* Detail:```sh %s```  Input:```json %s```", prefix, m, message.Body))
 		}
 	}
 
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	investment_core "github.com/silasstoffel/invest-tracker/apps/investments/core"
+	"github.com/silasstoffel/invest-tracker/apps/shared/database"
+	"github.com/silasstoffel/invest-tracker/apps/shared/decimal"
+	"github.com/silasstoffel/invest-tracker/apps/shared/outbox"
+	"github.com/silasstoffel/invest-tracker/apps/shared/repository"
+	appConfig "github.com/silasstoffel/invest-tracker/config"
+)
+
+type fakeSender struct {
+	messages int
+}
+
+func (s *fakeSender) SendMessage(ctx context.Context, params *sqs.SendMessageInput, optFns ...func(*sqs.Options)) (*sqs.SendMessageOutput, error) {
+	s.messages++
+	return &sqs.SendMessageOutput{}, nil
+}
+
+// setup points the consumer at a sqlite database, with no Telegram alerts.
+func setup(t *testing.T) (*database.SQLite, *fakeSender) {
+	t.Helper()
+	db, err := database.NewSQLite(":memory:")
+	if err != nil {
+		t.Fatalf("failure to open database: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	sender := &fakeSender{}
+	env.TelegramConfig = &appConfig.TelegramConfig{}
+	investmentRepository = repository.NewInvestmentRepository(db)
+	operationStatusRepository = repository.NewOperationStatusRepository(db)
+	symbolAliasRepository = repository.NewSymbolAliasRepository(db)
+	relay = outbox.NewRelay(sender, repository.NewOutboxRepository(db), map[string]string{outbox.CalculateAveragePrice: "calculate-average-price"})
+	return db, sender
+}
+
+func TestHandlerRedelivery(t *testing.T) {
+	ctx := context.Background()
+	_, sender := setup(t)
+
+	// scheduled without an Idempotency-Key
+	input := investment_core.CreateInvestmentInput{
+		ID: "01JREDELIVERED000000000000", Type: "stock", Symbol: "VALE3", Brokerage: "xp", OperationType: "buy",
+		OperationDate: "2025-03-10", Quantity: decimal.NewFromInt(10), TotalValue: decimal.NewFromInt(600),
+	}
+	if err := operationStatusRepository.Queue(ctx, input.ID, ""); err != nil {
+		t.Fatalf("failure to queue operation: %v", err)
+	}
+	body, _ := json.Marshal(input)
+	event := events.SQSEvent{Records: []events.SQSMessage{{MessageId: "1", Body: string(body)}}}
+
+	for delivery := 1; delivery <= 2; delivery++ {
+		response, err := Handler(ctx, event)
+		if err != nil || len(response.BatchItemFailures) != 0 {
+			t.Fatalf("expected delivery %d to succeed, got %+v (%v)", delivery, response, err)
+		}
+	}
+
+	operations, err := investmentRepository.Find(ctx, repository.InvestmentFilter{Symbol: "VALE3"})
+	if err != nil || len(operations) != 1 {
+		t.Fatalf("expected a single investment, got %d (%v)", len(operations), err)
+	}
+	if sender.messages != 1 {
+		t.Errorf("expected the average price requested once, got %d", sender.messages)
+	}
+	if operation, err := operationStatusRepository.Find(ctx, input.ID); err != nil || operation.Status != investment_core.PersistedOperationStatus {
+		t.Errorf("expected the operation kept persisted, got %+v (%v)", operation, err)
+	}
+}
+
+func TestRedirectSymbol(t *testing.T) {
+	ctx := context.Background()
+	db, _ := setup(t)
+
+	// OLDS3 became NEWS3 with 2 new shares for each old one
+	err := db.Exec(ctx, "INSERT INTO symbol_aliases (old_symbol, new_symbol, ratio, effective_date) VALUES (?, ?, ?, ?)", "OLDS3", "NEWS3", "2", "2025-03-01")
+	if err != nil {
+		t.Fatalf("failure to create alias: %v", err)
+	}
+
+	tests := []struct {
+		date      string
+		quantity  int64
+		unitPrice int64
+	}{
+		{date: "2025-02-10", quantity: 20, unitPrice: 5},
+		{date: "2025-03-10", quantity: 10, unitPrice: 10},
+	}
+
+	for _, test := range tests {
+		input := investment_core.CreateInvestmentInput{
+			ID: "01JREDIRECT" + test.date, Type: "stock", Symbol: "OLDS3", Brokerage: "xp", OperationType: "buy",
+			OperationDate: test.date, Quantity: decimal.NewFromInt(10), TotalValue: decimal.NewFromInt(100),
+		}
+		body, _ := json.Marshal(input)
+
+		entity, _, err := createInvestment(ctx, string(body))
+		if err != nil {
+			t.Fatalf("failure to create investment: %v", err)
+		}
+		if entity.Symbol != "NEWS3" || !entity.Quantity.Equal(decimal.NewFromInt(test.quantity)) || !entity.UnitPrice.Equal(decimal.NewFromInt(test.unitPrice)) {
+			t.Errorf("expected the operation of %s as %d NEWS3 at %d, got %s %s at %s", test.date, test.quantity, test.unitPrice, entity.Quantity, entity.Symbol, entity.UnitPrice)
+		}
+	}
+}
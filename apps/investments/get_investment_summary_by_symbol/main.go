@@ -2,27 +2,75 @@ package main
 
 import (
 	"context"
+	"fmt"
+	"log"
+	"net/url"
+	"strings"
 
 	"github.com/aws/aws-lambda-go/events"
 	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/silasstoffel/invest-tracker/apps/shared/database"
 	http_helper "github.com/silasstoffel/invest-tracker/apps/shared/http_helpers"
+	"github.com/silasstoffel/invest-tracker/apps/shared/repository"
+	appConfig "github.com/silasstoffel/invest-tracker/config"
 )
 
-type Response events.APIGatewayProxyResponse
+type ErrorOutput struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+var (
+	env               *appConfig.Config
+	summaryRepository repository.SummaryRepository
+)
+
+func init() {
+	env = appConfig.NewConfigFromEnvVars()
+
+	db, err := database.NewFromConfig(env)
+	if err != nil {
+		m := fmt.Sprintf("Failure to connect to database: %v", err)
+		log.Println(m)
+		panic(m)
+	}
+	summaryRepository = repository.NewSummaryRepository(db)
+}
 
 func Handler(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	symbol, err := url.PathUnescape(request.PathParameters["symbol"])
+	symbol = strings.TrimSpace(symbol)
+
+	if err != nil || symbol == "" {
+		return http_helper.JsonResponse(ErrorOutput{
+			Code:    "INVALID_REQUEST",
+			Message: "symbol is required",
+		}, http_helper.JsonResponseOptions{StatusCode: 400}), nil
+	}
+
+	brokerage := strings.TrimSpace(request.QueryStringParameters["brokerage"])
+	investmentType := strings.ToLower(strings.TrimSpace(request.QueryStringParameters["type"]))
 
-	type CustomResponse struct {
-		Code    string `json:"raw_code"`
-		Message string `json:"raw_message"`
+	summaries, err := summaryRepository.Find(ctx, repository.SummaryFilter{
+		Symbol:    symbol,
+		Brokerage: brokerage,
+		Type:      investmentType,
+	})
+	if err != nil {
+		return http_helper.JsonResponse(ErrorOutput{
+			Code:    "INTERNAL_ERROR",
+			Message: "failure to read investment summary",
+		}, http_helper.JsonResponseOptions{StatusCode: 500}), nil
 	}
 
-	data := CustomResponse{
-		Code:    "SUCCESS",
-		Message: "Investment summary retrieved successfully",
+	if len(summaries) == 0 {
+		return http_helper.JsonResponse(ErrorOutput{
+			Code:    "NOT_FOUND",
+			Message: fmt.Sprintf("no investment summary found for symbol %s", symbol),
+		}, http_helper.JsonResponseOptions{StatusCode: 404}), nil
 	}
 
-	return http_helper.JsonResponse(data), nil
+	return http_helper.JsonResponse(summaries), nil
 }
 
 func main() {
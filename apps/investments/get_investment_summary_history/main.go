@@ -0,0 +1,155 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambda"
+	investment_summary_core "github.com/silasstoffel/invest-tracker/apps/investments_summary/core"
+	"github.com/silasstoffel/invest-tracker/apps/shared/database"
+	http_helper "github.com/silasstoffel/invest-tracker/apps/shared/http_helpers"
+	"github.com/silasstoffel/invest-tracker/apps/shared/repository"
+	appConfig "github.com/silasstoffel/invest-tracker/config"
+)
+
+type ErrorOutput struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// HistoryOutput is the series of one position: a symbol in a brokerage, or a bond purchase.
+type HistoryOutput struct {
+	SummaryID    string                                 `json:"summaryId"`
+	InvestmentID string                                 `json:"investmentId,omitempty"`
+	Symbol       string                                 `json:"symbol"`
+	Type         string                                 `json:"type"`
+	Brokerage    string                                 `json:"brokerage"`
+	Granularity  string                                 `json:"granularity"`
+	Points       []investment_summary_core.HistoryPoint `json:"points"`
+}
+
+var (
+	env               *appConfig.Config
+	summaryRepository repository.SummaryRepository
+)
+
+func init() {
+	env = appConfig.NewConfigFromEnvVars()
+
+	db, err := database.NewFromConfig(env)
+	if err != nil {
+		m := fmt.Sprintf("Failure to connect to database: %v", err)
+		log.Println(m)
+		panic(m)
+	}
+	summaryRepository = repository.NewSummaryRepository(db)
+}
+
+func badRequest(message string) events.APIGatewayProxyResponse {
+	return http_helper.JsonResponse(ErrorOutput{
+		Code:    "INVALID_REQUEST",
+		Message: message,
+	}, http_helper.JsonResponseOptions{StatusCode: 400})
+}
+
+func internalError(message string) events.APIGatewayProxyResponse {
+	return http_helper.JsonResponse(ErrorOutput{
+		Code:    "INTERNAL_ERROR",
+		Message: message,
+	}, http_helper.JsonResponseOptions{StatusCode: 500})
+}
+
+// since drops the points before the from date, compared in the layout of the granularity.
+func since(points []investment_summary_core.HistoryPoint, from string, granularity string) []investment_summary_core.HistoryPoint {
+	if from == "" {
+		return points
+	}
+	if granularity == investment_summary_core.MonthlyGranularity {
+		from = from[:7]
+	}
+
+	filtered := []investment_summary_core.HistoryPoint{}
+	for _, point := range points {
+		if point.Date >= from {
+			filtered = append(filtered, point)
+		}
+	}
+	return filtered
+}
+
+func Handler(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	symbol, err := url.PathUnescape(request.PathParameters["symbol"])
+	symbol = strings.TrimSpace(symbol)
+
+	if err != nil || symbol == "" {
+		return badRequest("symbol is required"), nil
+	}
+
+	params := request.QueryStringParameters
+	granularity := strings.ToLower(strings.TrimSpace(params["granularity"]))
+	if granularity == "" {
+		granularity = investment_summary_core.OperationGranularity
+	}
+	if err := investment_summary_core.CheckGranularity(granularity); err != nil {
+		return badRequest(err.Error()), nil
+	}
+
+	from := strings.TrimSpace(params["from"])
+	if _, err := time.Parse("2006-01-02", from); from != "" && err != nil {
+		return badRequest("from must be in the format YYYY-MM-DD"), nil
+	}
+
+	until := time.Now().UTC()
+	if to := strings.TrimSpace(params["to"]); to != "" {
+		if until, err = time.Parse("2006-01-02", to); err != nil {
+			return badRequest("to must be in the format YYYY-MM-DD"), nil
+		}
+	}
+
+	summaries, err := summaryRepository.Find(ctx, repository.SummaryFilter{
+		Symbol:    symbol,
+		Brokerage: strings.TrimSpace(params["brokerage"]),
+		Type:      strings.ToLower(strings.TrimSpace(params["type"])),
+	})
+	if err != nil {
+		log.Printf("Failure to read investment summary: %v", err)
+		return internalError("failure to read investment summary"), nil
+	}
+
+	if len(summaries) == 0 {
+		return http_helper.JsonResponse(ErrorOutput{
+			Code:    "NOT_FOUND",
+			Message: fmt.Sprintf("no investment summary found for symbol %s", symbol),
+		}, http_helper.JsonResponseOptions{StatusCode: 404}), nil
+	}
+
+	outputs := []HistoryOutput{}
+	for _, summary := range summaries {
+		snapshots, err := summaryRepository.FindHistory(ctx, summary.ID)
+		if err != nil {
+			log.Printf("Failure to read history of summary %s: %v", summary.ID, err)
+			return internalError("failure to read investment summary history"), nil
+		}
+
+		outputs = append(outputs, HistoryOutput{
+			SummaryID:    summary.ID,
+			InvestmentID: summary.InvestmentID,
+			Symbol:       summary.Symbol,
+			Type:         summary.Type,
+			Brokerage:    summary.Brokerage,
+			Granularity:  granularity,
+			Points:       since(investment_summary_core.History(snapshots, granularity, until), from, granularity),
+		})
+	}
+
+	return http_helper.JsonResponse(outputs), nil
+}
+
+func main() {
+	lambda.Start(Handler)
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/silasstoffel/invest-tracker/apps/shared/database"
+	http_helper "github.com/silasstoffel/invest-tracker/apps/shared/http_helpers"
+	"github.com/silasstoffel/invest-tracker/apps/shared/repository"
+	appConfig "github.com/silasstoffel/invest-tracker/config"
+)
+
+type ErrorOutput struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+var (
+	env                       *appConfig.Config
+	operationStatusRepository repository.OperationStatusRepository
+)
+
+func init() {
+	env = appConfig.NewConfigFromEnvVars()
+
+	db, err := database.NewFromConfig(env)
+	if err != nil {
+		m := fmt.Sprintf("Failure to connect to database: %v", err)
+		log.Println(m)
+		panic(m)
+	}
+	operationStatusRepository = repository.NewOperationStatusRepository(db)
+}
+
+func Handler(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	id := strings.TrimSpace(request.PathParameters["id"])
+
+	if len(id) != 26 {
+		return http_helper.JsonResponse(ErrorOutput{
+			Code:    "INVALID_REQUEST",
+			Message: "id must be 26 characters long",
+		}, http_helper.JsonResponseOptions{StatusCode: 400}), nil
+	}
+
+	operation, err := operationStatusRepository.Find(ctx, id)
+	if errors.Is(err, repository.ErrNotFound) {
+		return http_helper.JsonResponse(ErrorOutput{
+			Code:    "NOT_FOUND",
+			Message: fmt.Sprintf("no operation found with id %s", id),
+		}, http_helper.JsonResponseOptions{StatusCode: 404}), nil
+	}
+
+	if err != nil {
+		log.Printf("Failure to read operation %s: %v", id, err)
+		return http_helper.JsonResponse(ErrorOutput{
+			Code:    "INTERNAL_ERROR",
+			Message: "failure to read operation status",
+		}, http_helper.JsonResponseOptions{StatusCode: 500}), nil
+	}
+
+	return http_helper.JsonResponse(operation), nil
+}
+
+func main() {
+	lambda.Start(Handler)
+}
@@ -0,0 +1,149 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambda"
+	investment_core "github.com/silasstoffel/invest-tracker/apps/investments/core"
+	"github.com/silasstoffel/invest-tracker/apps/shared/database"
+	http_helper "github.com/silasstoffel/invest-tracker/apps/shared/http_helpers"
+	"github.com/silasstoffel/invest-tracker/apps/shared/repository"
+	appConfig "github.com/silasstoffel/invest-tracker/config"
+)
+
+const (
+	defaultLimit = 50
+	maxLimit     = 200
+)
+
+type ErrorOutput struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+type ListOutput struct {
+	Data []investment_core.InvestmentEntity `json:"data"`
+	// id to send as cursor to read the next page, empty on the last one
+	NextCursor string `json:"nextCursor,omitempty"`
+}
+
+var (
+	env                  *appConfig.Config
+	investmentRepository repository.InvestmentRepository
+)
+
+func init() {
+	env = appConfig.NewConfigFromEnvVars()
+
+	db, err := database.NewFromConfig(env)
+	if err != nil {
+		m := fmt.Sprintf("Failure to connect to database: %v", err)
+		log.Println(m)
+		panic(m)
+	}
+	investmentRepository = repository.NewInvestmentRepository(db)
+}
+
+func parseInt(params map[string]string, name string, min, max int) (int, error) {
+	value := strings.TrimSpace(params[name])
+	if value == "" {
+		return 0, nil
+	}
+
+	n, err := strconv.Atoi(value)
+	if err != nil || n < min || n > max {
+		return 0, fmt.Errorf("%s must be a number between %d and %d", name, min, max)
+	}
+
+	return n, nil
+}
+
+func parseDate(params map[string]string, name string) (string, error) {
+	value := strings.TrimSpace(params[name])
+	if value == "" {
+		return "", nil
+	}
+
+	if _, err := time.Parse("2006-01-02", value); err != nil {
+		return "", fmt.Errorf("%s must be in the format YYYY-MM-DD", name)
+	}
+
+	return value, nil
+}
+
+func parseRequest(params map[string]string) (repository.InvestmentFilter, string, int, error) {
+	filter := repository.InvestmentFilter{
+		Symbol:        strings.TrimSpace(params["symbol"]),
+		Type:          strings.ToLower(strings.TrimSpace(params["type"])),
+		Brokerage:     strings.TrimSpace(params["brokerage"]),
+		OperationType: strings.ToLower(strings.TrimSpace(params["operationType"])),
+		Note:          strings.TrimSpace(params["note"]),
+	}
+
+	var err error
+	if filter.Year, err = parseInt(params, "year", 1900, 9999); err != nil {
+		return filter, "", 0, err
+	}
+	if filter.Month, err = parseInt(params, "month", 1, 12); err != nil {
+		return filter, "", 0, err
+	}
+	if filter.From, err = parseDate(params, "from"); err != nil {
+		return filter, "", 0, err
+	}
+	if filter.To, err = parseDate(params, "to"); err != nil {
+		return filter, "", 0, err
+	}
+
+	limit, err := parseInt(params, "limit", 1, maxLimit)
+	if err != nil {
+		return filter, "", 0, err
+	}
+	if limit == 0 {
+		limit = defaultLimit
+	}
+
+	cursor := strings.TrimSpace(params["cursor"])
+	if cursor != "" && len(cursor) != 26 {
+		return filter, "", 0, fmt.Errorf("cursor must be 26 characters long")
+	}
+
+	return filter, cursor, limit, nil
+}
+
+func Handler(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	filter, cursor, limit, err := parseRequest(request.QueryStringParameters)
+	if err != nil {
+		return http_helper.JsonResponse(ErrorOutput{
+			Code:    "INVALID_REQUEST",
+			Message: err.Error(),
+		}, http_helper.JsonResponseOptions{StatusCode: 400}), nil
+	}
+
+	// one extra row tells whether there is a next page
+	investments, err := investmentRepository.List(ctx, filter, cursor, limit+1)
+	if err != nil {
+		log.Printf("Failure to list investments: %v", err)
+		return http_helper.JsonResponse(ErrorOutput{
+			Code:    "INTERNAL_ERROR",
+			Message: "failure to read investments",
+		}, http_helper.JsonResponseOptions{StatusCode: 500}), nil
+	}
+
+	output := ListOutput{Data: investments}
+	if len(investments) > limit {
+		output.Data = investments[:limit]
+		output.NextCursor = output.Data[limit-1].ID
+	}
+
+	return http_helper.JsonResponse(output), nil
+}
+
+func main() {
+	lambda.Start(Handler)
+}
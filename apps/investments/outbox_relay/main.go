@@ -0,0 +1,77 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	"github.com/silasstoffel/invest-tracker/apps/shared/database"
+	"github.com/silasstoffel/invest-tracker/apps/shared/outbox"
+	"github.com/silasstoffel/invest-tracker/apps/shared/repository"
+	"github.com/silasstoffel/invest-tracker/apps/shared/telegram"
+	appConfig "github.com/silasstoffel/invest-tracker/config"
+)
+
+const (
+	// messages younger than this are still being delivered by the producer
+	gracePeriod = time.Minute
+	batchSize   = 50
+	// alert on telegram once a message keeps failing
+	alertAttempts = 3
+)
+
+var (
+	env   *appConfig.Config
+	relay *outbox.Relay
+)
+
+func init() {
+	env = appConfig.NewConfigFromEnvVars()
+	cfg, err := config.LoadDefaultConfig(context.Background())
+	if err != nil {
+		m := fmt.Sprintf("Failure to load aws config: %v", err)
+		log.Println(m)
+		panic(m)
+	}
+
+	db, err := database.NewFromConfig(env)
+	if err != nil {
+		m := fmt.Sprintf("Failure to connect to database: %v", err)
+		log.Println(m)
+		panic(m)
+	}
+
+	relay = outbox.NewRelay(sqs.NewFromConfig(cfg), repository.NewOutboxRepository(db), map[string]string{
+		outbox.CalculateAveragePrice: env.CalculateAveragePriceQueueURL,
+	})
+}
+
+func Handler(ctx context.Context) error {
+	prefix := os.Getenv("AWS_LAMBDA_FUNCTION_NAME")
+	tb := telegram.NewTelegramBot(env)
+
+	result, err := relay.DeliverPending(ctx, gracePeriod, batchSize)
+	if err != nil {
+		log.Print(err)
+		tb.SendMessage(fmt.Sprintf("*[%s] Failure to relay outbox messages* ```%s```", prefix, err.Error()))
+		return err
+	}
+
+	for _, message := range result.Failed {
+		if message.Attempts >= alertAttempts {
+			tb.SendMessage(fmt.Sprintf("*[%s] Outbox message %s failed %d times.* Investment: %s ```sh %s```", prefix, message.ID, message.Attempts, message.AggregateID, message.LastError))
+		}
+	}
+
+	log.Printf("Outbox relay finished. Delivered: %d Failed: %d", result.Delivered, len(result.Failed))
+	return nil
+}
+
+func main() {
+	lambda.Start(Handler)
+}
@@ -2,15 +2,20 @@ package main
 
 import (
 	"context"
+	cryptoRand "crypto/rand"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"log"
 	"strings"
+	"time"
 
 	"github.com/aws/aws-lambda-go/events"
 	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/oklog/ulid/v2"
 	investment_core "github.com/silasstoffel/invest-tracker/apps/investments/core"
+	"github.com/silasstoffel/invest-tracker/apps/shared/database"
+	"github.com/silasstoffel/invest-tracker/apps/shared/repository"
 	appConfig "github.com/silasstoffel/invest-tracker/config"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
@@ -20,10 +25,14 @@ import (
 
 type Response events.APIGatewayProxyResponse
 
+const idempotencyKeyHeader = "Idempotency-Key"
+
 var (
-	sqsClient       *sqs.Client
-	queueURL        string
-	responseHeaders = map[string]string{
+	sqsClient                 *sqs.Client
+	queueURL                  string
+	investmentRepository      repository.InvestmentRepository
+	operationStatusRepository repository.OperationStatusRepository
+	responseHeaders           = map[string]string{
 		"Content-Type": "application/json",
 	}
 )
@@ -36,6 +45,34 @@ func init() {
 	sqsClient = sqs.NewFromConfig(cfg)
 	config := appConfig.NewConfigFromEnvVars()
 	queueURL = config.CreateInvestmentQueueURL
+
+	db, err := database.NewFromConfig(config)
+	if err != nil {
+		m := fmt.Sprintf("Failure to connect to database: %v", err)
+		log.Println(m)
+		panic(m)
+	}
+	investmentRepository = repository.NewInvestmentRepository(db)
+	operationStatusRepository = repository.NewOperationStatusRepository(db)
+}
+
+func createId() string {
+	entropy := ulid.Monotonic(cryptoRand.Reader, 0)
+	t := time.Now().UTC()
+
+	return ulid.MustNew(ulid.Timestamp(t), entropy).String()
+}
+
+// idempotencyKey returns the Idempotency-Key header. Requests without it are
+// never deduplicated, identical operations on the same day are legitimate.
+func idempotencyKey(request events.APIGatewayProxyRequest) string {
+	for name, value := range request.Headers {
+		if strings.EqualFold(name, idempotencyKeyHeader) && strings.TrimSpace(value) != "" {
+			return strings.TrimSpace(value)
+		}
+	}
+
+	return ""
 }
 
 func checkInvestmentType(t string) error {
@@ -60,7 +97,7 @@ func checkRedemptionPolicyType(t string) error {
 
 func checkOperationType(t string) error {
 	switch t {
-	case investment_core.BuyOperationType, investment_core.SellOperationType:
+	case investment_core.BuyOperationType, investment_core.SellOperationType, investment_core.CorporateActionOperationType, investment_core.IncomeOperationType:
 		return nil
 	case "":
 		return errors.New("operation type is required")
@@ -69,6 +106,66 @@ func checkOperationType(t string) error {
 	}
 }
 
+// validateCorporateAction checks the fields of a split, reverse split or bonus.
+// The quantity is not informed, it comes from the position the action applies to.
+func validateCorporateAction(input investment_core.CreateInvestmentInput) error {
+	switch input.Type {
+	case investment_core.FiiInvestmentType, investment_core.StockInvestmentType, investment_core.ReitInvestmentType, investment_core.EtfInvestmentType:
+	default:
+		return fmt.Errorf("corporate actions are not supported for %s investments", input.Type)
+	}
+
+	switch input.OperationSubtype {
+	case investment_core.SplitCorporateAction, investment_core.ReverseSplitCorporateAction, investment_core.BonusCorporateAction:
+	case "":
+		return errors.New("operation subtype is required for corporate actions")
+	default:
+		return errors.New("invalid corporate action, use split, reverse_split or bonus")
+	}
+
+	if !input.Ratio.IsPositive() {
+		return fmt.Errorf("corporate action ratio must be greater than zero")
+	}
+	if !input.Quantity.IsZero() {
+		return fmt.Errorf("quantity is not allowed for corporate actions, it is calculated from the position")
+	}
+	if input.TotalValue.IsNegative() {
+		return fmt.Errorf("cash in lieu (total value) cannot be negative")
+	}
+	if input.UnitPrice.IsNegative() {
+		return fmt.Errorf("bonus unit price cannot be negative")
+	}
+
+	return nil
+}
+
+// validateIncome checks a dividend, JCP, FII income, rendimento or amortization.
+// The total value is the gross amount, the quantity of shares is optional.
+func validateIncome(input investment_core.CreateInvestmentInput) error {
+	switch input.OperationSubtype {
+	case investment_core.DividendIncome, investment_core.JcpIncome, investment_core.FiiIncome, investment_core.RendimentoIncome, investment_core.AmortizationIncome:
+	case "":
+		return errors.New("operation subtype is required for incomes")
+	default:
+		return errors.New("invalid income, use dividend, jcp, fii_income, rendimento or amortization")
+	}
+
+	if input.OperationSubtype == investment_core.AmortizationIncome && input.Type == investment_core.BondInvestmentType {
+		return fmt.Errorf("bond amortizations must be scheduled as a sell of the purchase")
+	}
+	if !input.TotalValue.IsPositive() {
+		return fmt.Errorf("income total value must be greater than zero")
+	}
+	if input.Quantity.IsNegative() {
+		return fmt.Errorf("income quantity cannot be negative")
+	}
+	if input.TaxWithheld.IsNegative() || input.TaxWithheld.GreaterThan(input.TotalValue) {
+		return fmt.Errorf("tax withheld must be between zero and the total value")
+	}
+
+	return nil
+}
+
 func validateInput(input investment_core.CreateInvestmentInput) error {
 	investmentTypeError := checkInvestmentType(input.Type)
 	if investmentTypeError != nil {
@@ -77,19 +174,30 @@ func validateInput(input investment_core.CreateInvestmentInput) error {
 	if input.Symbol == "" {
 		return fmt.Errorf("investment symbol is required")
 	}
-	if input.Quantity <= 0 {
-		return fmt.Errorf("investment quantity must be greater than zero")
-	}
-	if input.TotalValue <= 0 {
-		return fmt.Errorf("investment total value must be greater than zero")
-	}
-	if input.Cost < 0 {
-		return fmt.Errorf("investment cost cannot be negative")
-	}
 	checkOperationTypeErr := checkOperationType(input.OperationType)
 	if checkOperationTypeErr != nil {
 		return checkOperationTypeErr
 	}
+	switch input.OperationType {
+	case investment_core.CorporateActionOperationType:
+		if err := validateCorporateAction(input); err != nil {
+			return err
+		}
+	case investment_core.IncomeOperationType:
+		if err := validateIncome(input); err != nil {
+			return err
+		}
+	default:
+		if !input.Quantity.IsPositive() {
+			return fmt.Errorf("investment quantity must be greater than zero")
+		}
+		if !input.TotalValue.IsPositive() {
+			return fmt.Errorf("investment total value must be greater than zero")
+		}
+	}
+	if input.Cost.IsNegative() {
+		return fmt.Errorf("investment cost cannot be negative")
+	}
 	if input.OperationDate == "" {
 		return fmt.Errorf("operation date is required")
 	} else {
@@ -112,9 +220,14 @@ func validateInput(input investment_core.CreateInvestmentInput) error {
 		if input.BondIndex == "" {
 			return fmt.Errorf("bond index is required for bond investments")
 		}
-		if (input.BondIndex == investment_core.BondIndexIPCA || input.BondIndex == investment_core.BondIndexPrefix) && input.BondRate < 0 {
+		if (input.BondIndex == investment_core.BondIndexIPCA || input.BondIndex == investment_core.BondIndexPrefix) && input.BondRate.IsNegative() {
 			return fmt.Errorf("bond rate must be greater than zero")
 		}
+		if input.ProductKind != "" && !investment_core.IsProductKind(input.ProductKind) {
+			return fmt.Errorf("invalid product kind, use cdb, lci, lca, cri, cra, debenture or tesouro")
+		}
+	} else if input.ProductKind != "" || strings.TrimSpace(input.Issuer) != "" {
+		return fmt.Errorf("issuer and product kind are only allowed for bond investments")
 	}
 
 	if input.RedemptionPolicyType != "" {
@@ -123,6 +236,20 @@ func validateInput(input investment_core.CreateInvestmentInput) error {
 		}
 	}
 
+	if input.GracePeriodDate != "" {
+		if input.RedemptionPolicyType != investment_core.HybridRedemption {
+			return fmt.Errorf("grace period date is only allowed for hybrid redemption policy")
+		}
+		if _, err := time.Parse("2006-01-02", input.GracePeriodDate); err != nil {
+			return fmt.Errorf("grace period date must be in the format YYYY-MM-DD")
+		}
+		if input.DueDate != "" && input.GracePeriodDate > input.DueDate {
+			return fmt.Errorf("grace period date cannot be after the due date")
+		}
+	} else if input.RedemptionPolicyType == investment_core.HybridRedemption && input.OperationType == investment_core.BuyOperationType {
+		return fmt.Errorf("grace period date is required for hybrid redemption policy")
+	}
+
 	if input.OperationType == investment_core.SellOperationType && input.Type == investment_core.BondInvestmentType {
 		sellInvestmentId := strings.Trim(input.SellInvestmentId, "")
 
@@ -158,6 +285,7 @@ func Handler(ctx context.Context, request events.APIGatewayProxyRequest) (Respon
 		}, nil
 	}
 
+	input.Symbol = investment_core.NormalizeSymbol(input.Type, input.Symbol)
 	validateInputErr := validateInput(input)
 	if validateInputErr != nil {
 		message := validateInputErr.Error()
@@ -173,6 +301,43 @@ func Handler(ctx context.Context, request events.APIGatewayProxyRequest) (Respon
 		}, nil
 	}
 
+	input.IdempotencyKey = idempotencyKey(request)
+
+	var operation investment_core.OperationStatusEntity
+	if input.IdempotencyKey != "" {
+		// a repeated request answers with the operation created the first time
+		existing, err := investmentRepository.FindByIdempotencyKey(ctx, input.IdempotencyKey)
+		if err == nil {
+			log.Printf("Investment %s was already created for idempotency key %s", existing.ID, input.IdempotencyKey)
+			respBody, _ := json.Marshal(existing)
+
+			return Response{
+				StatusCode: 200,
+				Headers:    responseHeaders,
+				Body:       string(respBody),
+			}, nil
+		}
+		if !errors.Is(err, repository.ErrNotFound) {
+			log.Printf("Failure to look up idempotency key %s: %v", input.IdempotencyKey, err)
+		}
+
+		// still in the queue, a failed one is sent again with the same id
+		operation, err = operationStatusRepository.FindByIdempotencyKey(ctx, input.IdempotencyKey)
+		if err == nil && operation.Status != investment_core.FailedOperationStatus {
+			log.Printf("Operation %s was already scheduled for idempotency key %s", operation.ID, input.IdempotencyKey)
+			return scheduledResponse(operation.ID, input.IdempotencyKey), nil
+		}
+	}
+
+	input.ID = operation.ID
+	if input.ID == "" {
+		input.ID = createId()
+	}
+
+	if err := operationStatusRepository.Queue(ctx, input.ID, input.IdempotencyKey); err != nil {
+		log.Print(err)
+	}
+
 	messageContent, err := json.Marshal(input)
 	if err != nil {
 		message := "Failure to convert message input to JSON"
@@ -197,6 +362,9 @@ func Handler(ctx context.Context, request events.APIGatewayProxyRequest) (Respon
 
 	if err != nil {
 		const message = "Failed to send message to SQS"
+		if statusErr := operationStatusRepository.UpdateStatus(ctx, input.ID, investment_core.FailedOperationStatus, fmt.Sprintf("%s: %v", message, err)); statusErr != nil {
+			log.Print(statusErr)
+		}
 		respBody, _ := json.Marshal(map[string]string{
 			"message": message,
 			"code":    "INTEGRATION_ERROR",
@@ -208,8 +376,15 @@ func Handler(ctx context.Context, request events.APIGatewayProxyRequest) (Respon
 		}, nil
 	}
 
+	return scheduledResponse(input.ID, input.IdempotencyKey), nil
+}
+
+// scheduledResponse carries the operation id, used to follow it on /investments/operations/{id}.
+func scheduledResponse(id string, idempotencyKey string) Response {
 	response, _ := json.Marshal(map[string]string{
-		"message": "Investment will be created as a soon as possible!",
+		"message":        "Investment will be created as a soon as possible!",
+		"id":             id,
+		"idempotencyKey": idempotencyKey,
 	})
 
 	return Response{
@@ -217,7 +392,7 @@ func Handler(ctx context.Context, request events.APIGatewayProxyRequest) (Respon
 		IsBase64Encoded: false,
 		Body:            string(response),
 		Headers:         responseHeaders,
-	}, nil
+	}
 }
 
 func main() {
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/aws/aws-lambda-go/lambda"
+	investment_summary_service "github.com/silasstoffel/invest-tracker/apps/investments_summary/service"
+	"github.com/silasstoffel/invest-tracker/apps/shared/database"
+	"github.com/silasstoffel/invest-tracker/apps/shared/repository"
+	appConfig "github.com/silasstoffel/invest-tracker/config"
+)
+
+/* Example of event, ratio (new shares per old share) and date are optional:
+{
+  "oldSymbol": "BIDI11",
+  "newSymbol": "INBR32",
+  "ratio": "0.333333",
+  "date": "2022-06-23"
+}
+*/
+
+type ChangeSymbolOutput struct {
+	Changes []investment_summary_service.PositionChange `json:"changes"`
+}
+
+var (
+	env                 *appConfig.Config
+	symbolChangeService *investment_summary_service.SymbolChangeService
+)
+
+func init() {
+	env = appConfig.NewConfigFromEnvVars()
+
+	db, err := database.NewFromConfig(env)
+	if err != nil {
+		m := fmt.Sprintf("Failure to connect to database: %v", err)
+		log.Println(m)
+		panic(m)
+	}
+
+	symbolChangeService = investment_summary_service.NewSymbolChangeService(
+		repository.NewInvestmentRepository(db),
+		repository.NewSummaryRepository(db),
+		repository.NewSymbolAliasRepository(db),
+	)
+}
+
+func Handler(ctx context.Context, event investment_summary_service.SymbolChange) (ChangeSymbolOutput, error) {
+	log.Printf("Changing symbol. Event: %+v", event)
+
+	changes, err := symbolChangeService.ChangeSymbol(ctx, event)
+	for _, change := range changes {
+		log.Println(change.String())
+	}
+
+	if err != nil {
+		log.Printf("Failure to change symbol %s to %s: %v", event.OldSymbol, event.NewSymbol, err)
+		return ChangeSymbolOutput{}, err
+	}
+
+	return ChangeSymbolOutput{Changes: changes}, nil
+}
+
+func main() {
+	lambda.Start(Handler)
+}
@@ -0,0 +1,94 @@
+package investment_summary_core
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/silasstoffel/invest-tracker/apps/shared/decimal"
+)
+
+const (
+	// history granularities
+	OperationGranularity = "operation"
+	DailyGranularity     = "daily"
+	MonthlyGranularity   = "monthly"
+
+	// operation type of the history rows written by the valuation of a
+	// position, they are kept when the history of operations is replaced
+	ValuationSnapshot = "valuation"
+)
+
+type HistoryPoint struct {
+	// YYYY-MM-DD, or YYYY-MM for the monthly granularity
+	Date         string          `json:"date"`
+	Quantity     decimal.Decimal `json:"quantity"`
+	AveragePrice decimal.Decimal `json:"averagePrice"`
+	TotalValue   decimal.Decimal `json:"totalValue"`
+	MarketValue  decimal.Decimal `json:"marketValue"`
+	// the operation behind the point, only for the operation granularity
+	OperationType string `json:"operationType,omitempty"`
+}
+
+func CheckGranularity(granularity string) error {
+	switch granularity {
+	case OperationGranularity, DailyGranularity, MonthlyGranularity:
+		return nil
+	default:
+		return fmt.Errorf("invalid granularity %s", granularity)
+	}
+}
+
+func toPoint(date string, snapshot InvestmentSummaryEntity) HistoryPoint {
+	return HistoryPoint{
+		Date:         date,
+		Quantity:     snapshot.Quantity,
+		AveragePrice: snapshot.AveragePrice,
+		TotalValue:   snapshot.TotalValue,
+		MarketValue:  snapshot.MarketValue,
+	}
+}
+
+// History turns the snapshots of a position (chronological, one per operation)
+// into a time series ending at until. Daily and monthly points repeat the last
+// snapshot known at the end of the period, so there are no gaps between operations.
+func History(snapshots []InvestmentSummaryEntity, granularity string, until time.Time) []HistoryPoint {
+	points := []HistoryPoint{}
+	if len(snapshots) == 0 {
+		return points
+	}
+
+	if granularity == OperationGranularity {
+		for _, snapshot := range snapshots {
+			point := toPoint(snapshot.LastTransactionDate.Format("2006-01-02"), snapshot)
+			point.OperationType = snapshot.LastTransactionType
+			points = append(points, point)
+		}
+		return points
+	}
+
+	until = truncateDay(until)
+	period := truncateDay(snapshots[0].LastTransactionDate)
+	layout := "2006-01-02"
+	next := func(t time.Time) time.Time { return t.AddDate(0, 0, 1) }
+
+	if granularity == MonthlyGranularity {
+		period = time.Date(period.Year(), period.Month(), 1, 0, 0, 0, 0, time.UTC)
+		layout = "2006-01"
+		next = func(t time.Time) time.Time { return t.AddDate(0, 1, 0) }
+	}
+
+	current := -1
+	for ; !period.After(until); period = next(period) {
+		end := next(period)
+		for current+1 < len(snapshots) && truncateDay(snapshots[current+1].LastTransactionDate).Before(end) {
+			current++
+		}
+		points = append(points, toPoint(period.Format(layout), snapshots[current]))
+	}
+
+	return points
+}
+
+func truncateDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
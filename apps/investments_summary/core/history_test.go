@@ -0,0 +1,64 @@
+package investment_summary_core
+
+import (
+	"testing"
+	"time"
+
+	"github.com/silasstoffel/invest-tracker/apps/shared/decimal"
+)
+
+func snapshot(date string, quantity int64, averagePrice int64) InvestmentSummaryEntity {
+	d, _ := time.Parse("2006-01-02", date)
+	return InvestmentSummaryEntity{
+		LastTransactionDate: d,
+		Quantity:            decimal.NewFromInt(quantity),
+		AveragePrice:        decimal.NewFromInt(averagePrice),
+		TotalValue:          decimal.NewFromInt(quantity * averagePrice),
+	}
+}
+
+func TestHistory(t *testing.T) {
+	snapshots := []InvestmentSummaryEntity{
+		snapshot("2025-01-30", 10, 10),
+		snapshot("2025-02-01", 20, 12),
+		snapshot("2025-02-01", 15, 12),
+		snapshot("2025-04-10", 5, 12),
+	}
+	until, _ := time.Parse("2006-01-02", "2025-04-12")
+
+	operations := History(snapshots, OperationGranularity, until)
+	if len(operations) != 4 {
+		t.Fatalf("expected one point per operation, got %d", len(operations))
+	}
+
+	daily := History(snapshots, DailyGranularity, until)
+	// 2025-01-30 .. 2025-04-12
+	if len(daily) != 73 {
+		t.Fatalf("expected 73 daily points, got %d", len(daily))
+	}
+	if daily[1].Date != "2025-01-31" || !daily[1].Quantity.Equal(decimal.NewFromInt(10)) {
+		t.Errorf("expected the gap to repeat the previous snapshot, got %+v", daily[1])
+	}
+	if daily[2].Date != "2025-02-01" || !daily[2].Quantity.Equal(decimal.NewFromInt(15)) {
+		t.Errorf("expected the last operation of the day, got %+v", daily[2])
+	}
+
+	monthly := History(snapshots, MonthlyGranularity, until)
+	expected := []struct {
+		date     string
+		quantity int64
+	}{
+		{"2025-01", 10},
+		{"2025-02", 15},
+		{"2025-03", 15},
+		{"2025-04", 5},
+	}
+	if len(monthly) != len(expected) {
+		t.Fatalf("expected %d monthly points, got %d", len(expected), len(monthly))
+	}
+	for i, e := range expected {
+		if monthly[i].Date != e.date || !monthly[i].Quantity.Equal(decimal.NewFromInt(e.quantity)) {
+			t.Errorf("expected %s with quantity %d, got %+v", e.date, e.quantity, monthly[i])
+		}
+	}
+}
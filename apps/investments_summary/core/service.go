@@ -1,45 +1,350 @@
 package investment_summary_core
 
-import "log"
+import (
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+
+	"github.com/silasstoffel/invest-tracker/apps/shared/decimal"
+)
+
+// ErrOversell is returned when a sell is larger than the position it is
+// applied to, which is usually a typo in the quantity.
+var ErrOversell = errors.New("sell quantity is larger than the position")
+
+var pnlInvestmentTypes = map[string]int{
+	"fii":   1,
+	"stock": 1,
+	"reit":  1,
+	"etf":   1,
+}
+
+// positions of these types are whole shares, B3 sells the fractions left by
+// corporate actions and pays them in cash
+var wholeShareInvestmentTypes = map[string]int{
+	"fii":   1,
+	"stock": 1,
+	"etf":   1,
+}
+
+// averagePrice is the price paid per unit of a position, zero when it holds
+// nothing.
+func averagePrice(totalValue, quantity decimal.Decimal) decimal.Decimal {
+	if !quantity.IsPositive() {
+		return decimal.Zero
+	}
+	return totalValue.Div(quantity).Price()
+}
 
 func CalculateAverageCost(createdInvestment InvestmentCreatedInput, investments []InvestmentCreatedInput) CalculateAverageCostOutput {
 	if len(investments) == 0 {
 		log.Print("It is the first operation to summarize")
 		return CalculateAverageCostOutput{
-			Quantity:     createdInvestment.Quantity,
-			TotalValue:   createdInvestment.TotalValue,
-			AveragePrice: createdInvestment.TotalValue / float64(createdInvestment.Quantity),
+			Quantity:     createdInvestment.Quantity.Quantity(),
+			TotalValue:   createdInvestment.TotalValue.Money(),
+			AveragePrice: averagePrice(createdInvestment.TotalValue, createdInvestment.Quantity),
 		}
 	}
 	return handleAverageCost(investments)
 }
 
 func handleAverageCost(investments []InvestmentCreatedInput) CalculateAverageCostOutput {
-	var quantity float64 = 0
-	var totalValue float64 = 0
-	var averagePrice float64 = 0
+	position := Position{}
 
 	for _, investment := range investments {
-		if investment.OperationType == "buy" {
-			quantity += investment.Quantity
-			totalValue += investment.TotalValue
-			averagePrice = totalValue / float64(quantity)
-		} else {
-			costToRemove := float64(investment.Quantity) * averagePrice
-			totalValue -= costToRemove
-			quantity -= investment.Quantity
-
-			if quantity > 0 {
-				averagePrice = totalValue / float64(quantity)
-			} else {
-				averagePrice = 0
-			}
+		applied, _, err := ApplyOperation(position, investment)
+		if err != nil {
+			log.Print(err)
+			continue
 		}
+		position = applied
 	}
 
 	return CalculateAverageCostOutput{
-		Quantity:     quantity,
-		TotalValue:   totalValue,
-		AveragePrice: averagePrice,
+		Quantity:     position.Quantity,
+		TotalValue:   position.TotalValue,
+		AveragePrice: position.AveragePrice,
+	}
+}
+
+// ChangesPosition tells whether the operation changes the quantity or the cost
+// of a position. Incomes are only recorded, except amortizations, which return
+// part of the invested capital.
+func ChangesPosition(operation InvestmentCreatedInput) bool {
+	return operation.OperationType != "income" || operation.OperationSubtype == "amortization"
+}
+
+// ApplyOperation applies a buy, sell, corporate action or amortization on top
+// of a position. The sale result is only returned for sells of investment types
+// that track profit and loss, and for the fractions sold after a corporate action.
+// Selling more than the position holds is refused with ErrOversell.
+func ApplyOperation(position Position, operation InvestmentCreatedInput) (Position, *SaleResult, error) {
+	switch operation.OperationType {
+	case "corporate_action":
+		position, sale := applyCorporateAction(position, operation)
+		return position, sale, nil
+	case "income":
+		if operation.OperationSubtype == "amortization" {
+			// the capital returned reduces the cost of the shares held
+			position.TotalValue = decimal.Max(position.TotalValue.Sub(operation.TotalValue), decimal.Zero).Money()
+			position.AveragePrice = averagePrice(position.TotalValue, position.Quantity)
+		}
+		return position, nil, nil
+	}
+
+	if operation.OperationType != "sell" {
+		position.Quantity = position.Quantity.Add(operation.Quantity).Quantity()
+		position.TotalValue = position.TotalValue.Add(operation.TotalValue).Money()
+		position.AveragePrice = averagePrice(position.TotalValue, position.Quantity)
+		position.Cost = position.Cost.Add(operation.Cost).Money()
+		return position, nil, nil
+	}
+
+	if operation.Quantity.GreaterThan(position.Quantity) {
+		return position, nil, fmt.Errorf("%w: operation %s sells %s, the position holds %s", ErrOversell, operation.ID, operation.Quantity, position.Quantity)
+	}
+
+	var sale *SaleResult
+	if _, ok := pnlInvestmentTypes[operation.Type]; ok {
+		// PNL = Profit and Loss
+		sale = &SaleResult{
+			InvestmentID:        operation.ID,
+			Pnl:                 operation.TotalValue.Sub(position.AveragePrice.Mul(operation.Quantity)).Money(),
+			AverageSellingPrice: position.AveragePrice,
+		}
 	}
+
+	if operation.Type == "bond" {
+		position, sale := applyBondRedemption(position, operation)
+		return position, sale, nil
+	}
+
+	if !position.Quantity.GreaterThan(operation.Quantity) {
+		// a full sell closes the position without residues
+		return Position{}, sale, nil
+	}
+
+	// average price does not change when the operation type is sell
+	position.Quantity = position.Quantity.Sub(operation.Quantity).Quantity()
+	// total value is reduced by the average price times the quantity sold
+	position.TotalValue = position.TotalValue.Sub(position.AveragePrice.Mul(operation.Quantity)).Money()
+	position.Cost = position.Cost.Sub(operation.Cost).Money()
+
+	return position, sale, nil
+}
+
+// applyBondRedemption redeems part or all of a bond purchase. The gain is
+// measured against the principal of the quantity redeemed, the taxes withheld
+// depend on the purchase and are filled in by the caller.
+func applyBondRedemption(position Position, operation InvestmentCreatedInput) (Position, *SaleResult) {
+	quantity := operation.Quantity
+	costBasis := position.TotalValue
+	if position.Quantity.GreaterThan(quantity) {
+		costBasis = position.AveragePrice.Mul(quantity).Money()
+	}
+
+	sale := &SaleResult{
+		InvestmentID:        operation.ID,
+		Pnl:                 operation.TotalValue.Sub(costBasis).Money(),
+		AverageSellingPrice: position.AveragePrice,
+		BondRedemption:      true,
+		NetProceeds:         operation.TotalValue.Money(),
+	}
+
+	if !position.Quantity.GreaterThan(quantity) {
+		return Position{}, sale
+	}
+
+	// the cost leaves the position in the share redeemed, as the total value
+	position.Cost = position.Cost.Sub(position.Cost.Mul(quantity).Div(position.Quantity)).Money()
+	position.Quantity = position.Quantity.Sub(quantity).Quantity()
+	position.TotalValue = position.TotalValue.Sub(costBasis).Money()
+	return position, sale
+}
+
+// applyCorporateAction scales the position by the ratio of a split, reverse
+// split or bonus. Bonus shares are added at the declared unit price, so the
+// average price is diluted by them. A split keeps the total value and only
+// changes the average price.
+func applyCorporateAction(position Position, operation InvestmentCreatedInput) (Position, *SaleResult) {
+	quantity, totalValue := position.Quantity, position.TotalValue
+
+	switch operation.OperationSubtype {
+	case "split":
+		quantity = quantity.Mul(operation.Ratio)
+	case "reverse_split":
+		if !operation.Ratio.IsPositive() {
+			log.Printf("Reverse split %s without a ratio, the position is not changed", operation.ID)
+			return position, nil
+		}
+		quantity = quantity.Div(operation.Ratio)
+	case "bonus":
+		bonus := quantity.Mul(operation.Ratio)
+		quantity = quantity.Add(bonus)
+		totalValue = totalValue.Add(bonus.Mul(operation.UnitPrice))
+	default:
+		log.Printf("Unknown corporate action %s in operation %s, the position is not changed", operation.OperationSubtype, operation.ID)
+		return position, nil
+	}
+
+	position.Quantity = quantity.Quantity()
+	position.TotalValue = totalValue.Money()
+	position.AveragePrice = averagePrice(position.TotalValue, position.Quantity)
+	position.Cost = position.Cost.Add(operation.Cost).Money()
+
+	if _, ok := wholeShareInvestmentTypes[operation.Type]; !ok {
+		return position, nil
+	}
+
+	// the fraction is sold at the average price, the cash in lieu is its proceeds
+	fraction := position.Quantity.Sub(position.Quantity.Truncate(0))
+	if !fraction.IsPositive() && !operation.TotalValue.IsPositive() {
+		return position, nil
+	}
+
+	soldValue := position.AveragePrice.Mul(fraction)
+	sale := &SaleResult{
+		InvestmentID:        operation.ID,
+		Pnl:                 operation.TotalValue.Sub(soldValue).Money(),
+		AverageSellingPrice: position.AveragePrice,
+	}
+
+	position.Quantity = position.Quantity.Truncate(0)
+	position.TotalValue = position.TotalValue.Sub(soldValue).Money()
+	if !position.Quantity.IsPositive() {
+		return Position{}, sale
+	}
+
+	return position, sale
+}
+
+// SortOperations orders operations chronologically, operations of the same day
+// keep the creation order given by their ULID.
+func SortOperations(operations []InvestmentCreatedInput) {
+	sort.SliceStable(operations, func(i, j int) bool {
+		if operations[i].OperationDate != operations[j].OperationDate {
+			return operations[i].OperationDate < operations[j].OperationDate
+		}
+		return operations[i].ID < operations[j].ID
+	})
+}
+
+// dayTrades matches the buys and sells of a single day. It returns the quantity
+// of each operation that is part of a day trade and the average price of the
+// day purchases matched, which is the cost basis of the day trades.
+func dayTrades(day []InvestmentCreatedInput) (map[string]decimal.Decimal, decimal.Decimal) {
+	bought, sold := decimal.Zero, decimal.Zero
+
+	for _, operation := range day {
+		if _, ok := pnlInvestmentTypes[operation.Type]; !ok {
+			continue
+		}
+		switch operation.OperationType {
+		case "sell":
+			sold = sold.Add(operation.Quantity)
+		case "buy":
+			bought = bought.Add(operation.Quantity)
+		}
+	}
+
+	quantities := map[string]decimal.Decimal{}
+	if !bought.IsPositive() || !sold.IsPositive() {
+		return quantities, decimal.Zero
+	}
+
+	// operations are matched in order until the smaller side is exhausted, the
+	// cost comes from the part of each buy that was matched
+	matchedQuantity := decimal.Min(bought, sold)
+	remainingBuy, remainingSell := matchedQuantity, matchedQuantity
+	matchedValue := decimal.Zero
+	for _, operation := range day {
+		if _, ok := pnlInvestmentTypes[operation.Type]; !ok || (operation.OperationType != "buy" && operation.OperationType != "sell") {
+			continue
+		}
+		remaining := &remainingBuy
+		if operation.OperationType == "sell" {
+			remaining = &remainingSell
+		}
+		matched := decimal.Min(*remaining, operation.Quantity)
+		if !matched.IsPositive() {
+			continue
+		}
+		quantities[operation.ID] = matched
+		*remaining = remaining.Sub(matched)
+		if operation.OperationType == "buy" {
+			matchedValue = matchedValue.Add(operation.TotalValue.Mul(matched).Div(operation.Quantity))
+		}
+	}
+
+	return quantities, matchedValue.Div(matchedQuantity).Price()
+}
+
+// withoutDayTrade returns the part of the operation left after the day trade,
+// with values proportional to the remaining quantity.
+func withoutDayTrade(operation InvestmentCreatedInput, dayTradeQuantity decimal.Decimal) InvestmentCreatedInput {
+	remaining := operation.Quantity.Sub(dayTradeQuantity)
+	operation.TotalValue = operation.TotalValue.Mul(remaining).Div(operation.Quantity).Money()
+	operation.Cost = operation.Cost.Mul(remaining).Div(operation.Quantity).Money()
+	operation.Quantity = remaining
+	return operation
+}
+
+// Replay rebuilds a position from all of its operations in chronological order.
+// Buys and sells of the same day are booked as day trades first, so they do
+// not change the average price of the position. Operations that do not change
+// the position are skipped, a sell larger than the position stops the replay.
+func Replay(operations []InvestmentCreatedInput) (ReplayOutput, error) {
+	sorted := make([]InvestmentCreatedInput, 0, len(operations))
+	for _, operation := range operations {
+		if ChangesPosition(operation) {
+			sorted = append(sorted, operation)
+		}
+	}
+	SortOperations(sorted)
+
+	output := ReplayOutput{}
+	for start := 0; start < len(sorted); {
+		end := start
+		for end < len(sorted) && sorted[end].OperationDate == sorted[start].OperationDate {
+			end++
+		}
+
+		day := sorted[start:end]
+		quantities, dayTradePrice := dayTrades(day)
+
+		for _, operation := range day {
+			var sale *SaleResult
+			dayTradeQuantity, isDayTrade := quantities[operation.ID]
+
+			remaining := operation
+			if isDayTrade {
+				remaining = withoutDayTrade(operation, dayTradeQuantity)
+			}
+			if !isDayTrade || remaining.Quantity.IsPositive() {
+				var err error
+				if output.Position, sale, err = ApplyOperation(output.Position, remaining); err != nil {
+					return output, err
+				}
+			}
+
+			if isDayTrade && operation.OperationType == "sell" {
+				if sale == nil {
+					sale = &SaleResult{InvestmentID: operation.ID, Pnl: decimal.Zero, AverageSellingPrice: dayTradePrice}
+				}
+				sellPrice := operation.TotalValue.Div(operation.Quantity)
+				sale.DayTradeQuantity = dayTradeQuantity
+				sale.DayTradePnl = sellPrice.Sub(dayTradePrice).Mul(dayTradeQuantity).Money()
+			}
+
+			output.Snapshots = append(output.Snapshots, PositionSnapshot{Operation: operation, Position: output.Position})
+			if sale != nil {
+				output.Sales = append(output.Sales, *sale)
+			}
+		}
+
+		start = end
+	}
+
+	return output, nil
 }
@@ -1,46 +1,222 @@
 package investment_summary_core
 
 import (
+	"errors"
 	"testing"
+
+	"github.com/silasstoffel/invest-tracker/apps/shared/decimal"
 )
 
 func TestCalculateAverageCost(t *testing.T) {
 	investments := []InvestmentCreatedInput{
 		{
 			OperationType: "buy",
-			Quantity:      10,
-			TotalValue:    100.0,
+			Quantity:      decimal.NewFromInt(10),
+			TotalValue:    decimal.RequireFromString("100.0"),
 		},
 		{
 			OperationType: "buy",
-			Quantity:      15,
-			TotalValue:    160.0,
+			Quantity:      decimal.NewFromInt(15),
+			TotalValue:    decimal.RequireFromString("160.0"),
 		},
 		{
 			OperationType: "sell",
-			Quantity:      5,
-			TotalValue:    55.0,
+			Quantity:      decimal.NewFromInt(5),
+			TotalValue:    decimal.RequireFromString("55.0"),
 		},
 	}
 
 	created := InvestmentCreatedInput{
-		Quantity:   5,
-		TotalValue: 55.0,
+		Quantity:   decimal.NewFromInt(5),
+		TotalValue: decimal.RequireFromString("55.0"),
 	}
 
 	result := CalculateAverageCost(created, investments)
 
-	expectedQuantity := 20
-	expectedTotalValue := 208.0
-	expectedAveragePrice := 10.4
+	expectedQuantity := decimal.NewFromInt(20)
+	expectedTotalValue := decimal.RequireFromString("208")
+	expectedAveragePrice := decimal.RequireFromString("10.4")
+
+	if !result.Quantity.Equal(expectedQuantity) {
+		t.Errorf("expected quantity %s, got %s", expectedQuantity, result.Quantity)
+	}
+	if !result.TotalValue.Equal(expectedTotalValue) {
+		t.Errorf("expected total value %s, got %s", expectedTotalValue, result.TotalValue)
+	}
+	if !result.AveragePrice.Equal(expectedAveragePrice) {
+		t.Errorf("expected average price %s, got %s", expectedAveragePrice, result.AveragePrice)
+	}
+}
+
+func TestCalculateAverageCostFullSell(t *testing.T) {
+	investments := []InvestmentCreatedInput{
+		{OperationType: "buy", Quantity: decimal.NewFromInt(3), TotalValue: decimal.RequireFromString("100")},
+		{OperationType: "buy", Quantity: decimal.RequireFromString("0.1"), TotalValue: decimal.RequireFromString("3.33")},
+		{OperationType: "sell", Quantity: decimal.RequireFromString("3.1"), TotalValue: decimal.RequireFromString("110")},
+	}
+
+	result := CalculateAverageCost(InvestmentCreatedInput{}, investments)
+
+	if !result.Quantity.IsZero() || !result.TotalValue.IsZero() || !result.AveragePrice.IsZero() {
+		t.Errorf("expected an empty position, got quantity %s, total value %s and average price %s", result.Quantity, result.TotalValue, result.AveragePrice)
+	}
+}
+
+func TestReplayDayTrade(t *testing.T) {
+	operations := []InvestmentCreatedInput{
+		{ID: "01", Type: "stock", OperationType: "buy", OperationDate: "2025-03-10", Quantity: decimal.NewFromInt(10), TotalValue: decimal.NewFromInt(100)},
+		{ID: "02", Type: "stock", OperationType: "buy", OperationDate: "2025-03-11", Quantity: decimal.NewFromInt(5), TotalValue: decimal.NewFromInt(60)},
+		{ID: "03", Type: "stock", OperationType: "sell", OperationDate: "2025-03-11", Quantity: decimal.NewFromInt(8), TotalValue: decimal.NewFromInt(104)},
+	}
+
+	output, err := Replay(operations)
+	if err != nil {
+		t.Fatalf("failure to replay: %v", err)
+	}
+
+	if !output.Position.Quantity.Equal(decimal.NewFromInt(7)) || !output.Position.AveragePrice.Equal(decimal.NewFromInt(10)) {
+		t.Errorf("expected 7 units at the long term average price of 10, got %s at %s", output.Position.Quantity, output.Position.AveragePrice)
+	}
+
+	if len(output.Sales) != 1 {
+		t.Fatalf("expected one sale, got %d", len(output.Sales))
+	}
+
+	sale := output.Sales[0]
+	if !sale.DayTradeQuantity.Equal(decimal.NewFromInt(5)) || !sale.DayTradePnl.Equal(decimal.NewFromInt(5)) {
+		t.Errorf("expected a day trade of 5 units with result 5, got %s units with result %s", sale.DayTradeQuantity, sale.DayTradePnl)
+	}
+	if !sale.Pnl.Equal(decimal.NewFromInt(9)) {
+		t.Errorf("expected swing trade result of 9 for the remaining 3 units, got %s", sale.Pnl)
+	}
+}
+
+func TestReplayDayTradeMatchedCost(t *testing.T) {
+	// only the first buy of the day is matched with the sell
+	operations := []InvestmentCreatedInput{
+		{ID: "01", Type: "stock", OperationType: "buy", OperationDate: "2025-03-10", Quantity: decimal.NewFromInt(10), TotalValue: decimal.NewFromInt(100)},
+		{ID: "02", Type: "stock", OperationType: "buy", OperationDate: "2025-03-10", Quantity: decimal.NewFromInt(10), TotalValue: decimal.NewFromInt(200)},
+		{ID: "03", Type: "stock", OperationType: "sell", OperationDate: "2025-03-10", Quantity: decimal.NewFromInt(10), TotalValue: decimal.NewFromInt(150)},
+	}
+
+	output, err := Replay(operations)
+	if err != nil {
+		t.Fatalf("failure to replay: %v", err)
+	}
+
+	if len(output.Sales) != 1 || !output.Sales[0].DayTradePnl.Equal(decimal.NewFromInt(50)) || !output.Sales[0].AverageSellingPrice.Equal(decimal.NewFromInt(10)) {
+		t.Errorf("expected a day trade result of 50 over a cost of 10, got %+v", output.Sales)
+	}
+	if !output.Position.Quantity.Equal(decimal.NewFromInt(10)) || !output.Position.AveragePrice.Equal(decimal.NewFromInt(20)) {
+		t.Errorf("expected the second buy left in the position, got %s at %s", output.Position.Quantity, output.Position.AveragePrice)
+	}
+}
+
+func TestApplyOperationOversell(t *testing.T) {
+	position := Position{Quantity: decimal.NewFromInt(10), AveragePrice: decimal.NewFromInt(10), TotalValue: decimal.NewFromInt(100)}
+	operation := InvestmentCreatedInput{ID: "02", Type: "stock", OperationType: "sell", Quantity: decimal.NewFromInt(100), TotalValue: decimal.NewFromInt(1200)}
+
+	result, sale, err := ApplyOperation(position, operation)
+	if !errors.Is(err, ErrOversell) {
+		t.Fatalf("expected an oversell error, got %v", err)
+	}
+	if sale != nil || !result.Quantity.Equal(decimal.NewFromInt(10)) || !result.TotalValue.Equal(decimal.NewFromInt(100)) {
+		t.Errorf("expected the position kept and no sale, got %+v and %+v", result, sale)
+	}
+}
+
+func TestApplyCorporateAction(t *testing.T) {
+	position := Position{
+		Quantity:     decimal.NewFromInt(15),
+		AveragePrice: decimal.NewFromInt(10),
+		TotalValue:   decimal.NewFromInt(150),
+		Cost:         decimal.Zero,
+	}
+
+	tests := []struct {
+		name         string
+		operation    InvestmentCreatedInput
+		quantity     string
+		averagePrice string
+		totalValue   string
+		pnl          string
+	}{
+		{
+			name:         "split keeps the total value",
+			operation:    InvestmentCreatedInput{OperationSubtype: "split", Ratio: decimal.NewFromInt(2)},
+			quantity:     "30",
+			averagePrice: "5",
+			totalValue:   "150",
+		},
+		{
+			name:         "reverse split sells the fraction left",
+			operation:    InvestmentCreatedInput{OperationSubtype: "reverse_split", Ratio: decimal.NewFromInt(10), TotalValue: decimal.NewFromInt(60)},
+			quantity:     "1",
+			averagePrice: "100",
+			totalValue:   "100",
+			pnl:          "10",
+		},
+		{
+			name:         "bonus shares are added at the declared cost",
+			operation:    InvestmentCreatedInput{OperationSubtype: "bonus", Ratio: decimal.RequireFromString("0.1"), UnitPrice: decimal.NewFromInt(8), TotalValue: decimal.NewFromInt(5)},
+			quantity:     "16",
+			averagePrice: "9.818182",
+			totalValue:   "157.09",
+			pnl:          "0.09",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			operation := test.operation
+			operation.ID = "01"
+			operation.Type = "stock"
+			operation.OperationType = "corporate_action"
 
-	if result.Quantity != expectedQuantity {
-		t.Errorf("expected quantity %d, got %d", expectedQuantity, result.Quantity)
+			result, sale, err := ApplyOperation(position, operation)
+			if err != nil {
+				t.Fatalf("failure to apply corporate action: %v", err)
+			}
+
+			if !result.Quantity.Equal(decimal.RequireFromString(test.quantity)) {
+				t.Errorf("expected quantity %s, got %s", test.quantity, result.Quantity)
+			}
+			if !result.AveragePrice.Equal(decimal.RequireFromString(test.averagePrice)) {
+				t.Errorf("expected average price %s, got %s", test.averagePrice, result.AveragePrice)
+			}
+			if !result.TotalValue.Equal(decimal.RequireFromString(test.totalValue)) {
+				t.Errorf("expected total value %s, got %s", test.totalValue, result.TotalValue)
+			}
+
+			if test.pnl == "" {
+				if sale != nil {
+					t.Errorf("expected no sale, got %+v", *sale)
+				}
+				return
+			}
+			if sale == nil || !sale.Pnl.Equal(decimal.RequireFromString(test.pnl)) {
+				t.Errorf("expected a cash in lieu sale with pnl %s, got %+v", test.pnl, sale)
+			}
+		})
 	}
-	if result.TotalValue != expectedTotalValue {
-		t.Errorf("expected total value %.2f, got %.2f", expectedTotalValue, result.TotalValue)
+}
+
+func TestApplyBondRedemptionPartial(t *testing.T) {
+	position := Position{
+		Quantity:     decimal.NewFromInt(4),
+		AveragePrice: decimal.NewFromInt(1000),
+		TotalValue:   decimal.NewFromInt(4000),
+		Cost:         decimal.NewFromInt(40),
+	}
+
+	// the cost of the redemption itself does not come out of the purchase cost
+	operation := InvestmentCreatedInput{ID: "02", Quantity: decimal.NewFromInt(1), TotalValue: decimal.NewFromInt(1100), Cost: decimal.NewFromInt(3)}
+	result, sale := applyBondRedemption(position, operation)
+
+	if !result.Quantity.Equal(decimal.NewFromInt(3)) || !result.TotalValue.Equal(decimal.NewFromInt(3000)) || !result.Cost.Equal(decimal.NewFromInt(30)) {
+		t.Errorf("expected 3 units worth 3000 with cost 30, got %s worth %s with cost %s", result.Quantity, result.TotalValue, result.Cost)
 	}
-	if result.AveragePrice != expectedAveragePrice {
-		t.Errorf("expected average price %.2f, got %.2f", expectedAveragePrice, result.AveragePrice)
+	if !sale.Pnl.Equal(decimal.NewFromInt(100)) {
+		t.Errorf("expected a result of 100, got %s", sale.Pnl)
 	}
 }
@@ -2,52 +2,95 @@ package investment_summary_core
 
 import (
 	"time"
+
+	"github.com/silasstoffel/invest-tracker/apps/shared/decimal"
 )
 
 type InvestmentCreatedInput struct {
-	ID                   string    `json:"id"`
-	Type                 string    `json:"type"`
-	Symbol               string    `json:"symbol"`
-	BondIndex            string    `json:"bondIndex,omitempty"`
-	BondRate             float64   `json:"bondRate,omitempty"`
-	Quantity             float64   `json:"quantity"`
-	UnitPrice            float64   `json:"unitPrice"`
-	TotalValue           float64   `json:"totalValue"`
-	Cost                 float64   `json:"cost"`
-	OperationType        string    `json:"operationType"`
-	OperationDate        string    `json:"operationDate"`
-	OperationYear        int       `json:"operationYear"`
-	OperationMonth       int       `json:"operationMonth"`
-	DueDate              string    `json:"dueDate"`
-	Brokerage            string    `json:"brokerage"`
-	Note                 string    `json:"note"`
-	RedemptionPolicyType string    `json:"redemptionPolicyType"`
-	SellInvestmentId     string    `json:"sellInvestmentId,omitempty"`
-	CreatedAt            time.Time `json:"createdAt"`
-	UpdatedAt            time.Time `json:"updatedAt"`
+	ID                   string          `json:"id"`
+	Type                 string          `json:"type"`
+	Symbol               string          `json:"symbol"`
+	BondIndex            string          `json:"bondIndex,omitempty"`
+	BondRate             decimal.Decimal `json:"bondRate,omitzero"`
+	Quantity             decimal.Decimal `json:"quantity"`
+	UnitPrice            decimal.Decimal `json:"unitPrice"`
+	TotalValue           decimal.Decimal `json:"totalValue"`
+	Cost                 decimal.Decimal `json:"cost"`
+	OperationType        string          `json:"operationType"`
+	OperationSubtype     string          `json:"operationSubtype,omitempty"`
+	Ratio                decimal.Decimal `json:"ratio,omitzero"`
+	OperationDate        string          `json:"operationDate"`
+	OperationYear        int             `json:"operationYear"`
+	OperationMonth       int             `json:"operationMonth"`
+	DueDate              string          `json:"dueDate"`
+	Brokerage            string          `json:"brokerage"`
+	Note                 string          `json:"note"`
+	RedemptionPolicyType string          `json:"redemptionPolicyType"`
+	Issuer               string          `json:"issuer,omitempty"`
+	ProductKind          string          `json:"productKind,omitempty"`
+	SellInvestmentId     string          `json:"sellInvestmentId,omitempty"`
+	CreatedAt            time.Time       `json:"createdAt"`
+	UpdatedAt            time.Time       `json:"updatedAt"`
 }
 
 type InvestmentSummaryEntity struct {
-	ID                   string    `json:"id"`
-	Type                 string    `json:"type"`
-	Symbol               string    `json:"symbol"`
-	BondIndex            string    `json:"bondIndex,omitempty"`
-	BondRate             float64   `json:"bondRate,omitempty"`
-	Quantity             float64   `json:"quantity"`
-	AveragePrice         float64   `json:"averagePrice"`
-	TotalValue           float64   `json:"totalValue"`
-	Cost                 float64   `json:"cost"`
-	LastTransactionDate  time.Time `json:"lastOperationDate"`
-	LastTransactionType  string    `json:"lastOperationType"`
-	DueDate              string    `json:"dueDate"`
-	Brokerage            string    `json:"brokerage"`
-	RedemptionPolicyType string    `json:"redemptionPolicyType"`
-	CreatedAt            time.Time `json:"createdAt"`
-	UpdatedAt            time.Time `json:"updatedAt"`
+	ID                   string          `json:"id"`
+	InvestmentID         string          `json:"investmentId,omitempty"`
+	Type                 string          `json:"type"`
+	Symbol               string          `json:"symbol"`
+	BondIndex            string          `json:"bondIndex,omitempty"`
+	BondRate             decimal.Decimal `json:"bondRate,omitzero"`
+	Quantity             decimal.Decimal `json:"quantity"`
+	AveragePrice         decimal.Decimal `json:"averagePrice"`
+	TotalValue           decimal.Decimal `json:"totalValue"`
+	MarketValue          decimal.Decimal `json:"marketValue"`
+	Cost                 decimal.Decimal `json:"cost"`
+	LastTransactionDate  time.Time       `json:"lastOperationDate"`
+	LastTransactionType  string          `json:"lastOperationType"`
+	DueDate              string          `json:"dueDate"`
+	Brokerage            string          `json:"brokerage"`
+	RedemptionPolicyType string          `json:"redemptionPolicyType"`
+	CreatedAt            time.Time       `json:"createdAt"`
+	UpdatedAt            time.Time       `json:"updatedAt"`
 }
 
 type CalculateAverageCostOutput struct {
-	Quantity     float64
-	TotalValue   float64
-	AveragePrice float64
+	Quantity     decimal.Decimal
+	TotalValue   decimal.Decimal
+	AveragePrice decimal.Decimal
+}
+
+// Position is the state of a summarized investment after applying operations.
+type Position struct {
+	Quantity     decimal.Decimal
+	AveragePrice decimal.Decimal
+	TotalValue   decimal.Decimal
+	Cost         decimal.Decimal
+}
+
+// SaleResult is the realized result of a sell operation. The part of the sell
+// closed against purchases of the same day is a day trade, with its own result.
+type SaleResult struct {
+	InvestmentID        string
+	Pnl                 decimal.Decimal
+	AverageSellingPrice decimal.Decimal
+	DayTradeQuantity    decimal.Decimal
+	DayTradePnl         decimal.Decimal
+	// bond redemptions only: the taxes withheld at source and the proceeds after them
+	BondRedemption bool
+	IncomeTax      decimal.Decimal
+	Iof            decimal.Decimal
+	NetProceeds    decimal.Decimal
+}
+
+// PositionSnapshot is the position right after an operation was applied.
+type PositionSnapshot struct {
+	Operation InvestmentCreatedInput
+	Position  Position
+}
+
+type ReplayOutput struct {
+	Position  Position
+	Snapshots []PositionSnapshot
+	Sales     []SaleResult
 }
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/aws/aws-lambda-go/lambda"
+	investment_summary_service "github.com/silasstoffel/invest-tracker/apps/investments_summary/service"
+	"github.com/silasstoffel/invest-tracker/apps/shared/database"
+	"github.com/silasstoffel/invest-tracker/apps/shared/repository"
+	appConfig "github.com/silasstoffel/invest-tracker/config"
+)
+
+/* Example of event, every field is optional:
+{
+  "symbol": "VALE3",
+  "type": "stock",
+  "brokerage": "xp",
+  "from": "2024-01-01",
+  "to": "2024-12-31",
+  "dryRun": true
+}
+*/
+
+type RebuildEvent struct {
+	investment_summary_service.RebuildFilter
+	DryRun bool `json:"dryRun"`
+}
+
+type RebuildOutput struct {
+	DryRun  bool                                        `json:"dryRun"`
+	Changes []investment_summary_service.PositionChange `json:"changes"`
+}
+
+var (
+	env            *appConfig.Config
+	summaryService *investment_summary_service.Service
+)
+
+func init() {
+	env = appConfig.NewConfigFromEnvVars()
+
+	db, err := database.NewFromConfig(env)
+	if err != nil {
+		m := fmt.Sprintf("Failure to connect to database: %v", err)
+		log.Println(m)
+		panic(m)
+	}
+
+	summaryService = investment_summary_service.NewService(
+		repository.NewInvestmentRepository(db),
+		repository.NewSummaryRepository(db),
+	)
+}
+
+func Handler(ctx context.Context, event RebuildEvent) (RebuildOutput, error) {
+	log.Printf("Rebuilding investments summary. Filter: %+v Dry run: %v", event.RebuildFilter, event.DryRun)
+
+	changes, err := summaryService.Rebuild(ctx, event.RebuildFilter, event.DryRun)
+	for _, change := range changes {
+		log.Println(change.String())
+	}
+
+	if err != nil {
+		log.Printf("Failure to rebuild investments summary: %v", err)
+		return RebuildOutput{}, err
+	}
+
+	return RebuildOutput{DryRun: event.DryRun, Changes: changes}, nil
+}
+
+func main() {
+	lambda.Start(Handler)
+}
@@ -0,0 +1,256 @@
+package investment_summary_service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	investment_summary_core "github.com/silasstoffel/invest-tracker/apps/investments_summary/core"
+	"github.com/silasstoffel/invest-tracker/apps/shared/decimal"
+	"github.com/silasstoffel/invest-tracker/apps/shared/repository"
+)
+
+const (
+	RebuildCreate    = "create"
+	RebuildUpdate    = "update"
+	RebuildDelete    = "delete"
+	RebuildUnchanged = "unchanged"
+)
+
+// RebuildFilter selects the positions to rebuild. An empty filter rebuilds
+// everything. The date range selects positions with at least one operation in
+// it; those positions are still rebuilt from all of their operations.
+type RebuildFilter struct {
+	Symbol    string `json:"symbol,omitempty"`
+	Type      string `json:"type,omitempty"`
+	Brokerage string `json:"brokerage,omitempty"`
+	From      string `json:"from,omitempty"`
+	To        string `json:"to,omitempty"`
+}
+
+type PositionChange struct {
+	Action    string   `json:"action"`
+	Symbol    string   `json:"symbol"`
+	Type      string   `json:"type"`
+	Brokerage string   `json:"brokerage"`
+	SummaryID string   `json:"summaryId"`
+	Changes   []string `json:"changes,omitempty"`
+}
+
+func (c PositionChange) String() string {
+	signs := map[string]string{
+		RebuildCreate:    "+",
+		RebuildUpdate:    "~",
+		RebuildDelete:    "-",
+		RebuildUnchanged: "=",
+	}
+	line := fmt.Sprintf("%s %s (%s) at %s [%s]", signs[c.Action], c.Symbol, c.Type, c.Brokerage, c.SummaryID)
+	if len(c.Changes) > 0 {
+		line += ": " + strings.Join(c.Changes, ", ")
+	}
+	return line
+}
+
+// positionKey identifies the summary an operation belongs to. Each bond
+// purchase is a position of its own and its sells point to it.
+func positionKey(operation investment_summary_core.InvestmentCreatedInput) string {
+	if operation.Type == "bond" {
+		if operation.OperationType == "sell" {
+			return "bond|" + operation.SellInvestmentId
+		}
+		return "bond|" + operation.ID
+	}
+	return strings.Join([]string{operation.Symbol, operation.Type, operation.Brokerage}, "|")
+}
+
+func summaryKey(summary investment_summary_core.InvestmentSummaryEntity) string {
+	if summary.Type == "bond" {
+		return "bond|" + summary.InvestmentID
+	}
+	return strings.Join([]string{summary.Symbol, summary.Type, summary.Brokerage}, "|")
+}
+
+func inDateRange(operations []investment_summary_core.InvestmentCreatedInput, filter RebuildFilter) bool {
+	if filter.From == "" && filter.To == "" {
+		return true
+	}
+
+	for _, operation := range operations {
+		if filter.From != "" && operation.OperationDate < filter.From {
+			continue
+		}
+		if filter.To != "" && operation.OperationDate > filter.To {
+			continue
+		}
+		return true
+	}
+
+	return false
+}
+
+// newSummary describes a position that does not exist yet from its first operation.
+func newSummary(first investment_summary_core.InvestmentCreatedInput) investment_summary_core.InvestmentSummaryEntity {
+	now := time.Now().UTC()
+	return investment_summary_core.InvestmentSummaryEntity{
+		ID:                   createId(),
+		InvestmentID:         first.ID,
+		Type:                 first.Type,
+		Symbol:               first.Symbol,
+		BondIndex:            first.BondIndex,
+		BondRate:             first.BondRate,
+		DueDate:              first.DueDate,
+		Brokerage:            first.Brokerage,
+		RedemptionPolicyType: first.RedemptionPolicyType,
+		CreatedAt:            now,
+	}
+}
+
+func describeChanges(current, rebuilt investment_summary_core.InvestmentSummaryEntity) []string {
+	changes := []string{}
+	compare := func(name string, before, after decimal.Decimal) {
+		if !before.Equal(after) {
+			changes = append(changes, fmt.Sprintf("%s %s -> %s", name, before, after))
+		}
+	}
+
+	compare("quantity", current.Quantity, rebuilt.Quantity)
+	compare("average price", current.AveragePrice, rebuilt.AveragePrice)
+	compare("total value", current.TotalValue, rebuilt.TotalValue)
+	compare("cost", current.Cost, rebuilt.Cost)
+
+	before := "none"
+	if !current.LastTransactionDate.IsZero() {
+		before = current.LastTransactionDate.Format("2006-01-02")
+	}
+	after := rebuilt.LastTransactionDate.Format("2006-01-02")
+	if before != after {
+		changes = append(changes, fmt.Sprintf("last operation date %s -> %s", before, after))
+	}
+
+	return changes
+}
+
+// Rebuild recalculates investments_summary and investments_summary_history from
+// the operations stored in investments. With dryRun nothing is written and the
+// returned changes describe the difference against the current rows.
+func (s *Service) Rebuild(ctx context.Context, filter RebuildFilter, dryRun bool) ([]PositionChange, error) {
+	entities, err := s.investments.Find(ctx, repository.InvestmentFilter{
+		Symbol:    filter.Symbol,
+		Type:      filter.Type,
+		Brokerage: filter.Brokerage,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failure to read investments: %w", err)
+	}
+
+	current, err := s.summaries.Find(ctx, repository.SummaryFilter{
+		Symbol:    filter.Symbol,
+		Type:      filter.Type,
+		Brokerage: filter.Brokerage,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failure to read summarized investments: %w", err)
+	}
+
+	groups := map[string][]investment_summary_core.InvestmentCreatedInput{}
+	for _, entity := range entities {
+		operation := toCreatedInput(entity)
+		if !investment_summary_core.ChangesPosition(operation) {
+			continue
+		}
+		key := positionKey(operation)
+		groups[key] = append(groups[key], operation)
+	}
+
+	summaries := map[string]investment_summary_core.InvestmentSummaryEntity{}
+	for _, summary := range current {
+		summaries[summaryKey(summary)] = summary
+	}
+
+	keys := make([]string, 0, len(groups))
+	for key := range groups {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	changes := []PositionChange{}
+
+	for _, key := range keys {
+		operations := groups[key]
+		if !inDateRange(operations, filter) {
+			continue
+		}
+
+		investment_summary_core.SortOperations(operations)
+		summary, exists := summaries[key]
+		if !exists {
+			summary = newSummary(operations[0])
+		}
+
+		replayed, err := replaySummary(summary, operations)
+		if err != nil {
+			return changes, fmt.Errorf("failure to rebuild %s: %w", key, err)
+		}
+		change := PositionChange{
+			Action:    RebuildCreate,
+			Symbol:    summary.Symbol,
+			Type:      summary.Type,
+			Brokerage: summary.Brokerage,
+			SummaryID: summary.ID,
+		}
+
+		if exists {
+			change.Action = RebuildUpdate
+			change.Changes = describeChanges(summary, replayed.Summary)
+			if len(change.Changes) == 0 {
+				change.Action = RebuildUnchanged
+			}
+		} else {
+			change.Changes = describeChanges(investment_summary_core.InvestmentSummaryEntity{}, replayed.Summary)
+		}
+		changes = append(changes, change)
+
+		if dryRun {
+			continue
+		}
+
+		// history and pnl are rewritten even when the position did not change
+		if err := s.saveReplay(ctx, replayed, !exists); err != nil {
+			return changes, fmt.Errorf("failure to rebuild %s: %w", key, err)
+		}
+	}
+
+	// positions without operations, or duplicating the one kept for their key,
+	// only exist because of old data, they are dropped only when nothing
+	// narrowed the selection
+	if filter == (RebuildFilter{}) {
+		for _, summary := range current {
+			key := summaryKey(summary)
+			if _, ok := groups[key]; ok && summaries[key].ID == summary.ID {
+				continue
+			}
+
+			changes = append(changes, PositionChange{
+				Action:    RebuildDelete,
+				Symbol:    summary.Symbol,
+				Type:      summary.Type,
+				Brokerage: summary.Brokerage,
+				SummaryID: summary.ID,
+			})
+
+			if dryRun {
+				continue
+			}
+
+			if err := s.summaries.Delete(ctx, summary.ID); err != nil {
+				return changes, err
+			}
+		}
+	}
+
+	log.Printf("Rebuild of %d position(s) finished. Dry run: %v", len(changes), dryRun)
+	return changes, nil
+}
@@ -0,0 +1,373 @@
+package investment_summary_service
+
+import (
+	"context"
+	cryptoRand "crypto/rand"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/oklog/ulid/v2"
+	investment_core "github.com/silasstoffel/invest-tracker/apps/investments/core"
+	investment_summary_core "github.com/silasstoffel/invest-tracker/apps/investments_summary/core"
+	"github.com/silasstoffel/invest-tracker/apps/shared/decimal"
+	"github.com/silasstoffel/invest-tracker/apps/shared/repository"
+	tax_core "github.com/silasstoffel/invest-tracker/apps/tax/core"
+)
+
+// Service keeps investments_summary in sync with the operations created in investments.
+type Service struct {
+	investments repository.InvestmentRepository
+	summaries   repository.SummaryRepository
+}
+
+func NewService(investments repository.InvestmentRepository, summaries repository.SummaryRepository) *Service {
+	return &Service{
+		investments: investments,
+		summaries:   summaries,
+	}
+}
+
+func createId() string {
+	entropy := ulid.Monotonic(cryptoRand.Reader, 0)
+	t := time.Now().UTC()
+
+	return ulid.MustNew(ulid.Timestamp(t), entropy).String()
+}
+
+func operationDate(value string) time.Time {
+	od, _ := time.Parse("2006-01-02", value)
+	return od
+}
+
+func (s *Service) getSummarizedInvestment(ctx context.Context, input investment_summary_core.InvestmentCreatedInput) (investment_summary_core.InvestmentSummaryEntity, error) {
+	if input.Type == "bond" {
+		if input.OperationType != "sell" {
+			// every bond purchase is a position on its own
+			return investment_summary_core.InvestmentSummaryEntity{}, repository.ErrNotFound
+		}
+		return s.summaries.FindByInvestmentID(ctx, input.SellInvestmentId)
+	}
+
+	return s.summaries.FindByPosition(ctx, input.Symbol, input.Type, input.Brokerage)
+}
+
+func (s *Service) createSummarizedInvestment(ctx context.Context, input investment_summary_core.InvestmentCreatedInput) (string, error) {
+	if input.OperationType == "corporate_action" || input.OperationType == "income" {
+		return "", fmt.Errorf("there is no position of %s (%s) at %s to apply the %s", input.Symbol, input.Type, input.Brokerage, input.OperationType)
+	}
+	if input.OperationType != "buy" && input.OperationType != "sell" {
+		return "", errors.New("operation type must be 'buy' or 'sell'")
+	}
+
+	now := time.Now().UTC()
+	entity := investment_summary_core.InvestmentSummaryEntity{
+		ID:                   createId(),
+		InvestmentID:         input.ID,
+		Type:                 input.Type,
+		Symbol:               input.Symbol,
+		BondIndex:            input.BondIndex,
+		BondRate:             input.BondRate,
+		Quantity:             input.Quantity.Quantity(),
+		AveragePrice:         decimal.Zero,
+		TotalValue:           input.TotalValue.Money(),
+		Cost:                 input.Cost.Money(),
+		LastTransactionDate:  operationDate(input.OperationDate),
+		LastTransactionType:  input.OperationType,
+		DueDate:              input.DueDate,
+		Brokerage:            input.Brokerage,
+		RedemptionPolicyType: input.RedemptionPolicyType,
+		CreatedAt:            now,
+		UpdatedAt:            now,
+	}
+	if input.Quantity.IsPositive() {
+		entity.AveragePrice = input.TotalValue.Div(input.Quantity).Price()
+	}
+
+	if err := s.summaries.Create(ctx, entity); err != nil {
+		return "", err
+	}
+
+	if err := s.summaries.SaveHistory(ctx, entity.ID); err != nil {
+		log.Print(err)
+	}
+
+	return entity.ID, nil
+}
+
+func toPosition(summary investment_summary_core.InvestmentSummaryEntity) investment_summary_core.Position {
+	return investment_summary_core.Position{
+		Quantity:     summary.Quantity,
+		AveragePrice: summary.AveragePrice,
+		TotalValue:   summary.TotalValue,
+		Cost:         summary.Cost,
+	}
+}
+
+func withPosition(summary investment_summary_core.InvestmentSummaryEntity, position investment_summary_core.Position) investment_summary_core.InvestmentSummaryEntity {
+	summary.Quantity = position.Quantity
+	summary.AveragePrice = position.AveragePrice
+	summary.TotalValue = position.TotalValue
+	summary.Cost = position.Cost
+	return summary
+}
+
+func toCreatedInput(entity investment_core.InvestmentEntity) investment_summary_core.InvestmentCreatedInput {
+	return investment_summary_core.InvestmentCreatedInput{
+		ID:                   entity.ID,
+		Type:                 entity.Type,
+		Symbol:               entity.Symbol,
+		BondIndex:            entity.BondIndex,
+		BondRate:             entity.BondRate,
+		Quantity:             entity.Quantity,
+		UnitPrice:            entity.UnitPrice,
+		TotalValue:           entity.TotalValue,
+		Cost:                 entity.Cost,
+		OperationType:        entity.OperationType,
+		OperationSubtype:     entity.OperationSubtype,
+		Ratio:                entity.Ratio,
+		OperationDate:        entity.OperationDate,
+		OperationYear:        entity.OperationYear,
+		OperationMonth:       entity.OperationMonth,
+		DueDate:              entity.DueDate,
+		Brokerage:            entity.Brokerage,
+		Note:                 entity.Note,
+		RedemptionPolicyType: entity.RedemptionPolicyType,
+		Issuer:               entity.Issuer,
+		ProductKind:          entity.ProductKind,
+		SellInvestmentId:     entity.SellInvestmentId,
+		CreatedAt:            entity.CreatedAt,
+		UpdatedAt:            entity.UpdatedAt,
+	}
+}
+
+func (s *Service) updateSummarizedInvestment(ctx context.Context, currentPosition investment_summary_core.InvestmentSummaryEntity, createdInvestment investment_summary_core.InvestmentCreatedInput) error {
+	switch createdInvestment.OperationType {
+	case "buy", "sell", "corporate_action", "income":
+	default:
+		return errors.New("operation type must be 'buy', 'sell', 'corporate_action' or 'income'")
+	}
+
+	position, sale, err := investment_summary_core.ApplyOperation(toPosition(currentPosition), createdInvestment)
+	if err != nil {
+		return err
+	}
+	if sale != nil && sale.BondRedemption {
+		purchase, err := s.investments.FindByID(ctx, currentPosition.InvestmentID)
+		if err != nil {
+			return fmt.Errorf("failure to read purchase %s of bond redemption %s: %w", currentPosition.InvestmentID, createdInvestment.ID, err)
+		}
+		*sale = withRedemptionTax(*sale, toCreatedInput(purchase), createdInvestment)
+	}
+
+	updated := withPosition(currentPosition, position)
+	updated.LastTransactionDate = operationDate(createdInvestment.OperationDate)
+	updated.LastTransactionType = createdInvestment.OperationType
+	updated.UpdatedAt = time.Now().UTC()
+
+	if err := s.summaries.Update(ctx, updated); err != nil {
+		return err
+	}
+
+	if err := s.summaries.SaveHistory(ctx, updated.ID); err != nil {
+		log.Print(err)
+	}
+
+	if sale != nil {
+		if err := s.investments.UpdateProfitAndLoss(ctx, *sale); err != nil {
+			log.Print(err)
+		}
+	}
+
+	return nil
+}
+
+// withRedemptionTax fills the taxes withheld on a bond redemption, which depend
+// on how long the purchase was held and on the product.
+func withRedemptionTax(sale investment_summary_core.SaleResult, purchase, redemption investment_summary_core.InvestmentCreatedInput) investment_summary_core.SaleResult {
+	tax := tax_core.RedemptionTax(tax_core.BondRedemption{
+		Symbol:         purchase.Symbol,
+		ProductKind:    purchase.ProductKind,
+		PurchaseDate:   purchase.OperationDate,
+		RedemptionDate: redemption.OperationDate,
+		CostBasis:      redemption.TotalValue.Sub(sale.Pnl),
+		Proceeds:       redemption.TotalValue,
+	})
+
+	sale.IncomeTax = tax.IncomeTax
+	sale.Iof = tax.Iof
+	sale.NetProceeds = tax.NetProceeds
+	return sale
+}
+
+// isBackdated tells whether the operation happened before the last operation
+// already applied to the position. Bond positions belong to a single purchase
+// and are not replayed.
+func isBackdated(summary investment_summary_core.InvestmentSummaryEntity, input investment_summary_core.InvestmentCreatedInput) bool {
+	if input.Type == "bond" || summary.LastTransactionDate.IsZero() {
+		return false
+	}
+	return input.OperationDate < summary.LastTransactionDate.Format("2006-01-02")
+}
+
+// isSameDay tells whether the position already has an operation on the date of
+// the new one, in which case buys and sells of that day may be a day trade.
+func isSameDay(summary investment_summary_core.InvestmentSummaryEntity, input investment_summary_core.InvestmentCreatedInput) bool {
+	if input.Type == "bond" || summary.LastTransactionDate.IsZero() {
+		return false
+	}
+	return input.OperationDate == summary.LastTransactionDate.Format("2006-01-02")
+}
+
+// ReplayPosition recalculates a position from every operation of its
+// symbol/type/brokerage in chronological order, rewriting the summary,
+// its history snapshots and the profit and loss of each sale.
+func (s *Service) ReplayPosition(ctx context.Context, summary investment_summary_core.InvestmentSummaryEntity) error {
+	entities, err := s.investments.Find(ctx, repository.InvestmentFilter{
+		Symbol:    summary.Symbol,
+		Type:      summary.Type,
+		Brokerage: summary.Brokerage,
+	})
+	if err != nil {
+		return fmt.Errorf("failure to read operations of %s: %w", summary.Symbol, err)
+	}
+
+	if len(entities) == 0 {
+		return fmt.Errorf("no operations found for %s (%s) at %s", summary.Symbol, summary.Type, summary.Brokerage)
+	}
+
+	operations := make([]investment_summary_core.InvestmentCreatedInput, 0, len(entities))
+	for _, entity := range entities {
+		if operation := toCreatedInput(entity); investment_summary_core.ChangesPosition(operation) {
+			operations = append(operations, operation)
+		}
+	}
+
+	replayed, err := replaySummary(summary, operations)
+	if err != nil {
+		return fmt.Errorf("failure to replay %s: %w", summary.Symbol, err)
+	}
+	if err := s.saveReplay(ctx, replayed, false); err != nil {
+		return err
+	}
+
+	log.Printf("Replayed %d operation(s) of %s (%s) at %s", len(operations), summary.Symbol, summary.Type, summary.Brokerage)
+	return nil
+}
+
+type replayedSummary struct {
+	Summary   investment_summary_core.InvestmentSummaryEntity
+	Snapshots []investment_summary_core.InvestmentSummaryEntity
+	Sales     []investment_summary_core.SaleResult
+}
+
+// replaySummary applies the operations on top of an empty position, keeping
+// the identity and descriptive fields of summary.
+func replaySummary(summary investment_summary_core.InvestmentSummaryEntity, operations []investment_summary_core.InvestmentCreatedInput) (replayedSummary, error) {
+	replayed, err := investment_summary_core.Replay(operations)
+	if err != nil {
+		return replayedSummary{}, err
+	}
+	last := replayed.Snapshots[len(replayed.Snapshots)-1].Operation
+
+	updated := withPosition(summary, replayed.Position)
+	updated.LastTransactionDate = operationDate(last.OperationDate)
+	updated.LastTransactionType = last.OperationType
+	updated.UpdatedAt = time.Now().UTC()
+
+	snapshots := make([]investment_summary_core.InvestmentSummaryEntity, 0, len(replayed.Snapshots))
+	for _, snapshot := range replayed.Snapshots {
+		historic := withPosition(summary, snapshot.Position)
+		historic.LastTransactionDate = operationDate(snapshot.Operation.OperationDate)
+		historic.LastTransactionType = snapshot.Operation.OperationType
+		historic.MarketValue = decimal.Zero
+		snapshots = append(snapshots, historic)
+	}
+
+	if summary.Type == "bond" {
+		// the purchase is the first operation of a bond position
+		redemptions := map[string]investment_summary_core.InvestmentCreatedInput{}
+		for _, operation := range operations {
+			redemptions[operation.ID] = operation
+		}
+		for i, sale := range replayed.Sales {
+			if sale.BondRedemption {
+				replayed.Sales[i] = withRedemptionTax(sale, operations[0], redemptions[sale.InvestmentID])
+			}
+		}
+	}
+
+	return replayedSummary{Summary: updated, Snapshots: snapshots, Sales: replayed.Sales}, nil
+}
+
+func (s *Service) saveReplay(ctx context.Context, replayed replayedSummary, isNew bool) error {
+	var err error
+	if isNew {
+		err = s.summaries.Create(ctx, replayed.Summary)
+	} else {
+		err = s.summaries.Update(ctx, replayed.Summary)
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := s.summaries.ReplaceHistory(ctx, replayed.Summary.ID, replayed.Snapshots); err != nil {
+		return err
+	}
+
+	for _, sale := range replayed.Sales {
+		if err := s.investments.UpdateProfitAndLoss(ctx, sale); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Summarize applies a created investment to its summarized position, creating
+// the position when it does not exist yet.
+func (s *Service) Summarize(ctx context.Context, input investment_summary_core.InvestmentCreatedInput) error {
+	if !investment_summary_core.ChangesPosition(input) {
+		log.Printf("Operation %s is a %s income of %s, the position does not change", input.ID, input.OperationSubtype, input.Symbol)
+		return nil
+	}
+
+	summarized, err := s.getSummarizedInvestment(ctx, input)
+
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			log.Printf("Summarized investment not found for symbol %s. It does need to create it", input.Symbol)
+			summarizedId, err := s.createSummarizedInvestment(ctx, input)
+
+			if err != nil {
+				log.Printf("Failure to create summarized investment: %v", err)
+				return err
+			}
+			log.Printf("Created summarized investment with ID: %s - Symbol: %s", summarizedId, input.Symbol)
+
+			return nil
+		}
+
+		log.Printf("Failure to get summarized investment: %v", err)
+		return err
+	}
+
+	if isBackdated(summarized, input) {
+		log.Printf("Operation %s of %s happened before the last operation of the position (%s). Replaying it",
+			input.ID, input.Symbol, summarized.LastTransactionDate.Format("2006-01-02"))
+		return s.ReplayPosition(ctx, summarized)
+	}
+
+	if isSameDay(summarized, input) {
+		log.Printf("Position of %s already has operations on %s. Replaying it to book day trades", input.Symbol, input.OperationDate)
+		return s.ReplayPosition(ctx, summarized)
+	}
+
+	if err := s.updateSummarizedInvestment(ctx, summarized, input); err != nil {
+		log.Printf("Failure to update summarized investment: %v", err)
+		return fmt.Errorf("failure to update summarized investment: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,451 @@
+package investment_summary_service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	investment_core "github.com/silasstoffel/invest-tracker/apps/investments/core"
+	investment_summary_core "github.com/silasstoffel/invest-tracker/apps/investments_summary/core"
+	"github.com/silasstoffel/invest-tracker/apps/shared/database"
+	"github.com/silasstoffel/invest-tracker/apps/shared/decimal"
+	"github.com/silasstoffel/invest-tracker/apps/shared/repository"
+)
+
+func createOperation(t *testing.T, ctx context.Context, investments repository.InvestmentRepository, input investment_summary_core.InvestmentCreatedInput) {
+	t.Helper()
+	unitPrice := decimal.Zero
+	if input.Quantity.IsPositive() {
+		unitPrice = input.TotalValue.Div(input.Quantity)
+	}
+	err := investments.Create(ctx, investment_core.InvestmentEntity{
+		ID:               input.ID,
+		Type:             input.Type,
+		Symbol:           input.Symbol,
+		BondIndex:        input.BondIndex,
+		Quantity:         input.Quantity,
+		UnitPrice:        unitPrice,
+		TotalValue:       input.TotalValue,
+		OperationType:    input.OperationType,
+		OperationSubtype: input.OperationSubtype,
+		Ratio:            input.Ratio,
+		OperationDate:    input.OperationDate,
+		Brokerage:        input.Brokerage,
+		SellInvestmentId: input.SellInvestmentId,
+		CreatedAt:        time.Now().UTC(),
+		UpdatedAt:        time.Now().UTC(),
+	})
+	if err != nil {
+		t.Fatalf("failure to create investment: %v", err)
+	}
+}
+
+func newTestService(t *testing.T) (*database.SQLite, repository.InvestmentRepository, repository.SummaryRepository, *Service) {
+	t.Helper()
+	db, err := database.NewSQLite(":memory:")
+	if err != nil {
+		t.Fatalf("failure to open database: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	investments := repository.NewInvestmentRepository(db)
+	summaries := repository.NewSummaryRepository(db)
+	return db, investments, summaries, NewService(investments, summaries)
+}
+
+func TestSummarize(t *testing.T) {
+	ctx := context.Background()
+	db, investments, summaries, service := newTestService(t)
+
+	operations := []investment_summary_core.InvestmentCreatedInput{
+		{ID: "01", Type: "stock", Symbol: "VALE3", Brokerage: "xp", OperationType: "buy", OperationDate: "2025-01-10", Quantity: decimal.NewFromInt(10), TotalValue: decimal.NewFromInt(100)},
+		{ID: "02", Type: "stock", Symbol: "VALE3", Brokerage: "xp", OperationType: "buy", OperationDate: "2025-02-10", Quantity: decimal.NewFromInt(10), TotalValue: decimal.NewFromInt(120)},
+		{ID: "03", Type: "stock", Symbol: "VALE3", Brokerage: "xp", OperationType: "sell", OperationDate: "2025-03-10", Quantity: decimal.NewFromInt(5), TotalValue: decimal.NewFromInt(70)},
+	}
+
+	for _, operation := range operations {
+		createOperation(t, ctx, investments, operation)
+		if err := service.Summarize(ctx, operation); err != nil {
+			t.Fatalf("failure to summarize operation %s: %v", operation.ID, err)
+		}
+	}
+
+	summary, err := summaries.FindByPosition(ctx, "VALE3", "stock", "xp")
+	if err != nil {
+		t.Fatalf("failure to read summary: %v", err)
+	}
+
+	if !summary.Quantity.Equal(decimal.NewFromInt(15)) {
+		t.Errorf("expected quantity 15, got %s", summary.Quantity)
+	}
+	if !summary.TotalValue.Equal(decimal.NewFromInt(165)) {
+		t.Errorf("expected total value 165, got %s", summary.TotalValue)
+	}
+	if !summary.AveragePrice.Equal(decimal.NewFromInt(11)) {
+		t.Errorf("expected average price 11, got %s", summary.AveragePrice)
+	}
+
+	rows, err := db.Query(ctx, "SELECT pnl FROM investments WHERE id = ?", "03")
+	if err != nil || len(rows) != 1 {
+		t.Fatalf("failure to read pnl: %v", err)
+	}
+	if pnl := rows[0]["pnl"]; pnl != int64(15) && pnl != 15.0 {
+		t.Errorf("expected pnl 15.00, got %v", pnl)
+	}
+
+	rows, err = db.Query(ctx, "SELECT count(*) AS counter FROM investments_summary_history WHERE investment_summary_id = ?", summary.ID)
+	if err != nil || len(rows) != 1 {
+		t.Fatalf("failure to read history: %v", err)
+	}
+	if counter := rows[0]["counter"]; counter != int64(3) {
+		t.Errorf("expected 3 history snapshots, got %v", counter)
+	}
+}
+
+func TestSummarizeBondRedemption(t *testing.T) {
+	ctx := context.Background()
+	_, investments, summaries, service := newTestService(t)
+
+	operations := []investment_summary_core.InvestmentCreatedInput{
+		{ID: "01", Type: "bond", Symbol: "CDB BTG", BondIndex: "cdi", Brokerage: "btg", OperationType: "buy", OperationDate: "2025-01-01", Quantity: decimal.NewFromInt(2), TotalValue: decimal.NewFromInt(2000)},
+		// redeemed after 10 days: 66% of the gain is iof, 22.5% of the rest is income tax
+		{ID: "02", Type: "bond", Symbol: "CDB BTG", BondIndex: "cdi", Brokerage: "btg", OperationType: "sell", OperationDate: "2025-01-11", Quantity: decimal.NewFromInt(1), TotalValue: decimal.NewFromInt(1100), SellInvestmentId: "01"},
+	}
+
+	for _, operation := range operations {
+		createOperation(t, ctx, investments, operation)
+		if err := service.Summarize(ctx, operation); err != nil {
+			t.Fatalf("failure to summarize operation %s: %v", operation.ID, err)
+		}
+	}
+
+	check := func(stage string) {
+		summary, err := summaries.FindByInvestmentID(ctx, "01")
+		if err != nil {
+			t.Fatalf("%s: failure to read summary: %v", stage, err)
+		}
+		if !summary.Quantity.Equal(decimal.NewFromInt(1)) || !summary.TotalValue.Equal(decimal.NewFromInt(1000)) {
+			t.Errorf("%s: expected half of the principal left, got %s worth %s", stage, summary.Quantity, summary.TotalValue)
+		}
+
+		redemption, err := investments.FindByID(ctx, "02")
+		if err != nil {
+			t.Fatalf("%s: failure to read redemption: %v", stage, err)
+		}
+		if !redemption.Pnl.Equal(decimal.NewFromInt(100)) || !redemption.Iof.Equal(decimal.NewFromInt(66)) ||
+			!redemption.TaxWithheld.Equal(decimal.RequireFromString("7.65")) || !redemption.NetProceeds.Equal(decimal.RequireFromString("1026.35")) {
+			t.Errorf("%s: expected gain 100, iof 66, income tax 7.65 and net 1026.35, got %s, %s, %s and %s",
+				stage, redemption.Pnl, redemption.Iof, redemption.TaxWithheld, redemption.NetProceeds)
+		}
+	}
+
+	check("summarize")
+
+	if _, err := service.Rebuild(ctx, RebuildFilter{}, false); err != nil {
+		t.Fatalf("failure to rebuild: %v", err)
+	}
+	check("rebuild")
+}
+
+func TestSummarizeBackdatedOperation(t *testing.T) {
+	ctx := context.Background()
+	db, investments, summaries, service := newTestService(t)
+
+	// the february purchase arrives after the march sale was summarized
+	operations := []investment_summary_core.InvestmentCreatedInput{
+		{ID: "01", Type: "stock", Symbol: "VALE3", Brokerage: "xp", OperationType: "buy", OperationDate: "2025-01-10", Quantity: decimal.NewFromInt(10), TotalValue: decimal.NewFromInt(100)},
+		{ID: "02", Type: "stock", Symbol: "VALE3", Brokerage: "xp", OperationType: "sell", OperationDate: "2025-03-10", Quantity: decimal.NewFromInt(5), TotalValue: decimal.NewFromInt(70)},
+		{ID: "03", Type: "stock", Symbol: "VALE3", Brokerage: "xp", OperationType: "buy", OperationDate: "2025-02-10", Quantity: decimal.NewFromInt(10), TotalValue: decimal.NewFromInt(120)},
+	}
+
+	for _, operation := range operations {
+		createOperation(t, ctx, investments, operation)
+		if err := service.Summarize(ctx, operation); err != nil {
+			t.Fatalf("failure to summarize operation %s: %v", operation.ID, err)
+		}
+	}
+
+	summary, err := summaries.FindByPosition(ctx, "VALE3", "stock", "xp")
+	if err != nil {
+		t.Fatalf("failure to read summary: %v", err)
+	}
+
+	if !summary.Quantity.Equal(decimal.NewFromInt(15)) || !summary.TotalValue.Equal(decimal.NewFromInt(165)) || !summary.AveragePrice.Equal(decimal.NewFromInt(11)) {
+		t.Errorf("expected 15 shares at 11 (165), got %s shares at %s (%s)", summary.Quantity, summary.AveragePrice, summary.TotalValue)
+	}
+	if got := summary.LastTransactionDate.Format("2006-01-02"); got != "2025-03-10" {
+		t.Errorf("expected last operation date 2025-03-10, got %s", got)
+	}
+
+	rows, err := db.Query(ctx, "SELECT pnl FROM investments WHERE id = ?", "02")
+	if err != nil || len(rows) != 1 {
+		t.Fatalf("failure to read pnl: %v", err)
+	}
+	if pnl := rows[0]["pnl"]; pnl != int64(15) && pnl != 15.0 {
+		t.Errorf("expected pnl 15, got %v", pnl)
+	}
+
+	rows, err = db.Query(ctx, "SELECT last_operation_date, quantity FROM investments_summary_history WHERE investment_summary_id = ? ORDER BY id", summary.ID)
+	if err != nil {
+		t.Fatalf("failure to read history: %v", err)
+	}
+	expected := []string{"2025-01-10", "2025-02-10", "2025-03-10"}
+	if len(rows) != len(expected) {
+		t.Fatalf("expected %d history snapshots, got %d", len(expected), len(rows))
+	}
+	for i, row := range rows {
+		if row["last_operation_date"] != expected[i] {
+			t.Errorf("expected snapshot %d at %s, got %v", i, expected[i], row["last_operation_date"])
+		}
+	}
+}
+
+func TestSummarizeDayTrade(t *testing.T) {
+	ctx := context.Background()
+	_, investments, summaries, service := newTestService(t)
+
+	operations := []investment_summary_core.InvestmentCreatedInput{
+		{ID: "01", Type: "stock", Symbol: "VALE3", Brokerage: "xp", OperationType: "buy", OperationDate: "2025-01-10", Quantity: decimal.NewFromInt(10), TotalValue: decimal.NewFromInt(100)},
+		{ID: "02", Type: "stock", Symbol: "VALE3", Brokerage: "xp", OperationType: "buy", OperationDate: "2025-02-10", Quantity: decimal.NewFromInt(5), TotalValue: decimal.NewFromInt(60)},
+		{ID: "03", Type: "stock", Symbol: "VALE3", Brokerage: "xp", OperationType: "sell", OperationDate: "2025-02-10", Quantity: decimal.NewFromInt(5), TotalValue: decimal.NewFromInt(70)},
+	}
+
+	for _, operation := range operations {
+		createOperation(t, ctx, investments, operation)
+		if err := service.Summarize(ctx, operation); err != nil {
+			t.Fatalf("failure to summarize operation %s: %v", operation.ID, err)
+		}
+	}
+
+	summary, err := summaries.FindByPosition(ctx, "VALE3", "stock", "xp")
+	if err != nil {
+		t.Fatalf("failure to read summary: %v", err)
+	}
+	if !summary.Quantity.Equal(decimal.NewFromInt(10)) || !summary.AveragePrice.Equal(decimal.NewFromInt(10)) {
+		t.Errorf("expected the long term position untouched, got %s shares at %s", summary.Quantity, summary.AveragePrice)
+	}
+
+	sale, err := investments.FindByID(ctx, "03")
+	if err != nil {
+		t.Fatalf("failure to read sale: %v", err)
+	}
+	if !sale.DayTradeQuantity.Equal(decimal.NewFromInt(5)) || !sale.DayTradePnl.Equal(decimal.NewFromInt(10)) || !sale.Pnl.IsZero() {
+		t.Errorf("expected a day trade of 5 shares with result 10, got %s shares with result %s and swing result %s", sale.DayTradeQuantity, sale.DayTradePnl, sale.Pnl)
+	}
+}
+
+func TestSummarizeCorporateAction(t *testing.T) {
+	ctx := context.Background()
+	_, investments, summaries, service := newTestService(t)
+
+	// the last purchase is backdated to before the split, so the split is replayed on top of it
+	operations := []investment_summary_core.InvestmentCreatedInput{
+		{ID: "01", Type: "stock", Symbol: "VALE3", Brokerage: "xp", OperationType: "buy", OperationDate: "2025-01-10", Quantity: decimal.NewFromInt(10), TotalValue: decimal.NewFromInt(100)},
+		{ID: "02", Type: "stock", Symbol: "VALE3", Brokerage: "xp", OperationType: "corporate_action", OperationSubtype: "split", Ratio: decimal.NewFromInt(2), OperationDate: "2025-02-10"},
+		{ID: "03", Type: "stock", Symbol: "VALE3", Brokerage: "xp", OperationType: "buy", OperationDate: "2025-01-20", Quantity: decimal.NewFromInt(10), TotalValue: decimal.NewFromInt(50)},
+	}
+
+	for _, operation := range operations {
+		createOperation(t, ctx, investments, operation)
+		if err := service.Summarize(ctx, operation); err != nil {
+			t.Fatalf("failure to summarize operation %s: %v", operation.ID, err)
+		}
+	}
+
+	summary, err := summaries.FindByPosition(ctx, "VALE3", "stock", "xp")
+	if err != nil {
+		t.Fatalf("failure to read summary: %v", err)
+	}
+	if !summary.Quantity.Equal(decimal.NewFromInt(40)) || !summary.AveragePrice.Equal(decimal.RequireFromString("3.75")) {
+		t.Errorf("expected 40 shares at 3.75 after the split, got %s shares at %s", summary.Quantity, summary.AveragePrice)
+	}
+
+	history, err := summaries.FindHistory(ctx, summary.ID)
+	if err != nil {
+		t.Fatalf("failure to read history: %v", err)
+	}
+	if len(history) != 3 || history[2].LastTransactionType != "corporate_action" {
+		t.Fatalf("expected the split as the last of 3 snapshots, got %+v", history)
+	}
+
+	if err := service.Summarize(ctx, investment_summary_core.InvestmentCreatedInput{
+		ID: "04", Type: "stock", Symbol: "ITSA4", Brokerage: "xp", OperationType: "corporate_action", OperationSubtype: "bonus", Ratio: decimal.RequireFromString("0.1"), OperationDate: "2025-02-10",
+	}); err == nil {
+		t.Errorf("expected an error for a corporate action without position")
+	}
+}
+
+func TestChangeSymbol(t *testing.T) {
+	ctx := context.Background()
+	db, investments, summaries, service := newTestService(t)
+	aliases := repository.NewSymbolAliasRepository(db)
+	symbolChanges := NewSymbolChangeService(investments, summaries, aliases)
+
+	// the new ticker already has a position in the same brokerage
+	operations := []investment_summary_core.InvestmentCreatedInput{
+		{ID: "01", Type: "stock", Symbol: "OLDS3", Brokerage: "xp", OperationType: "buy", OperationDate: "2025-01-10", Quantity: decimal.NewFromInt(10), TotalValue: decimal.NewFromInt(100)},
+		{ID: "02", Type: "stock", Symbol: "NEWS3", Brokerage: "xp", OperationType: "buy", OperationDate: "2025-02-10", Quantity: decimal.NewFromInt(5), TotalValue: decimal.NewFromInt(60)},
+	}
+	for _, operation := range operations {
+		createOperation(t, ctx, investments, operation)
+		if err := service.Summarize(ctx, operation); err != nil {
+			t.Fatalf("failure to summarize operation %s: %v", operation.ID, err)
+		}
+	}
+
+	_, err := symbolChanges.ChangeSymbol(ctx, SymbolChange{OldSymbol: "OLDS3", NewSymbol: "NEWS3", Ratio: decimal.NewFromInt(2), Date: "2025-03-01"})
+	if err != nil {
+		t.Fatalf("failure to change symbol: %v", err)
+	}
+
+	positions, err := summaries.Find(ctx, repository.SummaryFilter{Type: "stock"})
+	if err != nil {
+		t.Fatalf("failure to read summaries: %v", err)
+	}
+	if len(positions) != 1 || positions[0].Symbol != "NEWS3" {
+		t.Fatalf("expected a single NEWS3 position, got %+v", positions)
+	}
+	if !positions[0].Quantity.Equal(decimal.NewFromInt(25)) || !positions[0].TotalValue.Equal(decimal.NewFromInt(160)) {
+		t.Errorf("expected 25 shares worth 160, got %s shares worth %s", positions[0].Quantity, positions[0].TotalValue)
+	}
+
+	converted, err := investments.FindByID(ctx, "01")
+	if err != nil {
+		t.Fatalf("failure to read operation: %v", err)
+	}
+	if converted.Symbol != "NEWS3" || !converted.Quantity.Equal(decimal.NewFromInt(20)) || !converted.UnitPrice.Equal(decimal.NewFromInt(5)) {
+		t.Errorf("expected the operation converted to 20 NEWS3 at 5, got %s %s at %s", converted.Quantity, converted.Symbol, converted.UnitPrice)
+	}
+
+	// a second change keeps the redirect of the first ticker in a single hop
+	if _, err := symbolChanges.ChangeSymbol(ctx, SymbolChange{OldSymbol: "NEWS3", NewSymbol: "LAST3", Ratio: decimal.NewFromInt(3)}); err != nil {
+		t.Fatalf("failure to change symbol: %v", err)
+	}
+	alias, err := aliases.FindByOldSymbol(ctx, "OLDS3")
+	if err != nil {
+		t.Fatalf("failure to read alias: %v", err)
+	}
+	if alias.NewSymbol != "LAST3" || !alias.Ratio.Equal(decimal.NewFromInt(6)) {
+		t.Errorf("expected OLDS3 redirected to LAST3 with ratio 6, got %+v", alias)
+	}
+}
+
+func TestRebuild(t *testing.T) {
+	ctx := context.Background()
+	_, investments, summaries, service := newTestService(t)
+
+	operations := []investment_summary_core.InvestmentCreatedInput{
+		{ID: "01", Type: "stock", Symbol: "VALE3", Brokerage: "xp", OperationType: "buy", OperationDate: "2025-01-10", Quantity: decimal.NewFromInt(10), TotalValue: decimal.NewFromInt(100)},
+		{ID: "02", Type: "stock", Symbol: "VALE3", Brokerage: "xp", OperationType: "buy", OperationDate: "2025-02-10", Quantity: decimal.NewFromInt(10), TotalValue: decimal.NewFromInt(120)},
+	}
+	for _, operation := range operations {
+		createOperation(t, ctx, investments, operation)
+	}
+
+	// a position left behind by old data, it has no operation
+	orphan := investment_summary_core.InvestmentSummaryEntity{ID: "orphan", Type: "stock", Symbol: "OIBR3", Brokerage: "xp", Quantity: decimal.NewFromInt(1)}
+	if err := summaries.Create(ctx, orphan); err != nil {
+		t.Fatalf("failure to create summary: %v", err)
+	}
+
+	changes, err := service.Rebuild(ctx, RebuildFilter{}, true)
+	if err != nil {
+		t.Fatalf("failure to run dry run: %v", err)
+	}
+	if len(changes) != 2 || changes[0].Action != RebuildCreate || changes[1].Action != RebuildDelete {
+		t.Fatalf("expected a create and a delete, got %+v", changes)
+	}
+	if _, err := summaries.FindByPosition(ctx, "VALE3", "stock", "xp"); err != repository.ErrNotFound {
+		t.Fatalf("expected dry run to write nothing, got %v", err)
+	}
+
+	if _, err := service.Rebuild(ctx, RebuildFilter{}, false); err != nil {
+		t.Fatalf("failure to rebuild: %v", err)
+	}
+
+	summary, err := summaries.FindByPosition(ctx, "VALE3", "stock", "xp")
+	if err != nil {
+		t.Fatalf("failure to read summary: %v", err)
+	}
+	if !summary.Quantity.Equal(decimal.NewFromInt(20)) || !summary.AveragePrice.Equal(decimal.NewFromInt(11)) {
+		t.Errorf("expected 20 shares at 11, got %s shares at %s", summary.Quantity, summary.AveragePrice)
+	}
+	if _, err := summaries.FindByPosition(ctx, "OIBR3", "stock", "xp"); err != repository.ErrNotFound {
+		t.Errorf("expected the orphan position to be deleted, got %v", err)
+	}
+
+	changes, err = service.Rebuild(ctx, RebuildFilter{}, true)
+	if err != nil || len(changes) != 1 || changes[0].Action != RebuildUnchanged {
+		t.Errorf("expected a rebuilt position to be unchanged, got %+v (%v)", changes, err)
+	}
+}
+
+func TestRebuildFilteredBySymbol(t *testing.T) {
+	ctx := context.Background()
+	_, investments, summaries, service := newTestService(t)
+
+	createOperation(t, ctx, investments, investment_summary_core.InvestmentCreatedInput{
+		ID: "01", Type: "stock", Symbol: "VALE3", Brokerage: "xp", OperationType: "buy", OperationDate: "2025-01-10", Quantity: decimal.NewFromInt(10), TotalValue: decimal.NewFromInt(100),
+	})
+	if _, err := service.Rebuild(ctx, RebuildFilter{}, false); err != nil {
+		t.Fatalf("failure to rebuild: %v", err)
+	}
+
+	// an orphan outside the filter must survive a filtered rebuild
+	orphan := investment_summary_core.InvestmentSummaryEntity{ID: "orphan", Type: "stock", Symbol: "OIBR3", Brokerage: "xp", Quantity: decimal.NewFromInt(1)}
+	if err := summaries.Create(ctx, orphan); err != nil {
+		t.Fatalf("failure to create summary: %v", err)
+	}
+
+	changes, err := service.Rebuild(ctx, RebuildFilter{Symbol: "vale3", Brokerage: "XP"}, false)
+	if err != nil {
+		t.Fatalf("failure to rebuild: %v", err)
+	}
+	if len(changes) != 1 || changes[0].Action != RebuildUnchanged {
+		t.Fatalf("expected the position to be unchanged, got %+v", changes)
+	}
+
+	summary, err := summaries.FindByPosition(ctx, "VALE3", "stock", "xp")
+	if err != nil {
+		t.Fatalf("expected the position to be kept, got %v", err)
+	}
+	if !summary.Quantity.Equal(decimal.NewFromInt(10)) {
+		t.Errorf("expected 10 shares, got %s", summary.Quantity)
+	}
+	if _, err := summaries.FindByPosition(ctx, "OIBR3", "stock", "xp"); err != nil {
+		t.Errorf("expected the orphan position to be kept, got %v", err)
+	}
+}
+
+func TestRebuildMergesDuplicatedPositions(t *testing.T) {
+	ctx := context.Background()
+	_, investments, summaries, service := newTestService(t)
+
+	createOperation(t, ctx, investments, investment_summary_core.InvestmentCreatedInput{
+		ID: "01", Type: "stock", Symbol: "PETR4", Brokerage: "xp", OperationType: "buy", OperationDate: "2025-01-10", Quantity: decimal.NewFromInt(10), TotalValue: decimal.NewFromInt(300),
+	})
+	if _, err := service.Rebuild(ctx, RebuildFilter{Symbol: "petr4"}, false); err != nil {
+		t.Fatalf("failure to rebuild: %v", err)
+	}
+	changes, err := service.Rebuild(ctx, RebuildFilter{Symbol: "petr4"}, false)
+	if err != nil || len(changes) != 1 || changes[0].Action != RebuildUnchanged {
+		t.Fatalf("expected a rebuild filtered in lower case to find the position, got %+v (%v)", changes, err)
+	}
+
+	// a position informed in lower case, upper-cased by the migration
+	duplicate := investment_summary_core.InvestmentSummaryEntity{ID: "duplicate", Type: "stock", Symbol: "PETR4", Brokerage: "xp", Quantity: decimal.NewFromInt(5)}
+	if err := summaries.Create(ctx, duplicate); err != nil {
+		t.Fatalf("failure to create summary: %v", err)
+	}
+
+	if _, err := service.Rebuild(ctx, RebuildFilter{}, false); err != nil {
+		t.Fatalf("failure to rebuild: %v", err)
+	}
+	positions, err := summaries.Find(ctx, repository.SummaryFilter{Symbol: "PETR4"})
+	if err != nil {
+		t.Fatalf("failure to read summaries: %v", err)
+	}
+	if len(positions) != 1 || !positions[0].Quantity.Equal(decimal.NewFromInt(10)) {
+		t.Errorf("expected a single position of 10 shares, got %+v", positions)
+	}
+}
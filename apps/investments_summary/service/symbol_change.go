@@ -0,0 +1,90 @@
+package investment_summary_service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	investment_core "github.com/silasstoffel/invest-tracker/apps/investments/core"
+	"github.com/silasstoffel/invest-tracker/apps/shared/decimal"
+	"github.com/silasstoffel/invest-tracker/apps/shared/repository"
+)
+
+// SymbolChange moves the positions of a ticker to a new one after a merger or a rebranding.
+type SymbolChange struct {
+	OldSymbol string `json:"oldSymbol"`
+	NewSymbol string `json:"newSymbol"`
+	// new shares for each old share, 1 when omitted
+	Ratio decimal.Decimal `json:"ratio,omitzero"`
+	// YYYY-MM-DD, today when omitted
+	Date string `json:"date,omitempty"`
+}
+
+func CheckSymbolChange(change SymbolChange) error {
+	if change.OldSymbol == "" || change.NewSymbol == "" {
+		return errors.New("old and new symbols are required")
+	}
+	if change.OldSymbol == change.NewSymbol {
+		return errors.New("old and new symbols must be different")
+	}
+	if change.Ratio.IsNegative() {
+		return errors.New("ratio must be greater than zero")
+	}
+	if change.Date != "" {
+		if _, err := time.Parse("2006-01-02", change.Date); err != nil {
+			return errors.New("date must be in the format YYYY-MM-DD")
+		}
+	}
+	return nil
+}
+
+type SymbolChangeService struct {
+	summaries *Service
+	aliases   repository.SymbolAliasRepository
+}
+
+func NewSymbolChangeService(investments repository.InvestmentRepository, summaries repository.SummaryRepository, aliases repository.SymbolAliasRepository) *SymbolChangeService {
+	return &SymbolChangeService{
+		summaries: NewService(investments, summaries),
+		aliases:   aliases,
+	}
+}
+
+// ChangeSymbol re-keys the operations, positions and history of the old ticker
+// and keeps the alias so operations still informed with it are redirected.
+// The positions of the new ticker are then rebuilt, which merges them with the
+// ones it already had and converts the history by the ratio.
+func (s *SymbolChangeService) ChangeSymbol(ctx context.Context, change SymbolChange) ([]PositionChange, error) {
+	change.OldSymbol = investment_core.NormalizeSymbol(investment_core.StockInvestmentType, change.OldSymbol)
+	change.NewSymbol = investment_core.NormalizeSymbol(investment_core.StockInvestmentType, change.NewSymbol)
+	if err := CheckSymbolChange(change); err != nil {
+		return nil, err
+	}
+
+	if change.Ratio.IsZero() {
+		change.Ratio = decimal.NewFromInt(1)
+	}
+	if change.Date == "" {
+		change.Date = time.Now().UTC().Format("2006-01-02")
+	}
+
+	err := s.aliases.ChangeSymbol(ctx, repository.SymbolAlias{
+		OldSymbol:     change.OldSymbol,
+		NewSymbol:     change.NewSymbol,
+		Ratio:         change.Ratio,
+		EffectiveDate: change.Date,
+	})
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("Symbol %s changed to %s with ratio %s", change.OldSymbol, change.NewSymbol, change.Ratio)
+
+	changes, err := s.summaries.Rebuild(ctx, RebuildFilter{Symbol: change.NewSymbol}, false)
+	if err != nil {
+		return changes, fmt.Errorf("failure to rebuild positions of %s: %w", change.NewSymbol, err)
+	}
+
+	return changes, nil
+}
@@ -0,0 +1,178 @@
+package portfolio_core
+
+import (
+	"sort"
+	"time"
+
+	investment_core "github.com/silasstoffel/invest-tracker/apps/investments/core"
+	investment_summary_core "github.com/silasstoffel/invest-tracker/apps/investments_summary/core"
+	"github.com/silasstoffel/invest-tracker/apps/shared/decimal"
+)
+
+// liquidity buckets, by the days until the position can be turned into cash
+const (
+	LiquidToday      = "today"
+	LiquidD1         = "d+1"
+	LiquidD2         = "d+2"
+	LiquidD30        = "d+30"
+	LiquidAtMaturity = "at_maturity"
+)
+
+// equities settle on D+2 at B3
+const equitySettlementDays = 2
+
+// bonds traded on the secondary market settle on D+1, the others are redeemed
+// with the issuer on the same day
+var secondaryMarketProducts = map[string]bool{
+	investment_core.TesouroProduct:   true,
+	investment_core.DebentureProduct: true,
+	investment_core.CriProduct:       true,
+	investment_core.CraProduct:       true,
+}
+
+type LiquidityPosition struct {
+	Symbol               string `json:"symbol"`
+	Brokerage            string `json:"brokerage"`
+	Type                 string `json:"type"`
+	RedemptionPolicyType string `json:"redemptionPolicyType,omitempty"`
+	// YYYY-MM-DD, when the cash is available
+	AvailableOn string          `json:"availableOn"`
+	Value       decimal.Decimal `json:"value"`
+}
+
+type LiquidityBucket struct {
+	Name      string              `json:"name"`
+	Positions []LiquidityPosition `json:"positions"`
+	Value     decimal.Decimal     `json:"value"`
+	// share of the portfolio value, from 0 to 100
+	Percentage decimal.Decimal `json:"percentage"`
+	// value available up to this bucket, this one included
+	Cumulative           decimal.Decimal `json:"cumulative"`
+	CumulativePercentage decimal.Decimal `json:"cumulativePercentage"`
+}
+
+type Liquidity struct {
+	Date    string            `json:"date"`
+	Total   decimal.Decimal   `json:"total"`
+	Buckets []LiquidityBucket `json:"buckets"`
+}
+
+// LiquidityDays returns in how many days a position can be turned into cash.
+// purchase is the bond purchase of the position, with its product and grace
+// period. Bonds without a redemption policy are taken as redeemed at maturity.
+func LiquidityDays(summary investment_summary_core.InvestmentSummaryEntity, purchase investment_core.InvestmentEntity, today time.Time) int {
+	if summary.Type != investment_core.BondInvestmentType {
+		return equitySettlementDays
+	}
+
+	anyTime := 0
+	if secondaryMarketProducts[purchase.ProductKind] {
+		anyTime = 1
+	}
+
+	switch summary.RedemptionPolicyType {
+	case investment_core.AnyTimeRedemption:
+		return anyTime
+	case investment_core.HybridRedemption:
+		if purchase.GracePeriodDate != "" {
+			days := daysUntil(purchase.GracePeriodDate, today)
+			if days <= 0 {
+				return anyTime
+			}
+			if due := daysUntil(summary.DueDate, today); due >= 0 && due < days {
+				return due
+			}
+			return days
+		}
+	}
+	return daysUntil(summary.DueDate, today)
+}
+
+// daysUntil returns the calendar days from today to date, or -1 when there is
+// no date to count to.
+func daysUntil(date string, today time.Time) int {
+	parsed, err := time.Parse("2006-01-02", date)
+	if err != nil {
+		return -1
+	}
+	day := time.Date(today.Year(), today.Month(), today.Day(), 0, 0, 0, 0, time.UTC)
+	return max(int(parsed.Sub(day).Hours()/24), 0)
+}
+
+func liquidityBucket(days int) string {
+	switch {
+	case days < 0:
+		return LiquidAtMaturity
+	case days == 0:
+		return LiquidToday
+	case days == 1:
+		return LiquidD1
+	case days == 2:
+		return LiquidD2
+	case days <= 30:
+		return LiquidD30
+	default:
+		return LiquidAtMaturity
+	}
+}
+
+// BuildLiquidity groups the value of the open positions by when it can be
+// turned into cash. Positions are valued at the market value when there is
+// one, and purchases maps an investment id to the bond purchase.
+func BuildLiquidity(summaries []investment_summary_core.InvestmentSummaryEntity, purchases map[string]investment_core.InvestmentEntity, today time.Time) Liquidity {
+	names := []string{LiquidToday, LiquidD1, LiquidD2, LiquidD30, LiquidAtMaturity}
+	buckets := map[string]*LiquidityBucket{}
+	for _, name := range names {
+		buckets[name] = &LiquidityBucket{Name: name, Positions: []LiquidityPosition{}, Value: decimal.Zero}
+	}
+
+	liquidity := Liquidity{Date: today.Format("2006-01-02"), Total: decimal.Zero, Buckets: []LiquidityBucket{}}
+	for _, summary := range summaries {
+		if !IsOpen(summary, today) {
+			continue
+		}
+
+		value := MarketValue(summary)
+
+		days := LiquidityDays(summary, purchases[summary.InvestmentID], today)
+		availableOn := summary.DueDate
+		if days >= 0 {
+			availableOn = today.AddDate(0, 0, days).Format("2006-01-02")
+		}
+
+		bucket := buckets[liquidityBucket(days)]
+		bucket.Positions = append(bucket.Positions, LiquidityPosition{
+			Symbol:               summary.Symbol,
+			Brokerage:            summary.Brokerage,
+			Type:                 summary.Type,
+			RedemptionPolicyType: summary.RedemptionPolicyType,
+			AvailableOn:          availableOn,
+			Value:                value,
+		})
+		bucket.Value = bucket.Value.Add(value)
+		liquidity.Total = liquidity.Total.Add(value)
+	}
+
+	cumulative := decimal.Zero
+	hundred := decimal.NewFromInt(100)
+	for _, name := range names {
+		bucket := buckets[name]
+		cumulative = cumulative.Add(bucket.Value)
+		bucket.Cumulative = cumulative
+		bucket.Percentage, bucket.CumulativePercentage = decimal.Zero, decimal.Zero
+		if liquidity.Total.IsPositive() {
+			bucket.Percentage = bucket.Value.Div(liquidity.Total).Mul(hundred).Round(2)
+			bucket.CumulativePercentage = cumulative.Div(liquidity.Total).Mul(hundred).Round(2)
+		}
+		sort.Slice(bucket.Positions, func(i, j int) bool {
+			a, b := bucket.Positions[i], bucket.Positions[j]
+			if a.AvailableOn != b.AvailableOn {
+				return a.AvailableOn < b.AvailableOn
+			}
+			return a.Symbol < b.Symbol
+		})
+		liquidity.Buckets = append(liquidity.Buckets, *bucket)
+	}
+
+	return liquidity
+}
@@ -0,0 +1,68 @@
+package portfolio_core
+
+import (
+	"testing"
+	"time"
+
+	investment_core "github.com/silasstoffel/invest-tracker/apps/investments/core"
+	investment_summary_core "github.com/silasstoffel/invest-tracker/apps/investments_summary/core"
+	"github.com/silasstoffel/invest-tracker/apps/shared/decimal"
+)
+
+func TestBuildLiquidity(t *testing.T) {
+	today := time.Date(2025, 6, 10, 0, 0, 0, 0, time.UTC)
+	summaries := []investment_summary_core.InvestmentSummaryEntity{
+		{Symbol: "VALE3", Type: "stock", Brokerage: "xp", Quantity: decimal.NewFromInt(10), TotalValue: decimal.NewFromInt(600), MarketValue: decimal.NewFromInt(700)},
+		{Symbol: "CDB LIQUIDEZ", Type: "bond", InvestmentID: "1", RedemptionPolicyType: "any_time", Quantity: decimal.NewFromInt(1), TotalValue: decimal.NewFromInt(1000), DueDate: "2027-01-04"},
+		{Symbol: "TESOURO SELIC 2029", Type: "bond", InvestmentID: "2", RedemptionPolicyType: "any_time", Quantity: decimal.NewFromInt(1), TotalValue: decimal.NewFromInt(2000), DueDate: "2029-03-01"},
+		// grace period over, redeemable at any time
+		{Symbol: "CDB BTG", Type: "bond", InvestmentID: "3", RedemptionPolicyType: "hybrid", Quantity: decimal.NewFromInt(1), TotalValue: decimal.NewFromInt(300), DueDate: "2028-01-03"},
+		// grace period ends in 20 days
+		{Symbol: "LCA BTG", Type: "bond", InvestmentID: "4", RedemptionPolicyType: "hybrid", Quantity: decimal.NewFromInt(1), TotalValue: decimal.NewFromInt(400), DueDate: "2028-01-03"},
+		// matures in 15 days
+		{Symbol: "LCI BARI", Type: "bond", InvestmentID: "5", RedemptionPolicyType: "at_maturity", Quantity: decimal.NewFromInt(1), TotalValue: decimal.NewFromInt(500), DueDate: "2025-06-25"},
+		{Symbol: "CDB PRE BARI", Type: "bond", InvestmentID: "6", RedemptionPolicyType: "at_maturity", Quantity: decimal.NewFromInt(1), TotalValue: decimal.NewFromInt(5000), DueDate: "2026-06-10"},
+		// hybrid without the grace period is taken as at maturity
+		{Symbol: "LCI INTER", Type: "bond", InvestmentID: "7", RedemptionPolicyType: "hybrid", Quantity: decimal.NewFromInt(1), TotalValue: decimal.NewFromInt(500), DueDate: "2026-06-10"},
+		// matured
+		{Symbol: "CDB OLD", Type: "bond", InvestmentID: "8", RedemptionPolicyType: "at_maturity", Quantity: decimal.NewFromInt(1), TotalValue: decimal.NewFromInt(9000), DueDate: "2025-06-09"},
+	}
+	purchases := map[string]investment_core.InvestmentEntity{
+		"1": {ProductKind: "cdb"},
+		"2": {ProductKind: "tesouro"},
+		"3": {ProductKind: "cdb", GracePeriodDate: "2025-06-01"},
+		"4": {ProductKind: "lca", GracePeriodDate: "2025-06-30"},
+	}
+
+	liquidity := BuildLiquidity(summaries, purchases, today)
+
+	expected := []struct {
+		name       string
+		positions  int
+		value      int64
+		cumulative int64
+	}{
+		{LiquidToday, 2, 1300, 1300},
+		{LiquidD1, 1, 2000, 3300},
+		{LiquidD2, 1, 700, 4000},
+		{LiquidD30, 2, 900, 4900},
+		{LiquidAtMaturity, 2, 5500, 10400},
+	}
+	if len(liquidity.Buckets) != len(expected) || !liquidity.Total.Equal(decimal.NewFromInt(10400)) {
+		t.Fatalf("expected 5 buckets totaling 10400, got %+v", liquidity)
+	}
+	for i, e := range expected {
+		bucket := liquidity.Buckets[i]
+		if bucket.Name != e.name || len(bucket.Positions) != e.positions || !bucket.Value.Equal(decimal.NewFromInt(e.value)) || !bucket.Cumulative.Equal(decimal.NewFromInt(e.cumulative)) {
+			t.Errorf("expected %s with %d position(s), %d and %d cumulative, got %+v", e.name, e.positions, e.value, e.cumulative, bucket)
+		}
+	}
+
+	d30 := liquidity.Buckets[3]
+	if d30.Positions[0].Symbol != "LCI BARI" || d30.Positions[1].AvailableOn != "2025-06-30" {
+		t.Errorf("expected d+30 sorted by availability, got %+v", d30.Positions)
+	}
+	if !liquidity.Buckets[1].CumulativePercentage.Equal(decimal.RequireFromString("31.73")) {
+		t.Errorf("expected 31.73%% liquid within d+1, got %s", liquidity.Buckets[1].CumulativePercentage)
+	}
+}
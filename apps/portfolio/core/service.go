@@ -0,0 +1,99 @@
+package portfolio_core
+
+import (
+	"sort"
+	"time"
+
+	investment_summary_core "github.com/silasstoffel/invest-tracker/apps/investments_summary/core"
+	"github.com/silasstoffel/invest-tracker/apps/shared/decimal"
+)
+
+// IsOpen tells whether a position still counts in the portfolio: it has
+// quantity and, for bonds, did not reach the due date.
+func IsOpen(summary investment_summary_core.InvestmentSummaryEntity, today time.Time) bool {
+	if !summary.Quantity.IsPositive() {
+		return false
+	}
+
+	return summary.DueDate == "" || summary.DueDate >= today.Format("2006-01-02")
+}
+
+// MarketValue is the last accrued or quoted value of a position, or the
+// invested one when it was never valued.
+func MarketValue(summary investment_summary_core.InvestmentSummaryEntity) decimal.Decimal {
+	if summary.MarketValue.IsPositive() {
+		return summary.MarketValue
+	}
+	return summary.TotalValue
+}
+
+// Overview groups the invested value of the open positions by type, brokerage
+// and segment, with their market value alongside. The percentages are of the
+// invested total. segments maps a symbol to its symbol_details segment.
+func Overview(summaries []investment_summary_core.InvestmentSummaryEntity, segments map[string]string, today time.Time) Portfolio {
+	portfolio := Portfolio{Total: decimal.Zero, MarketValue: decimal.Zero}
+	byType := map[string]*Allocation{}
+	byBrokerage := map[string]*Allocation{}
+	bySegment := map[string]*Allocation{}
+
+	for _, summary := range summaries {
+		if !IsOpen(summary, today) {
+			continue
+		}
+
+		segment, ok := segments[summary.Symbol]
+		if !ok || segment == "" || segment == "#" {
+			segment = UnclassifiedSegment
+		}
+
+		portfolio.Total = portfolio.Total.Add(summary.TotalValue)
+		portfolio.MarketValue = portfolio.MarketValue.Add(MarketValue(summary))
+		portfolio.Positions++
+		allocate(byType, summary.Type, summary)
+		allocate(byBrokerage, summary.Brokerage, summary)
+		allocate(bySegment, segment, summary)
+	}
+
+	portfolio.MarketValue = portfolio.MarketValue.Money()
+
+	portfolio.ByType = allocations(byType, portfolio.Total)
+	portfolio.ByBrokerage = allocations(byBrokerage, portfolio.Total)
+	portfolio.BySegment = allocations(bySegment, portfolio.Total)
+
+	return portfolio
+}
+
+func allocate(groups map[string]*Allocation, name string, summary investment_summary_core.InvestmentSummaryEntity) {
+	group, ok := groups[name]
+	if !ok {
+		group = &Allocation{Name: name, Total: decimal.Zero, MarketValue: decimal.Zero}
+		groups[name] = group
+	}
+
+	group.Positions++
+	group.Total = group.Total.Add(summary.TotalValue)
+	group.MarketValue = group.MarketValue.Add(MarketValue(summary))
+}
+
+// allocations returns the groups with their percentage, largest first.
+func allocations(groups map[string]*Allocation, total decimal.Decimal) []Allocation {
+	result := make([]Allocation, 0, len(groups))
+	for _, group := range groups {
+		group.Total = group.Total.Money()
+		group.MarketValue = group.MarketValue.Money()
+		group.Percentage = decimal.Zero
+		if total.IsPositive() {
+			group.Percentage = group.Total.Div(total).Mul(decimal.NewFromInt(100)).Round(2)
+		}
+		result = append(result, *group)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if c := result[i].Total.Cmp(result[j].Total); c != 0 {
+			return c > 0
+		}
+		return result[i].Name < result[j].Name
+	})
+
+	return result
+}
@@ -0,0 +1,61 @@
+package portfolio_core
+
+import (
+	"testing"
+	"time"
+
+	investment_summary_core "github.com/silasstoffel/invest-tracker/apps/investments_summary/core"
+	"github.com/silasstoffel/invest-tracker/apps/shared/decimal"
+)
+
+func TestOverview(t *testing.T) {
+	today := time.Date(2025, 6, 10, 0, 0, 0, 0, time.UTC)
+	summaries := []investment_summary_core.InvestmentSummaryEntity{
+		{Symbol: "VALE3", Type: "stock", Brokerage: "xp", Quantity: decimal.NewFromInt(10), TotalValue: decimal.NewFromInt(600)},
+		{Symbol: "HGLG11", Type: "fii", Brokerage: "inter", Quantity: decimal.NewFromInt(2), TotalValue: decimal.NewFromInt(300)},
+		{Symbol: "LCA PRE BTG", Type: "bond", Brokerage: "btg", Quantity: decimal.NewFromInt(1), TotalValue: decimal.NewFromInt(100), MarketValue: decimal.NewFromInt(112), DueDate: "2025-06-10"},
+		// matured bond and closed position are left out
+		{Symbol: "CDB BARI", Type: "bond", Brokerage: "btg", Quantity: decimal.NewFromInt(1), TotalValue: decimal.NewFromInt(5000), DueDate: "2025-06-09"},
+		{Symbol: "BBDC3", Type: "stock", Brokerage: "xp", Quantity: decimal.Zero, TotalValue: decimal.Zero},
+	}
+	segments := map[string]string{"VALE3": "mining", "HGLG11": "#"}
+
+	portfolio := Overview(summaries, segments, today)
+
+	if portfolio.Positions != 3 || !portfolio.Total.Equal(decimal.NewFromInt(1000)) {
+		t.Fatalf("expected 3 positions totaling 1000, got %d totaling %s", portfolio.Positions, portfolio.Total)
+	}
+	// the bond is at its accrued value, the others were never valued
+	if !portfolio.MarketValue.Equal(decimal.NewFromInt(1012)) {
+		t.Errorf("expected a market value of 1012, got %s", portfolio.MarketValue)
+	}
+
+	expected := []struct {
+		allocations []Allocation
+		name        string
+		percentage  string
+	}{
+		{portfolio.ByType, "stock", "60"},
+		{portfolio.ByBrokerage, "inter", "30"},
+		{portfolio.BySegment, UnclassifiedSegment, "40"},
+	}
+
+	for _, e := range expected {
+		found := false
+		for _, allocation := range e.allocations {
+			if allocation.Name == e.name {
+				found = true
+				if !allocation.Percentage.Equal(decimal.RequireFromString(e.percentage)) {
+					t.Errorf("expected %s to be %s%%, got %s", e.name, e.percentage, allocation.Percentage)
+				}
+			}
+		}
+		if !found {
+			t.Errorf("allocation %s not found", e.name)
+		}
+	}
+
+	if portfolio.ByType[0].Name != "stock" {
+		t.Errorf("expected the largest allocation first, got %s", portfolio.ByType[0].Name)
+	}
+}
@@ -0,0 +1,27 @@
+package portfolio_core
+
+import (
+	"github.com/silasstoffel/invest-tracker/apps/shared/decimal"
+)
+
+// segment of the positions without symbol details
+const UnclassifiedSegment = "unclassified"
+
+type Allocation struct {
+	Name      string          `json:"name"`
+	Positions int             `json:"positions"`
+	Total     decimal.Decimal `json:"total"`
+	// accrued or quoted value, the invested one for positions never valued
+	MarketValue decimal.Decimal `json:"marketValue"`
+	// share of the portfolio total, from 0 to 100
+	Percentage decimal.Decimal `json:"percentage"`
+}
+
+type Portfolio struct {
+	Total       decimal.Decimal `json:"total"`
+	MarketValue decimal.Decimal `json:"marketValue"`
+	Positions   int             `json:"positions"`
+	ByType      []Allocation    `json:"byType"`
+	ByBrokerage []Allocation    `json:"byBrokerage"`
+	BySegment   []Allocation    `json:"bySegment"`
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambda"
+	investment_core "github.com/silasstoffel/invest-tracker/apps/investments/core"
+	portfolio_core "github.com/silasstoffel/invest-tracker/apps/portfolio/core"
+	"github.com/silasstoffel/invest-tracker/apps/shared/database"
+	http_helper "github.com/silasstoffel/invest-tracker/apps/shared/http_helpers"
+	"github.com/silasstoffel/invest-tracker/apps/shared/repository"
+	appConfig "github.com/silasstoffel/invest-tracker/config"
+)
+
+type ErrorOutput struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+var (
+	env                  *appConfig.Config
+	summaryRepository    repository.SummaryRepository
+	investmentRepository repository.InvestmentRepository
+)
+
+func init() {
+	env = appConfig.NewConfigFromEnvVars()
+
+	db, err := database.NewFromConfig(env)
+	if err != nil {
+		m := fmt.Sprintf("Failure to connect to database: %v", err)
+		log.Println(m)
+		panic(m)
+	}
+	summaryRepository = repository.NewSummaryRepository(db)
+	investmentRepository = repository.NewInvestmentRepository(db)
+}
+
+func Handler(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	summaries, err := summaryRepository.Find(ctx, repository.SummaryFilter{})
+	if err != nil {
+		log.Printf("Failure to read investments summary: %v", err)
+		return http_helper.JsonResponse(ErrorOutput{
+			Code:    "INTERNAL_ERROR",
+			Message: "failure to read investments summary",
+		}, http_helper.JsonResponseOptions{StatusCode: 500}), nil
+	}
+
+	bonds, err := investmentRepository.Find(ctx, repository.InvestmentFilter{
+		Type:          investment_core.BondInvestmentType,
+		OperationType: investment_core.BuyOperationType,
+	})
+	if err != nil {
+		log.Printf("Failure to read bond purchases: %v", err)
+		return http_helper.JsonResponse(ErrorOutput{
+			Code:    "INTERNAL_ERROR",
+			Message: "failure to read bond purchases",
+		}, http_helper.JsonResponseOptions{StatusCode: 500}), nil
+	}
+
+	purchases := map[string]investment_core.InvestmentEntity{}
+	for _, bond := range bonds {
+		purchases[bond.ID] = bond
+	}
+
+	return http_helper.JsonResponse(portfolio_core.BuildLiquidity(summaries, purchases, time.Now())), nil
+}
+
+func main() {
+	lambda.Start(Handler)
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambda"
+	portfolio_core "github.com/silasstoffel/invest-tracker/apps/portfolio/core"
+	"github.com/silasstoffel/invest-tracker/apps/shared/database"
+	http_helper "github.com/silasstoffel/invest-tracker/apps/shared/http_helpers"
+	"github.com/silasstoffel/invest-tracker/apps/shared/repository"
+	appConfig "github.com/silasstoffel/invest-tracker/config"
+)
+
+type ErrorOutput struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+var (
+	env                     *appConfig.Config
+	summaryRepository       repository.SummaryRepository
+	symbolDetailsRepository repository.SymbolDetailsRepository
+)
+
+func init() {
+	env = appConfig.NewConfigFromEnvVars()
+
+	db, err := database.NewFromConfig(env)
+	if err != nil {
+		m := fmt.Sprintf("Failure to connect to database: %v", err)
+		log.Println(m)
+		panic(m)
+	}
+	summaryRepository = repository.NewSummaryRepository(db)
+	symbolDetailsRepository = repository.NewSymbolDetailsRepository(db)
+}
+
+func Handler(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	summaries, err := summaryRepository.Find(ctx, repository.SummaryFilter{})
+	if err != nil {
+		log.Printf("Failure to read investments summary: %v", err)
+		return http_helper.JsonResponse(ErrorOutput{
+			Code:    "INTERNAL_ERROR",
+			Message: "failure to read investments summary",
+		}, http_helper.JsonResponseOptions{StatusCode: 500}), nil
+	}
+
+	details, err := symbolDetailsRepository.FindAll(ctx)
+	if err != nil {
+		log.Printf("Failure to read symbol details: %v", err)
+		return http_helper.JsonResponse(ErrorOutput{
+			Code:    "INTERNAL_ERROR",
+			Message: "failure to read symbol details",
+		}, http_helper.JsonResponseOptions{StatusCode: 500}), nil
+	}
+
+	segments := map[string]string{}
+	for _, detail := range details {
+		segments[detail.ID] = detail.Segment
+	}
+
+	return http_helper.JsonResponse(portfolio_core.Overview(summaries, segments, time.Now())), nil
+}
+
+func main() {
+	lambda.Start(Handler)
+}
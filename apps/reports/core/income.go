@@ -0,0 +1,126 @@
+package reports_core
+
+import (
+	"sort"
+
+	investment_core "github.com/silasstoffel/invest-tracker/apps/investments/core"
+	"github.com/silasstoffel/invest-tracker/apps/shared/decimal"
+)
+
+// IncomeLine sums the incomes of a symbol in a month. Amortizations return
+// invested capital, so they are shown apart and are not part of gross or net.
+type IncomeLine struct {
+	Period       string          `json:"period,omitempty"`
+	Symbol       string          `json:"symbol,omitempty"`
+	Type         string          `json:"type,omitempty"`
+	Incomes      int             `json:"incomes"`
+	Gross        decimal.Decimal `json:"gross"`
+	TaxWithheld  decimal.Decimal `json:"taxWithheld"`
+	Net          decimal.Decimal `json:"net"`
+	Amortization decimal.Decimal `json:"amortization"`
+	// gross income over the cost of the position held today (quantity times
+	// average price), from 0 to 100. Only for lines of a single symbol.
+	YieldOnCost decimal.Decimal `json:"yieldOnCost,omitzero"`
+}
+
+type IncomeReport struct {
+	// one line per month and symbol
+	Lines []IncomeLine `json:"lines"`
+	// one line per month with every symbol summed up
+	Months []IncomeLine `json:"months"`
+	// one line per symbol with the whole range summed up
+	Symbols []IncomeLine `json:"symbols"`
+	Total   IncomeLine   `json:"total"`
+}
+
+func newIncomeLine(period, symbol, investmentType string) IncomeLine {
+	return IncomeLine{
+		Period:       period,
+		Symbol:       symbol,
+		Type:         investmentType,
+		Gross:        decimal.Zero,
+		TaxWithheld:  decimal.Zero,
+		Net:          decimal.Zero,
+		Amortization: decimal.Zero,
+	}
+}
+
+func (l *IncomeLine) add(income investment_core.InvestmentEntity) {
+	l.Incomes++
+	if income.OperationSubtype == investment_core.AmortizationIncome {
+		l.Amortization = l.Amortization.Add(income.TotalValue)
+		return
+	}
+	l.Gross = l.Gross.Add(income.TotalValue)
+	l.TaxWithheld = l.TaxWithheld.Add(income.TaxWithheld)
+	l.Net = l.Gross.Sub(l.TaxWithheld)
+}
+
+func (l *IncomeLine) yieldOn(cost decimal.Decimal) {
+	if cost.IsPositive() {
+		l.YieldOnCost = l.Gross.Div(cost).Mul(decimal.NewFromInt(100)).Round(2)
+	}
+}
+
+// BuildIncomeReport sums the income operations by month and symbol. costs
+// maps each symbol to the cost of its open positions, used for the yield on cost.
+func BuildIncomeReport(incomes []investment_core.InvestmentEntity, costs map[string]decimal.Decimal) IncomeReport {
+	report := IncomeReport{Total: newIncomeLine("", "", "")}
+	lines := map[string]*IncomeLine{}
+	months := map[string]*IncomeLine{}
+	symbols := map[string]*IncomeLine{}
+
+	for _, income := range incomes {
+		month := periodOf(income.OperationDate, MonthlyPeriod)
+
+		key := month + "|" + income.Symbol
+		if _, ok := lines[key]; !ok {
+			line := newIncomeLine(month, income.Symbol, income.Type)
+			lines[key] = &line
+		}
+		lines[key].add(income)
+
+		if _, ok := months[month]; !ok {
+			line := newIncomeLine(month, "", "")
+			months[month] = &line
+		}
+		months[month].add(income)
+
+		if _, ok := symbols[income.Symbol]; !ok {
+			line := newIncomeLine("", income.Symbol, income.Type)
+			symbols[income.Symbol] = &line
+		}
+		symbols[income.Symbol].add(income)
+
+		report.Total.add(income)
+	}
+
+	for _, line := range lines {
+		line.yieldOn(costs[line.Symbol])
+	}
+	for _, line := range symbols {
+		line.yieldOn(costs[line.Symbol])
+	}
+
+	report.Lines = sortedIncomeLines(lines)
+	report.Months = sortedIncomeLines(months)
+	report.Symbols = sortedIncomeLines(symbols)
+
+	return report
+}
+
+func sortedIncomeLines(lines map[string]*IncomeLine) []IncomeLine {
+	result := make([]IncomeLine, 0, len(lines))
+	for _, line := range lines {
+		result = append(result, *line)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Period != result[j].Period {
+			return result[i].Period < result[j].Period
+		}
+		return result[i].Symbol < result[j].Symbol
+	})
+
+	return result
+}
@@ -0,0 +1,58 @@
+package reports_core
+
+import (
+	"testing"
+
+	investment_core "github.com/silasstoffel/invest-tracker/apps/investments/core"
+	"github.com/silasstoffel/invest-tracker/apps/shared/decimal"
+)
+
+func TestBuildIncomeReport(t *testing.T) {
+	income := func(date, symbol, subtype string, value, withheld int64) investment_core.InvestmentEntity {
+		return investment_core.InvestmentEntity{
+			Symbol:           symbol,
+			Type:             investment_core.StockInvestmentType,
+			OperationType:    investment_core.IncomeOperationType,
+			OperationSubtype: subtype,
+			OperationDate:    date,
+			TotalValue:       decimal.NewFromInt(value),
+			TaxWithheld:      decimal.NewFromInt(withheld),
+		}
+	}
+
+	incomes := []investment_core.InvestmentEntity{
+		income("2025-01-15", "ITSA4", investment_core.DividendIncome, 20, 0),
+		income("2025-01-20", "ITSA4", investment_core.JcpIncome, 40, 6),
+		income("2025-02-15", "ITSA4", investment_core.DividendIncome, 20, 0),
+		income("2025-02-10", "HGLG11", investment_core.AmortizationIncome, 30, 0),
+	}
+	costs := map[string]decimal.Decimal{"ITSA4": decimal.NewFromInt(1000)}
+
+	report := BuildIncomeReport(incomes, costs)
+
+	if len(report.Lines) != 3 || len(report.Months) != 2 || len(report.Symbols) != 2 {
+		t.Fatalf("expected 3 lines, 2 months and 2 symbols, got %+v", report)
+	}
+
+	january := report.Lines[0]
+	if january.Symbol != "ITSA4" || !january.Gross.Equal(decimal.NewFromInt(60)) || !january.Net.Equal(decimal.NewFromInt(54)) {
+		t.Errorf("expected ITSA4 to receive 60 gross and 54 net in january, got %+v", january)
+	}
+	if !january.YieldOnCost.Equal(decimal.NewFromInt(6)) {
+		t.Errorf("expected a yield on cost of 6%%, got %s", january.YieldOnCost)
+	}
+
+	itsa := report.Symbols[1]
+	if itsa.Incomes != 3 || !itsa.YieldOnCost.Equal(decimal.NewFromInt(8)) {
+		t.Errorf("expected 3 incomes of ITSA4 with a yield on cost of 8%%, got %+v", itsa)
+	}
+
+	hglg := report.Symbols[0]
+	if !hglg.Amortization.Equal(decimal.NewFromInt(30)) || !hglg.Gross.IsZero() || !hglg.YieldOnCost.IsZero() {
+		t.Errorf("expected the amortization apart from the income, got %+v", hglg)
+	}
+
+	if !report.Total.Net.Equal(decimal.NewFromInt(74)) || !report.Total.TaxWithheld.Equal(decimal.NewFromInt(6)) {
+		t.Errorf("expected 74 net and 6 withheld in total, got %+v", report.Total)
+	}
+}
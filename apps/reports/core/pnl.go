@@ -0,0 +1,239 @@
+package reports_core
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	investment_core "github.com/silasstoffel/invest-tracker/apps/investments/core"
+	"github.com/silasstoffel/invest-tracker/apps/shared/decimal"
+)
+
+const (
+	// report periods
+	MonthlyPeriod = "month"
+	YearlyPeriod  = "year"
+
+	// report dimensions
+	GroupByType      = "type"
+	GroupBySymbol    = "symbol"
+	GroupByBrokerage = "brokerage"
+)
+
+// RealizedSale is the result of a single sell or bond redemption.
+type RealizedSale struct {
+	InvestmentID  string          `json:"investmentId"`
+	OperationDate string          `json:"operationDate"`
+	Type          string          `json:"type"`
+	Symbol        string          `json:"symbol"`
+	Brokerage     string          `json:"brokerage"`
+	Proceeds      decimal.Decimal `json:"proceeds"`
+	CostBasis     decimal.Decimal `json:"costBasis"`
+	Fees          decimal.Decimal `json:"fees"`
+	// income tax and IOF withheld at source on bond redemptions
+	TaxWithheld decimal.Decimal `json:"taxWithheld"`
+	GrossResult decimal.Decimal `json:"grossResult"`
+	NetResult   decimal.Decimal `json:"netResult"`
+	// bought and sold in the same day and brokerage
+	DayTrade bool `json:"dayTrade"`
+}
+
+type PnlLine struct {
+	Period      string          `json:"period"`
+	Type        string          `json:"type,omitempty"`
+	Symbol      string          `json:"symbol,omitempty"`
+	Brokerage   string          `json:"brokerage,omitempty"`
+	Sales       int             `json:"sales"`
+	Proceeds    decimal.Decimal `json:"proceeds"`
+	CostBasis   decimal.Decimal `json:"costBasis"`
+	Fees        decimal.Decimal `json:"fees"`
+	TaxWithheld decimal.Decimal `json:"taxWithheld"`
+	GrossResult decimal.Decimal `json:"grossResult"`
+	NetResult   decimal.Decimal `json:"netResult"`
+	// part of the net result made in day trades
+	DayTradeResult decimal.Decimal `json:"dayTradeResult"`
+}
+
+type PnlReport struct {
+	Period  string    `json:"period"`
+	GroupBy []string  `json:"groupBy"`
+	Lines   []PnlLine `json:"lines"`
+	// one line per period with every dimension summed up
+	Periods []PnlLine `json:"periods"`
+	Total   PnlLine   `json:"total"`
+}
+
+// Realize computes the result of a sell. Fii/stock/reit/etf sells use the pnl
+// stored by calculate-average-price and are split in a swing trade and a day
+// trade part when they were partly closed against purchases of the same day.
+// Bond redemptions are compared with the original purchase, proportionally to
+// the quantity redeemed and their net result is after the taxes withheld at
+// source. Fees are the costs of the sell operation.
+func Realize(sale investment_core.InvestmentEntity, purchase *investment_core.InvestmentEntity) []RealizedSale {
+	base := RealizedSale{
+		InvestmentID:  sale.ID,
+		OperationDate: sale.OperationDate,
+		Type:          sale.Type,
+		Symbol:        sale.Symbol,
+		Brokerage:     sale.Brokerage,
+		Proceeds:      sale.TotalValue.Money(),
+		Fees:          sale.Cost.Money(),
+		TaxWithheld:   decimal.Zero,
+	}
+
+	if sale.Type == investment_core.BondInvestmentType {
+		base.CostBasis = decimal.Zero
+		if purchase != nil {
+			base.CostBasis = purchase.TotalValue
+			if purchase.Quantity.GreaterThan(sale.Quantity) {
+				base.CostBasis = purchase.TotalValue.Mul(sale.Quantity).Div(purchase.Quantity)
+			}
+		}
+		base.CostBasis = base.CostBasis.Money()
+		base.TaxWithheld = sale.TaxWithheld.Add(sale.Iof).Money()
+		base.GrossResult = base.Proceeds.Sub(base.CostBasis)
+		base.NetResult = base.GrossResult.Sub(base.Fees).Sub(base.TaxWithheld)
+		return []RealizedSale{base}
+	}
+
+	realized := []RealizedSale{}
+	swing := base
+
+	if sale.DayTradeQuantity.IsPositive() {
+		share := sale.DayTradeQuantity.Div(sale.Quantity)
+		dayTrade := base
+		dayTrade.DayTrade = true
+		dayTrade.Proceeds = base.Proceeds.Mul(share).Money()
+		dayTrade.Fees = base.Fees.Mul(share).Money()
+		dayTrade.GrossResult = sale.DayTradePnl.Money()
+		dayTrade.CostBasis = dayTrade.Proceeds.Sub(dayTrade.GrossResult)
+		dayTrade.NetResult = dayTrade.GrossResult.Sub(dayTrade.Fees)
+		realized = append(realized, dayTrade)
+
+		swing.Proceeds = base.Proceeds.Sub(dayTrade.Proceeds)
+		swing.Fees = base.Fees.Sub(dayTrade.Fees)
+		if !sale.Quantity.GreaterThan(sale.DayTradeQuantity) {
+			return realized
+		}
+	}
+
+	swing.GrossResult = sale.Pnl.Money()
+	swing.CostBasis = swing.Proceeds.Sub(swing.GrossResult)
+	swing.NetResult = swing.GrossResult.Sub(swing.Fees)
+
+	return append([]RealizedSale{swing}, realized...)
+}
+
+func CheckPnlReport(period string, groupBy []string) error {
+	if period != MonthlyPeriod && period != YearlyPeriod {
+		return fmt.Errorf("invalid period %s, use %s or %s", period, MonthlyPeriod, YearlyPeriod)
+	}
+
+	for _, dimension := range groupBy {
+		switch dimension {
+		case GroupByType, GroupBySymbol, GroupByBrokerage:
+		default:
+			return fmt.Errorf("invalid group %s, use %s, %s or %s", dimension, GroupByType, GroupBySymbol, GroupByBrokerage)
+		}
+	}
+
+	return nil
+}
+
+func newLine(period string) PnlLine {
+	return PnlLine{
+		Period:         period,
+		Proceeds:       decimal.Zero,
+		CostBasis:      decimal.Zero,
+		Fees:           decimal.Zero,
+		TaxWithheld:    decimal.Zero,
+		GrossResult:    decimal.Zero,
+		NetResult:      decimal.Zero,
+		DayTradeResult: decimal.Zero,
+	}
+}
+
+func (l *PnlLine) add(sale RealizedSale) {
+	l.Sales++
+	l.Proceeds = l.Proceeds.Add(sale.Proceeds)
+	l.CostBasis = l.CostBasis.Add(sale.CostBasis)
+	l.Fees = l.Fees.Add(sale.Fees)
+	l.TaxWithheld = l.TaxWithheld.Add(sale.TaxWithheld)
+	l.GrossResult = l.GrossResult.Add(sale.GrossResult)
+	l.NetResult = l.NetResult.Add(sale.NetResult)
+	if sale.DayTrade {
+		l.DayTradeResult = l.DayTradeResult.Add(sale.NetResult)
+	}
+}
+
+func periodOf(date string, period string) string {
+	if period == YearlyPeriod {
+		return date[:4]
+	}
+	return date[:7]
+}
+
+// BuildPnlReport sums the sales by period (month or year) and by the given dimensions.
+func BuildPnlReport(sales []RealizedSale, period string, groupBy []string) PnlReport {
+	report := PnlReport{Period: period, GroupBy: groupBy, Total: newLine("")}
+	lines := map[string]*PnlLine{}
+	periods := map[string]*PnlLine{}
+
+	for _, sale := range sales {
+		key := periodOf(sale.OperationDate, period)
+		line := newLine(key)
+
+		for _, dimension := range groupBy {
+			switch dimension {
+			case GroupByType:
+				line.Type = sale.Type
+			case GroupBySymbol:
+				line.Symbol = sale.Symbol
+			case GroupByBrokerage:
+				line.Brokerage = sale.Brokerage
+			}
+		}
+
+		lineKey := strings.Join([]string{key, line.Type, line.Symbol, line.Brokerage}, "|")
+		if _, ok := lines[lineKey]; !ok {
+			lines[lineKey] = &line
+		}
+		lines[lineKey].add(sale)
+
+		if _, ok := periods[key]; !ok {
+			periodLine := newLine(key)
+			periods[key] = &periodLine
+		}
+		periods[key].add(sale)
+
+		report.Total.add(sale)
+	}
+
+	report.Lines = sortedLines(lines)
+	report.Periods = sortedLines(periods)
+
+	return report
+}
+
+func sortedLines(lines map[string]*PnlLine) []PnlLine {
+	result := make([]PnlLine, 0, len(lines))
+	for _, line := range lines {
+		result = append(result, *line)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		a, b := result[i], result[j]
+		if a.Period != b.Period {
+			return a.Period < b.Period
+		}
+		if a.Type != b.Type {
+			return a.Type < b.Type
+		}
+		if a.Symbol != b.Symbol {
+			return a.Symbol < b.Symbol
+		}
+		return a.Brokerage < b.Brokerage
+	})
+
+	return result
+}
@@ -0,0 +1,101 @@
+package reports_core
+
+import (
+	"testing"
+
+	investment_core "github.com/silasstoffel/invest-tracker/apps/investments/core"
+	"github.com/silasstoffel/invest-tracker/apps/shared/decimal"
+)
+
+func TestRealizeBondRedemption(t *testing.T) {
+	purchase := investment_core.InvestmentEntity{
+		ID:         "purchase",
+		Type:       investment_core.BondInvestmentType,
+		Quantity:   decimal.NewFromInt(2),
+		TotalValue: decimal.NewFromInt(2000),
+	}
+	sale := investment_core.InvestmentEntity{
+		ID:               "sale",
+		Type:             investment_core.BondInvestmentType,
+		OperationDate:    "2025-03-10",
+		Quantity:         decimal.NewFromInt(1),
+		TotalValue:       decimal.NewFromInt(1150),
+		Cost:             decimal.NewFromInt(10),
+		SellInvestmentId: purchase.ID,
+		TaxWithheld:      decimal.NewFromInt(30),
+		Iof:              decimal.NewFromInt(5),
+	}
+
+	realized := Realize(sale, &purchase)[0]
+
+	if !realized.CostBasis.Equal(decimal.NewFromInt(1000)) {
+		t.Errorf("expected half of the purchase as cost basis, got %s", realized.CostBasis)
+	}
+	if !realized.GrossResult.Equal(decimal.NewFromInt(150)) || !realized.NetResult.Equal(decimal.NewFromInt(105)) {
+		t.Errorf("expected gross 150 and net 105 after fees and taxes, got %s and %s", realized.GrossResult, realized.NetResult)
+	}
+}
+
+func TestBuildPnlReport(t *testing.T) {
+	sales := []RealizedSale{}
+	for _, sale := range []investment_core.InvestmentEntity{
+		{
+			Type: "stock", Symbol: "VALE3", Brokerage: "xp", OperationDate: "2025-01-10",
+			TotalValue: decimal.NewFromInt(700), Pnl: decimal.NewFromInt(100), Cost: decimal.NewFromInt(5),
+		},
+		{
+			Type: "stock", Symbol: "VALE3", Brokerage: "inter", OperationDate: "2025-01-20",
+			TotalValue: decimal.NewFromInt(500), Pnl: decimal.NewFromInt(-50),
+		},
+		{
+			Type: "fii", Symbol: "HGLG11", Brokerage: "xp", OperationDate: "2025-02-03",
+			TotalValue: decimal.NewFromInt(300), Pnl: decimal.NewFromInt(20),
+		},
+	} {
+		sales = append(sales, Realize(sale, nil)...)
+	}
+
+	report := BuildPnlReport(sales, MonthlyPeriod, []string{GroupBySymbol})
+
+	if len(report.Lines) != 2 || report.Lines[0].Period != "2025-01" || report.Lines[0].Sales != 2 {
+		t.Fatalf("unexpected lines: %+v", report.Lines)
+	}
+	if !report.Lines[0].GrossResult.Equal(decimal.NewFromInt(50)) || !report.Lines[0].NetResult.Equal(decimal.NewFromInt(45)) {
+		t.Errorf("expected gross 50 and net 45 in january, got %s and %s", report.Lines[0].GrossResult, report.Lines[0].NetResult)
+	}
+	if !report.Lines[0].CostBasis.Equal(decimal.NewFromInt(1150)) {
+		t.Errorf("expected cost basis 1150 in january, got %s", report.Lines[0].CostBasis)
+	}
+
+	yearly := BuildPnlReport(sales, YearlyPeriod, nil)
+	if len(yearly.Lines) != 1 || yearly.Lines[0].Period != "2025" || !yearly.Total.NetResult.Equal(decimal.NewFromInt(65)) {
+		t.Errorf("unexpected yearly report: %+v", yearly)
+	}
+}
+
+func TestRealizeDayTrade(t *testing.T) {
+	// 10 sold at 12, 4 of them bought the same day at 11
+	sale := investment_core.InvestmentEntity{
+		Type:             "stock",
+		OperationDate:    "2025-03-10",
+		Quantity:         decimal.NewFromInt(10),
+		TotalValue:       decimal.NewFromInt(120),
+		Cost:             decimal.NewFromInt(10),
+		Pnl:              decimal.NewFromInt(12),
+		DayTradeQuantity: decimal.NewFromInt(4),
+		DayTradePnl:      decimal.NewFromInt(4),
+	}
+
+	realized := Realize(sale, nil)
+	if len(realized) != 2 {
+		t.Fatalf("expected a swing and a day trade part, got %d", len(realized))
+	}
+
+	swing, dayTrade := realized[0], realized[1]
+	if swing.DayTrade || !swing.Proceeds.Equal(decimal.NewFromInt(72)) || !swing.NetResult.Equal(decimal.NewFromInt(6)) {
+		t.Errorf("unexpected swing trade part: %+v", swing)
+	}
+	if !dayTrade.DayTrade || !dayTrade.Proceeds.Equal(decimal.NewFromInt(48)) || !dayTrade.CostBasis.Equal(decimal.NewFromInt(44)) || !dayTrade.NetResult.Equal(decimal.NewFromInt(0)) {
+		t.Errorf("unexpected day trade part: %+v", dayTrade)
+	}
+}
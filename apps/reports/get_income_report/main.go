@@ -0,0 +1,91 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambda"
+	reports_service "github.com/silasstoffel/invest-tracker/apps/reports/service"
+	"github.com/silasstoffel/invest-tracker/apps/shared/database"
+	http_helper "github.com/silasstoffel/invest-tracker/apps/shared/http_helpers"
+	"github.com/silasstoffel/invest-tracker/apps/shared/repository"
+	appConfig "github.com/silasstoffel/invest-tracker/config"
+)
+
+type ErrorOutput struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+var (
+	env           *appConfig.Config
+	incomeService *reports_service.IncomeService
+)
+
+func init() {
+	env = appConfig.NewConfigFromEnvVars()
+
+	db, err := database.NewFromConfig(env)
+	if err != nil {
+		m := fmt.Sprintf("Failure to connect to database: %v", err)
+		log.Println(m)
+		panic(m)
+	}
+	incomeService = reports_service.NewIncomeService(
+		repository.NewInvestmentRepository(db),
+		repository.NewSummaryRepository(db),
+	)
+}
+
+func badRequest(message string) events.APIGatewayProxyResponse {
+	return http_helper.JsonResponse(ErrorOutput{
+		Code:    "INVALID_REQUEST",
+		Message: message,
+	}, http_helper.JsonResponseOptions{StatusCode: 400})
+}
+
+func Handler(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	params := request.QueryStringParameters
+
+	filter := repository.InvestmentFilter{
+		Symbol:    strings.TrimSpace(params["symbol"]),
+		Type:      strings.ToLower(strings.TrimSpace(params["type"])),
+		Brokerage: strings.TrimSpace(params["brokerage"]),
+		From:      strings.TrimSpace(params["from"]),
+		To:        strings.TrimSpace(params["to"]),
+	}
+
+	for name, value := range map[string]string{"from": filter.From, "to": filter.To} {
+		if _, err := time.Parse("2006-01-02", value); value != "" && err != nil {
+			return badRequest(fmt.Sprintf("%s must be in the format YYYY-MM-DD", name)), nil
+		}
+	}
+
+	if year := strings.TrimSpace(params["year"]); year != "" {
+		y, err := strconv.Atoi(year)
+		if err != nil {
+			return badRequest("year must be a number"), nil
+		}
+		filter.Year = y
+	}
+
+	report, err := incomeService.IncomeReport(ctx, filter)
+	if err != nil {
+		log.Printf("Failure to build income report: %v", err)
+		return http_helper.JsonResponse(ErrorOutput{
+			Code:    "INTERNAL_ERROR",
+			Message: "failure to build income report",
+		}, http_helper.JsonResponseOptions{StatusCode: 500}), nil
+	}
+
+	return http_helper.JsonResponse(report), nil
+}
+
+func main() {
+	lambda.Start(Handler)
+}
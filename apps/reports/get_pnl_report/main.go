@@ -0,0 +1,103 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambda"
+	reports_core "github.com/silasstoffel/invest-tracker/apps/reports/core"
+	reports_service "github.com/silasstoffel/invest-tracker/apps/reports/service"
+	"github.com/silasstoffel/invest-tracker/apps/shared/database"
+	http_helper "github.com/silasstoffel/invest-tracker/apps/shared/http_helpers"
+	"github.com/silasstoffel/invest-tracker/apps/shared/repository"
+	appConfig "github.com/silasstoffel/invest-tracker/config"
+)
+
+type ErrorOutput struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+var (
+	env            *appConfig.Config
+	reportsService *reports_service.Service
+)
+
+func init() {
+	env = appConfig.NewConfigFromEnvVars()
+
+	db, err := database.NewFromConfig(env)
+	if err != nil {
+		m := fmt.Sprintf("Failure to connect to database: %v", err)
+		log.Println(m)
+		panic(m)
+	}
+	reportsService = reports_service.NewService(repository.NewInvestmentRepository(db))
+}
+
+func badRequest(message string) events.APIGatewayProxyResponse {
+	return http_helper.JsonResponse(ErrorOutput{
+		Code:    "INVALID_REQUEST",
+		Message: message,
+	}, http_helper.JsonResponseOptions{StatusCode: 400})
+}
+
+func Handler(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	params := request.QueryStringParameters
+
+	filter := repository.InvestmentFilter{
+		Symbol:    strings.TrimSpace(params["symbol"]),
+		Type:      strings.ToLower(strings.TrimSpace(params["type"])),
+		Brokerage: strings.TrimSpace(params["brokerage"]),
+		From:      strings.TrimSpace(params["from"]),
+		To:        strings.TrimSpace(params["to"]),
+	}
+
+	for name, value := range map[string]string{"from": filter.From, "to": filter.To} {
+		if _, err := time.Parse("2006-01-02", value); value != "" && err != nil {
+			return badRequest(fmt.Sprintf("%s must be in the format YYYY-MM-DD", name)), nil
+		}
+	}
+
+	if year := strings.TrimSpace(params["year"]); year != "" {
+		y, err := strconv.Atoi(year)
+		if err != nil {
+			return badRequest("year must be a number"), nil
+		}
+		filter.Year = y
+	}
+
+	period := strings.ToLower(strings.TrimSpace(params["period"]))
+	if period == "" {
+		period = reports_core.MonthlyPeriod
+	}
+
+	groupBy := []string{reports_core.GroupByType, reports_core.GroupBySymbol, reports_core.GroupByBrokerage}
+	if value := strings.TrimSpace(params["groupBy"]); value != "" {
+		groupBy = strings.Split(strings.ToLower(value), ",")
+	}
+
+	if err := reports_core.CheckPnlReport(period, groupBy); err != nil {
+		return badRequest(err.Error()), nil
+	}
+
+	report, err := reportsService.PnlReport(ctx, filter, period, groupBy)
+	if err != nil {
+		log.Printf("Failure to build pnl report: %v", err)
+		return http_helper.JsonResponse(ErrorOutput{
+			Code:    "INTERNAL_ERROR",
+			Message: "failure to build profit and loss report",
+		}, http_helper.JsonResponseOptions{StatusCode: 500}), nil
+	}
+
+	return http_helper.JsonResponse(report), nil
+}
+
+func main() {
+	lambda.Start(Handler)
+}
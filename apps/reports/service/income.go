@@ -0,0 +1,49 @@
+package reports_service
+
+import (
+	"context"
+	"fmt"
+
+	investment_core "github.com/silasstoffel/invest-tracker/apps/investments/core"
+	reports_core "github.com/silasstoffel/invest-tracker/apps/reports/core"
+	"github.com/silasstoffel/invest-tracker/apps/shared/decimal"
+	"github.com/silasstoffel/invest-tracker/apps/shared/repository"
+)
+
+type IncomeService struct {
+	investments repository.InvestmentRepository
+	summaries   repository.SummaryRepository
+}
+
+func NewIncomeService(investments repository.InvestmentRepository, summaries repository.SummaryRepository) *IncomeService {
+	return &IncomeService{investments: investments, summaries: summaries}
+}
+
+// IncomeReport sums the incomes matching the filter by month and symbol, with
+// the yield on cost against the positions held today.
+func (s *IncomeService) IncomeReport(ctx context.Context, filter repository.InvestmentFilter) (reports_core.IncomeReport, error) {
+	filter.OperationType = investment_core.IncomeOperationType
+
+	incomes, err := s.investments.Find(ctx, filter)
+	if err != nil {
+		return reports_core.IncomeReport{}, fmt.Errorf("failure to read incomes: %w", err)
+	}
+
+	positions, err := s.summaries.Find(ctx, repository.SummaryFilter{
+		Symbol:    filter.Symbol,
+		Type:      filter.Type,
+		Brokerage: filter.Brokerage,
+	})
+	if err != nil {
+		return reports_core.IncomeReport{}, fmt.Errorf("failure to read positions: %w", err)
+	}
+
+	costs := map[string]decimal.Decimal{}
+	for _, position := range positions {
+		if position.Quantity.IsPositive() {
+			costs[position.Symbol] = costs[position.Symbol].Add(position.TotalValue)
+		}
+	}
+
+	return reports_core.BuildIncomeReport(incomes, costs), nil
+}
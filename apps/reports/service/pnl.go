@@ -0,0 +1,77 @@
+package reports_service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+
+	investment_core "github.com/silasstoffel/invest-tracker/apps/investments/core"
+	reports_core "github.com/silasstoffel/invest-tracker/apps/reports/core"
+	"github.com/silasstoffel/invest-tracker/apps/shared/repository"
+)
+
+type Service struct {
+	investments repository.InvestmentRepository
+}
+
+func NewService(investments repository.InvestmentRepository) *Service {
+	return &Service{investments: investments}
+}
+
+// RealizedSales returns the result of every sell matching the filter, bond
+// redemptions are measured against the purchase in sell_investment_id. The
+// fractions sold after a corporate action (cash in lieu) count as sells too.
+func (s *Service) RealizedSales(ctx context.Context, filter repository.InvestmentFilter) ([]reports_core.RealizedSale, error) {
+	filter.OperationType = investment_core.SellOperationType
+
+	sales, err := s.investments.Find(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("failure to read sell operations: %w", err)
+	}
+
+	filter.OperationType = investment_core.CorporateActionOperationType
+	actions, err := s.investments.Find(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("failure to read corporate actions: %w", err)
+	}
+	for _, action := range actions {
+		if action.TotalValue.IsPositive() || !action.Pnl.IsZero() {
+			sales = append(sales, action)
+		}
+	}
+
+	realized := make([]reports_core.RealizedSale, 0, len(sales))
+	for _, sale := range sales {
+		var purchase *investment_core.InvestmentEntity
+
+		if sale.Type == investment_core.BondInvestmentType {
+			p, err := s.investments.FindByID(ctx, sale.SellInvestmentId)
+			if err != nil && !errors.Is(err, repository.ErrNotFound) {
+				return nil, fmt.Errorf("failure to read purchase %s of sell %s: %w", sale.SellInvestmentId, sale.ID, err)
+			}
+			if err == nil {
+				purchase = &p
+			} else {
+				log.Printf("Purchase %s of bond sell %s not found, the whole redemption counts as result", sale.SellInvestmentId, sale.ID)
+			}
+		}
+
+		realized = append(realized, reports_core.Realize(sale, purchase)...)
+	}
+
+	return realized, nil
+}
+
+func (s *Service) PnlReport(ctx context.Context, filter repository.InvestmentFilter, period string, groupBy []string) (reports_core.PnlReport, error) {
+	if err := reports_core.CheckPnlReport(period, groupBy); err != nil {
+		return reports_core.PnlReport{}, err
+	}
+
+	sales, err := s.RealizedSales(ctx, filter)
+	if err != nil {
+		return reports_core.PnlReport{}, err
+	}
+
+	return reports_core.BuildPnlReport(sales, period, groupBy), nil
+}
@@ -0,0 +1,92 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/cloudflare/cloudflare-go/v4"
+	"github.com/cloudflare/cloudflare-go/v4/d1"
+)
+
+type D1 struct {
+	client     *cloudflare.Client
+	accountId  string
+	databaseId string
+}
+
+func NewD1(cfClient *cloudflare.Client, accountId, databaseId string) *D1 {
+	return &D1{
+		client:     cfClient,
+		accountId:  accountId,
+		databaseId: databaseId,
+	}
+}
+
+func (d *D1) Query(ctx context.Context, command string, params ...string) ([]Row, error) {
+	res, err := d.client.D1.Database.Query(ctx, d.databaseId, d1.DatabaseQueryParams{
+		AccountID: cloudflare.F(d.accountId),
+		Sql:       cloudflare.F(command),
+		Params:    cloudflare.F(params),
+	})
+
+	if err != nil {
+		log.Printf("Failure do execute command: %s. Params: %v Detail: %v", command, params, err)
+		return nil, err
+	}
+
+	rows := []Row{}
+	if len(res.Result) == 0 {
+		return rows, nil
+	}
+
+	for _, result := range res.Result[0].Results {
+		row, ok := result.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("unexpected row format: %T", result)
+		}
+		rows = append(rows, Row(row))
+	}
+
+	return rows, nil
+}
+
+func (d *D1) Exec(ctx context.Context, command string, params ...string) error {
+	_, err := d.client.D1.Database.Raw(ctx, d.databaseId, d1.DatabaseRawParams{
+		AccountID: cloudflare.F(d.accountId),
+		Sql:       cloudflare.F(command),
+		Params:    cloudflare.F(params),
+	})
+
+	if err != nil {
+		m := fmt.Sprintf("Error executing command on cloudflare. Detail: %v", err)
+		log.Print(m)
+		log.Printf("Command: %s", command)
+		log.Printf("Params: %v", params)
+		return errors.New(m)
+	}
+
+	return nil
+}
+
+// Batch joins the statements with semicolons, which D1 executes as a single
+// implicit transaction. Positional parameters are bound in statement order.
+// A batch over the D1 limit of bound parameters is refused rather than split,
+// so it stays all or nothing.
+func (d *D1) Batch(ctx context.Context, statements []Statement) error {
+	commands := make([]string, 0, len(statements))
+	params := []string{}
+
+	for _, statement := range statements {
+		commands = append(commands, strings.TrimSuffix(strings.TrimSpace(statement.Sql), ";"))
+		params = append(params, statement.Params...)
+	}
+
+	if err := checkBatchParams(params); err != nil {
+		return err
+	}
+
+	return d.Exec(ctx, strings.Join(commands, ";\n"), params...)
+}
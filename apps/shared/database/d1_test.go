@@ -0,0 +1,55 @@
+package database
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/cloudflare/cloudflare-go/v4"
+	"github.com/cloudflare/cloudflare-go/v4/option"
+)
+
+func TestD1BatchParameterLimit(t *testing.T) {
+	calls := []int{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Params []string `json:"params"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("failure to read request: %v", err)
+		}
+		calls = append(calls, len(body.Params))
+
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"success": true, "errors": [], "messages": [], "result": []}`)
+	}))
+	defer server.Close()
+
+	client := cloudflare.NewClient(option.WithBaseURL(server.URL), option.WithAPIToken("token"), option.WithMaxRetries(0))
+	db := NewD1(client, "account", "database")
+
+	// a delete and 5 history rows of 18 parameters run as a single query
+	statements := []Statement{{Sql: "DELETE FROM history WHERE id = ? AND type IS NOT ?", Params: []string{"1", "valuation"}}}
+	for range 5 {
+		statements = append(statements, Statement{Sql: "INSERT INTO history VALUES (?)", Params: make([]string, 18)})
+	}
+
+	if err := db.Batch(context.Background(), statements); err != nil {
+		t.Fatalf("failure to run batch: %v", err)
+	}
+	if len(calls) != 1 || calls[0] != 2+5*18 {
+		t.Errorf("expected the 92 parameters in a single call, got %v", calls)
+	}
+
+	// splitting a larger batch would lose its atomicity, so it is refused
+	statements = append(statements, Statement{Sql: "INSERT INTO history VALUES (?)", Params: make([]string, 18)})
+	if err := db.Batch(context.Background(), statements); err == nil {
+		t.Error("expected a batch over the limit to fail")
+	}
+	if len(calls) != 1 {
+		t.Errorf("expected nothing sent for a batch over the limit, got %v", calls)
+	}
+}
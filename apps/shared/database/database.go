@@ -0,0 +1,73 @@
+package database
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	client "github.com/silasstoffel/invest-tracker/apps/shared/clients"
+	appConfig "github.com/silasstoffel/invest-tracker/config"
+)
+
+const (
+	D1Driver     = "d1"
+	SQLiteDriver = "sqlite"
+)
+
+// Row is a single result row keyed by column name.
+type Row map[string]interface{}
+
+type Statement struct {
+	Sql    string
+	Params []string
+}
+
+// DB is the minimal surface the repositories need. Cloudflare D1 and a local
+// sqlite file speak the same SQL dialect, so the same commands run on both.
+type DB interface {
+	Query(ctx context.Context, command string, params ...string) ([]Row, error)
+	Exec(ctx context.Context, command string, params ...string) error
+	// Batch runs the statements in order, atomically. Batches over
+	// maxBatchParams bound parameters are refused on both drivers.
+	Batch(ctx context.Context, statements []Statement) error
+}
+
+// maxBatchParams is the limit of bound parameters of a single D1 query, a
+// batch included. sqlite enforces it too, so local runs fail the same way.
+const maxBatchParams = 100
+
+func checkBatchParams(params []string) error {
+	if len(params) > maxBatchParams {
+		return fmt.Errorf("batch binds %d parameters, the limit is %d", len(params), maxBatchParams)
+	}
+	return nil
+}
+
+// NewFromConfig returns a D1 database unless DATABASE_DRIVER selects sqlite.
+func NewFromConfig(env *appConfig.Config) (DB, error) {
+	switch env.Database.Driver {
+	case SQLiteDriver:
+		return NewSQLite(env.Database.SQLitePath)
+	case D1Driver, "":
+		clients := client.CreateNewClients()
+		clients.InitCloudflare(env.Cloudflare.ApiKey)
+		return NewD1(clients.CloudflareClient, env.Cloudflare.AccountId, env.Cloudflare.InvestmentTrackDbId), nil
+	default:
+		return nil, fmt.Errorf("unsupported database driver: %s", env.Database.Driver)
+	}
+}
+
+// Scan decodes rows into dst (a pointer to a slice of structs) using the
+// json tags of the struct, which must match the column names.
+func Scan(rows []Row, dst interface{}) error {
+	jsonRows, err := json.Marshal(rows)
+	if err != nil {
+		return fmt.Errorf("failed to marshal rows to JSON: %w", err)
+	}
+
+	if err := json.Unmarshal(jsonRows, dst); err != nil {
+		return fmt.Errorf("failed to unmarshal rows to struct: %w", err)
+	}
+
+	return nil
+}
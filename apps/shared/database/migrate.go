@@ -0,0 +1,182 @@
+package database
+
+import (
+	"context"
+	"embed"
+	"fmt"
+	"io/fs"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
+
+const createMigrationsTable = `CREATE TABLE IF NOT EXISTS schema_migrations (
+    version INTEGER PRIMARY KEY,
+    name TEXT NOT NULL,
+    applied_at TEXT NOT NULL DEFAULT (datetime('now'))
+)`
+
+// Migration is a numbered file in migrations/, named <version>_<name>.sql.
+type Migration struct {
+	Version int
+	Name    string
+	Sql     string
+}
+
+type MigrationStatus struct {
+	Migration
+	AppliedAt string
+}
+
+func (m MigrationStatus) Applied() bool {
+	return m.AppliedAt != ""
+}
+
+// Migrations returns the embedded migrations sorted by version.
+func Migrations() ([]Migration, error) {
+	files, err := fs.Glob(migrationFiles, "migrations/*.sql")
+	if err != nil {
+		return nil, err
+	}
+
+	migrations := []Migration{}
+	seen := map[int]string{}
+
+	for _, file := range files {
+		base := strings.TrimSuffix(strings.TrimPrefix(file, "migrations/"), ".sql")
+		prefix, name, found := strings.Cut(base, "_")
+		version, err := strconv.Atoi(prefix)
+		if !found || err != nil {
+			return nil, fmt.Errorf("invalid migration file name: %s", file)
+		}
+
+		if other, ok := seen[version]; ok {
+			return nil, fmt.Errorf("duplicated migration version %d: %s and %s", version, other, file)
+		}
+		seen[version] = file
+
+		content, err := migrationFiles.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+
+		migrations = append(migrations, Migration{Version: version, Name: name, Sql: string(content)})
+	}
+
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+
+	return migrations, nil
+}
+
+func appliedMigrations(ctx context.Context, db DB) (map[int]string, error) {
+	if err := db.Exec(ctx, createMigrationsTable); err != nil {
+		return nil, fmt.Errorf("failure to create schema_migrations table: %w", err)
+	}
+
+	rows, err := db.Query(ctx, "SELECT version, applied_at FROM schema_migrations")
+	if err != nil {
+		return nil, err
+	}
+
+	var applied []struct {
+		Version   int    `json:"version"`
+		AppliedAt string `json:"applied_at"`
+	}
+	if err := Scan(rows, &applied); err != nil {
+		return nil, err
+	}
+
+	versions := map[int]string{}
+	for _, a := range applied {
+		versions[a.Version] = a.AppliedAt
+	}
+
+	return versions, nil
+}
+
+// Status lists every known migration and when it was applied.
+func Status(ctx context.Context, db DB) ([]MigrationStatus, error) {
+	migrations, err := Migrations()
+	if err != nil {
+		return nil, err
+	}
+
+	applied, err := appliedMigrations(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]MigrationStatus, 0, len(migrations))
+	for _, m := range migrations {
+		statuses = append(statuses, MigrationStatus{Migration: m, AppliedAt: applied[m.Version]})
+	}
+
+	return statuses, nil
+}
+
+func recordMigration(m Migration) Statement {
+	return Statement{
+		Sql:    "INSERT INTO schema_migrations (version, name, applied_at) VALUES (?, ?, ?)",
+		Params: []string{strconv.Itoa(m.Version), m.Name, time.Now().UTC().Format(time.RFC3339)},
+	}
+}
+
+// Up applies every pending migration in order. Each migration runs in the same
+// batch as its schema_migrations record, so a failure leaves nothing half applied.
+func Up(ctx context.Context, db DB) ([]Migration, error) {
+	statuses, err := Status(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+
+	applied := []Migration{}
+	for _, status := range statuses {
+		if status.Applied() {
+			continue
+		}
+
+		log.Printf("Applying migration %04d_%s", status.Version, status.Name)
+		err := db.Batch(ctx, []Statement{
+			{Sql: status.Sql},
+			recordMigration(status.Migration),
+		})
+
+		if err != nil {
+			return applied, fmt.Errorf("failure to apply migration %04d_%s: %w", status.Version, status.Name, err)
+		}
+		applied = append(applied, status.Migration)
+	}
+
+	return applied, nil
+}
+
+// Baseline records every migration up to version as applied without running it.
+// It is meant for databases created by hand before the migrations existed.
+func Baseline(ctx context.Context, db DB, version int) ([]Migration, error) {
+	statuses, err := Status(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+
+	recorded := []Migration{}
+	for _, status := range statuses {
+		if status.Applied() || status.Version > version {
+			continue
+		}
+
+		record := recordMigration(status.Migration)
+		if err := db.Exec(ctx, record.Sql, record.Params...); err != nil {
+			return recorded, fmt.Errorf("failure to record migration %04d_%s: %w", status.Version, status.Name, err)
+		}
+		recorded = append(recorded, status.Migration)
+	}
+
+	return recorded, nil
+}
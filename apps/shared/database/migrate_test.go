@@ -0,0 +1,43 @@
+package database
+
+import (
+	"context"
+	"testing"
+)
+
+func TestUp(t *testing.T) {
+	ctx := context.Background()
+	db, err := NewSQLite(":memory:")
+	if err != nil {
+		t.Fatalf("failure to open database: %v", err)
+	}
+	defer db.Close()
+
+	migrations, err := Migrations()
+	if err != nil {
+		t.Fatalf("failure to read migrations: %v", err)
+	}
+
+	statuses, err := Status(ctx, db)
+	if err != nil {
+		t.Fatalf("failure to read status: %v", err)
+	}
+
+	if len(statuses) != len(migrations) {
+		t.Fatalf("expected %d migrations, got %d", len(migrations), len(statuses))
+	}
+
+	for _, status := range statuses {
+		if !status.Applied() {
+			t.Errorf("expected migration %04d_%s to be applied", status.Version, status.Name)
+		}
+	}
+
+	applied, err := Up(ctx, db)
+	if err != nil {
+		t.Fatalf("failure to run migrations again: %v", err)
+	}
+	if len(applied) != 0 {
+		t.Errorf("expected no pending migration, got %d", len(applied))
+	}
+}
@@ -0,0 +1,3 @@
+ALTER TABLE investments ADD COLUMN brokerage TEXT DEFAULT NULL;
+ALTER TABLE investments ADD COLUMN note TEXT DEFAULT NULL;
+ALTER TABLE investments ADD COLUMN redemption_policy_type TEXT DEFAULT NULL;
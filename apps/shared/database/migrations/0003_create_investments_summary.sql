@@ -0,0 +1,60 @@
+CREATE TABLE investments_summary (
+    id TEXT PRIMARY KEY,
+    investment_id TEXT DEFAULT NULL,
+    last_operation_date TEXT NOT NULL,
+    brokerage TEXT DEFAULT NULL,
+    type TEXT NOT NULL,
+    symbol TEXT,
+    bond_index TEXT,
+    bond_rate NUMERIC(12,4),
+    quantity NUMERIC(12,4) NOT NULL DEFAULT 0,
+    average_price NUMERIC(12,4) NOT NULL DEFAULT 0,
+    total_value NUMERIC(12,4) NOT NULL DEFAULT 0,
+    market_value NUMERIC(12,4) NOT NULL DEFAULT 0,
+    cost NUMERIC(12,4) NOT NULL,
+    redemption_policy_type TEXT DEFAULT NULL,
+    due_date TEXT DEFAULT NULL,
+    created_at TEXT NOT NULL DEFAULT (datetime('now')),
+    updated_at TEXT NOT NULL DEFAULT (datetime('now'))
+);
+
+CREATE INDEX idx_investments_summary_type ON investments_summary(type);
+CREATE INDEX idx_investments_summary_brokerage ON investments_summary(brokerage);
+CREATE INDEX idx_investments_summary_symbol ON investments_summary(symbol);
+CREATE INDEX idx_investments_summary_bond_index ON investments_summary(bond_index);
+CREATE INDEX idx_investments_summary_redemption_policy_type ON investments_summary(redemption_policy_type);
+CREATE INDEX idx_investments_summary_investment_id ON investments_summary(investment_id);
+CREATE INDEX idx_investments_summary_last_operation_date ON investments_summary(last_operation_date);
+
+-- backfill: every operation created before the summary existed becomes a position
+INSERT INTO investments_summary(
+    id,
+    investment_id,
+    last_operation_date,
+    brokerage,
+    type,
+    symbol,
+    bond_index,
+    bond_rate,
+    quantity,
+    average_price,
+    total_value,
+    cost,
+    redemption_policy_type,
+    due_date
+) SELECT
+    id,
+    id as investment_id,
+    operation_date,
+    brokerage,
+    type,
+    symbol,
+    bond_index,
+    bond_rate,
+    quantity,
+    unit_price,
+    total_value,
+    cost,
+    redemption_policy_type,
+    due_date
+  FROM investments;
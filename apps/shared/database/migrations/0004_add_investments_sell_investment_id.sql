@@ -0,0 +1 @@
+ALTER TABLE investments ADD COLUMN sell_investment_id TEXT DEFAULT NULL;
@@ -0,0 +1,22 @@
+CREATE TABLE investments_summary_history (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    investment_id TEXT DEFAULT NULL,
+    last_operation_date TEXT NOT NULL,
+    operation_month INTEGER NOT NULL,
+    operation_year INTEGER NOT NULL,
+    brokerage TEXT DEFAULT NULL,
+    type TEXT NOT NULL,
+    symbol TEXT,
+    bond_index TEXT,
+    bond_rate NUMERIC(12,4),
+    quantity NUMERIC(12,4) NOT NULL DEFAULT 0,
+    average_price NUMERIC(12,4) NOT NULL DEFAULT 0,
+    total_value NUMERIC(12,4) NOT NULL DEFAULT 0,
+    market_value NUMERIC(12,4) NOT NULL DEFAULT 0,
+    cost NUMERIC(12,4) NOT NULL,
+    redemption_policy_type TEXT DEFAULT NULL,
+    due_date TEXT DEFAULT NULL,
+    created_at TEXT NOT NULL DEFAULT (datetime('now'))
+);
+
+ALTER TABLE investments_summary_history ADD COLUMN investment_summary_id TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,2 @@
+ALTER TABLE investments ADD COLUMN pnl NUMERIC(12, 4) NOT NULL DEFAULT 0;
+ALTER TABLE investments ADD COLUMN average_selling_price NUMERIC(12, 4) NOT NULL DEFAULT 0;
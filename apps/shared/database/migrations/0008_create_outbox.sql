@@ -0,0 +1,16 @@
+CREATE TABLE outbox (
+    id TEXT PRIMARY KEY,
+    aggregate_id TEXT NOT NULL,
+    destination TEXT NOT NULL,
+    message_group_id TEXT DEFAULT NULL,
+    payload TEXT NOT NULL,
+    status TEXT NOT NULL DEFAULT 'pending',
+    attempts INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT DEFAULT NULL,
+    created_at TEXT NOT NULL DEFAULT (datetime('now')),
+    updated_at TEXT NOT NULL DEFAULT (datetime('now')),
+    delivered_at TEXT DEFAULT NULL
+);
+
+CREATE INDEX idx_outbox_status_created_at ON outbox(status, created_at);
+CREATE INDEX idx_outbox_aggregate_id ON outbox(aggregate_id);
@@ -0,0 +1,4 @@
+ALTER TABLE investments ADD COLUMN idempotency_key TEXT DEFAULT NULL;
+
+-- NULLs are distinct, so operations created before the key existed are not affected
+CREATE UNIQUE INDEX idx_investments_idempotency_key ON investments(idempotency_key);
@@ -0,0 +1,10 @@
+CREATE TABLE operation_status (
+    id TEXT PRIMARY KEY,
+    idempotency_key TEXT DEFAULT NULL,
+    status TEXT NOT NULL,
+    reason TEXT DEFAULT NULL,
+    created_at TEXT NOT NULL DEFAULT (datetime('now')),
+    updated_at TEXT NOT NULL DEFAULT (datetime('now'))
+);
+
+CREATE INDEX idx_operation_status_idempotency_key ON operation_status(idempotency_key);
@@ -0,0 +1,2 @@
+ALTER TABLE investments ADD COLUMN day_trade_quantity NUMERIC(12, 4) NOT NULL DEFAULT 0;
+ALTER TABLE investments ADD COLUMN day_trade_pnl NUMERIC(12, 4) NOT NULL DEFAULT 0;
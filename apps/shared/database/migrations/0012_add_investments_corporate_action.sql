@@ -0,0 +1,6 @@
+ALTER TABLE investments ADD COLUMN operation_subtype TEXT DEFAULT NULL;
+ALTER TABLE investments ADD COLUMN ratio NUMERIC(12, 6) NOT NULL DEFAULT 0;
+
+-- the operation that produced each state of a position, so corporate actions show up in the history
+ALTER TABLE investments_summary ADD COLUMN last_operation_type TEXT DEFAULT NULL;
+ALTER TABLE investments_summary_history ADD COLUMN last_operation_type TEXT DEFAULT NULL;
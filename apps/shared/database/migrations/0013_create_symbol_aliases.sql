@@ -0,0 +1,10 @@
+-- tickers that changed (mergers, rebranding), operations informed with old_symbol are redirected
+CREATE TABLE symbol_aliases (
+    old_symbol TEXT PRIMARY KEY,
+    new_symbol TEXT NOT NULL,
+    ratio NUMERIC(12, 6) NOT NULL DEFAULT 1,
+    effective_date TEXT NOT NULL,
+    created_at TEXT NOT NULL DEFAULT (datetime('now'))
+);
+
+CREATE INDEX idx_symbol_aliases_new_symbol ON symbol_aliases(new_symbol);
@@ -0,0 +1,2 @@
+-- income tax withheld at source, e.g. 15% of juros sobre capital proprio
+ALTER TABLE investments ADD COLUMN tax_withheld NUMERIC(12, 4) NOT NULL DEFAULT 0;
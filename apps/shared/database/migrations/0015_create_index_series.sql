@@ -0,0 +1,10 @@
+-- index series used to accrue bonds: cdi and selic hold the daily rate in % per day
+-- (BCB SGS 12 and 11), ipca the monthly variation in % with reference_date on the
+-- first day of the month (BCB SGS 433)
+CREATE TABLE index_series (
+    index_name TEXT NOT NULL,
+    reference_date TEXT NOT NULL,
+    value NUMERIC(16, 8) NOT NULL,
+    created_at TEXT NOT NULL DEFAULT (datetime('now')),
+    PRIMARY KEY (index_name, reference_date)
+);
@@ -0,0 +1,4 @@
+-- taxes withheld on bond redemptions: income tax goes to tax_withheld, iof here,
+-- and the proceeds left after both
+ALTER TABLE investments ADD COLUMN iof NUMERIC(12, 2) NOT NULL DEFAULT 0;
+ALTER TABLE investments ADD COLUMN net_proceeds NUMERIC(12, 2) NOT NULL DEFAULT 0;
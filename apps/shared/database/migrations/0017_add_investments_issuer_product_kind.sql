@@ -0,0 +1,13 @@
+-- structured issuer and product of bonds, e.g. issuer "Banco BTG Pactual" and product_kind "lca"
+ALTER TABLE investments ADD COLUMN issuer TEXT;
+ALTER TABLE investments ADD COLUMN product_kind TEXT;
+
+CREATE INDEX idx_investments_issuer ON investments(issuer);
+
+-- financial conglomerate of each issuer, the FGC limit applies per conglomerate.
+-- Issuers without a row are a conglomerate of their own
+CREATE TABLE issuer_conglomerates (
+    issuer TEXT PRIMARY KEY,
+    conglomerate TEXT NOT NULL,
+    created_at TEXT NOT NULL DEFAULT (datetime('now'))
+);
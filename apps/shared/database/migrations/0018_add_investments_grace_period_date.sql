@@ -0,0 +1,2 @@
+-- hybrid bonds can be redeemed at any time from the end of the grace period (carência) on
+ALTER TABLE investments ADD COLUMN grace_period_date TEXT;
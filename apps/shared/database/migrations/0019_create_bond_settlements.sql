@@ -0,0 +1,7 @@
+-- amount received on the maturity of a bond, confirmed by the user. Matured
+-- bonds other than prefixed ones are only settled once the amount is confirmed
+CREATE TABLE bond_settlements (
+    summary_id TEXT PRIMARY KEY,
+    amount NUMERIC(16,2) NOT NULL,
+    created_at TEXT NOT NULL DEFAULT (datetime('now'))
+);
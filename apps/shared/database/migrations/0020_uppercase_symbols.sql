@@ -0,0 +1,5 @@
+-- tickers are upper-cased on write, positions informed in another case are merged by a rebuild
+UPDATE investments SET symbol = UPPER(TRIM(symbol)) WHERE type != 'bond';
+UPDATE investments_summary SET symbol = UPPER(TRIM(symbol)) WHERE type != 'bond';
+UPDATE investments_summary_history SET symbol = UPPER(TRIM(symbol)) WHERE type != 'bond';
+UPDATE OR IGNORE symbol_aliases SET old_symbol = UPPER(TRIM(old_symbol)), new_symbol = UPPER(TRIM(new_symbol));
@@ -0,0 +1,122 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+
+	_ "modernc.org/sqlite"
+)
+
+// SQLite is a local, file based stand-in for D1 used for development and tests.
+type SQLite struct {
+	db *sql.DB
+}
+
+// NewSQLite opens (or creates) the database file and applies pending migrations.
+// Use ":memory:" for a throwaway database.
+func NewSQLite(path string) (*SQLite, error) {
+	if path == "" {
+		return nil, fmt.Errorf("sqlite database path is required")
+	}
+
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, fmt.Errorf("failure to open sqlite database: %w", err)
+	}
+	// a single connection keeps ":memory:" databases alive and serializes writes
+	db.SetMaxOpenConns(1)
+
+	sqlite := &SQLite{db: db}
+	if _, err := Up(context.Background(), sqlite); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return sqlite, nil
+}
+
+func (s *SQLite) Close() error {
+	return s.db.Close()
+}
+
+func toArgs(params []string) []interface{} {
+	args := make([]interface{}, len(params))
+	for i, p := range params {
+		args[i] = p
+	}
+	return args
+}
+
+func (s *SQLite) Query(ctx context.Context, command string, params ...string) ([]Row, error) {
+	rows, err := s.db.QueryContext(ctx, command, toArgs(params)...)
+	if err != nil {
+		log.Printf("Failure do execute command: %s. Params: %v Detail: %v", command, params, err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+
+	result := []Row{}
+	for rows.Next() {
+		values := make([]interface{}, len(columns))
+		pointers := make([]interface{}, len(columns))
+		for i := range values {
+			pointers[i] = &values[i]
+		}
+
+		if err := rows.Scan(pointers...); err != nil {
+			return nil, err
+		}
+
+		row := Row{}
+		for i, column := range columns {
+			if b, ok := values[i].([]byte); ok {
+				row[column] = string(b)
+				continue
+			}
+			row[column] = values[i]
+		}
+		result = append(result, row)
+	}
+
+	return result, rows.Err()
+}
+
+func (s *SQLite) Exec(ctx context.Context, command string, params ...string) error {
+	if _, err := s.db.ExecContext(ctx, command, toArgs(params)...); err != nil {
+		log.Printf("Failure do execute command: %s. Params: %v Detail: %v", command, params, err)
+		return err
+	}
+	return nil
+}
+
+func (s *SQLite) Batch(ctx context.Context, statements []Statement) error {
+	params := []string{}
+	for _, statement := range statements {
+		params = append(params, statement.Params...)
+	}
+	if err := checkBatchParams(params); err != nil {
+		return err
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	for _, statement := range statements {
+		if _, err := tx.ExecContext(ctx, statement.Sql, toArgs(statement.Params)...); err != nil {
+			tx.Rollback()
+			log.Printf("Failure do execute command: %s. Params: %v Detail: %v", statement.Sql, statement.Params, err)
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
@@ -0,0 +1,236 @@
+// Package decimal is the exact numeric type used for money, quantities and rates.
+//
+// Values are never rounded implicitly: callers round at the boundaries with
+// Money, Price, Quantity or Rate, so the rule applied to each column is explicit.
+package decimal
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"fmt"
+
+	shopspring "github.com/shopspring/decimal"
+)
+
+var (
+	// BRL values (total value, cost, pnl)
+	MoneyPlaces int32 = 2
+	// unit and average prices, kept longer than money so averages do not drift
+	PricePlaces int32 = 6
+	// fractional shares and bond quantities
+	QuantityPlaces int32 = 6
+	// bond rates and index factors
+	RatePlaces int32 = 4
+)
+
+type Decimal struct {
+	value shopspring.Decimal
+}
+
+var Zero = Decimal{}
+
+func NewFromInt(value int64) Decimal {
+	return Decimal{value: shopspring.NewFromInt(value)}
+}
+
+// NewFromFloat converts a float using its shortest representation, so 0.1 becomes exactly 0.1.
+func NewFromFloat(value float64) Decimal {
+	return Decimal{value: shopspring.NewFromFloat(value)}
+}
+
+func NewFromString(value string) (Decimal, error) {
+	d, err := shopspring.NewFromString(value)
+	if err != nil {
+		return Zero, fmt.Errorf("invalid decimal %q: %w", value, err)
+	}
+	return Decimal{value: d}, nil
+}
+
+// RequireFromString is NewFromString for constants, it panics on invalid input.
+func RequireFromString(value string) Decimal {
+	d, err := NewFromString(value)
+	if err != nil {
+		panic(err)
+	}
+	return d
+}
+
+func (d Decimal) Add(other Decimal) Decimal {
+	return Decimal{value: d.value.Add(other.value)}
+}
+
+func (d Decimal) Sub(other Decimal) Decimal {
+	return Decimal{value: d.value.Sub(other.value)}
+}
+
+func (d Decimal) Mul(other Decimal) Decimal {
+	return Decimal{value: d.value.Mul(other.value)}
+}
+
+// Div returns d / other with 16 decimal places. It panics when other is zero,
+// callers guard divisors that may be zero, such as the quantity of a position.
+func (d Decimal) Div(other Decimal) Decimal {
+	return Decimal{value: d.value.DivRound(other.value, 16)}
+}
+
+// Pow raises d to a fractional exponent, used for compounding rates.
+func (d Decimal) Pow(exponent Decimal) Decimal {
+	result, err := d.value.PowWithPrecision(exponent.value, 16)
+	if err != nil {
+		return Zero
+	}
+	return Decimal{value: result}
+}
+
+func (d Decimal) Neg() Decimal {
+	return Decimal{value: d.value.Neg()}
+}
+
+func (d Decimal) Abs() Decimal {
+	return Decimal{value: d.value.Abs()}
+}
+
+// Round rounds half away from zero.
+func (d Decimal) Round(places int32) Decimal {
+	return Decimal{value: d.value.Round(places)}
+}
+
+// Truncate drops the digits after places, used for whole share counts.
+func (d Decimal) Truncate(places int32) Decimal {
+	return Decimal{value: d.value.Truncate(places)}
+}
+
+func (d Decimal) Money() Decimal {
+	return d.Round(MoneyPlaces)
+}
+
+func (d Decimal) Price() Decimal {
+	return d.Round(PricePlaces)
+}
+
+func (d Decimal) Quantity() Decimal {
+	return d.Round(QuantityPlaces)
+}
+
+func (d Decimal) Rate() Decimal {
+	return d.Round(RatePlaces)
+}
+
+func (d Decimal) Cmp(other Decimal) int {
+	return d.value.Cmp(other.value)
+}
+
+func (d Decimal) Equal(other Decimal) bool {
+	return d.value.Equal(other.value)
+}
+
+func (d Decimal) LessThan(other Decimal) bool {
+	return d.value.LessThan(other.value)
+}
+
+func (d Decimal) GreaterThan(other Decimal) bool {
+	return d.value.GreaterThan(other.value)
+}
+
+func (d Decimal) IsZero() bool {
+	return d.value.IsZero()
+}
+
+func (d Decimal) IsPositive() bool {
+	return d.value.IsPositive()
+}
+
+func (d Decimal) IsNegative() bool {
+	return d.value.IsNegative()
+}
+
+func (d Decimal) IntPart() int64 {
+	return d.value.IntPart()
+}
+
+func (d Decimal) Float64() float64 {
+	f, _ := d.value.Float64()
+	return f
+}
+
+// String is the exact representation without exponent, it is the format sent to D1.
+func (d Decimal) String() string {
+	return d.value.String()
+}
+
+// StringFixed formats with a fixed number of decimal places, e.g. for messages.
+func (d Decimal) StringFixed(places int32) string {
+	return d.value.StringFixed(places)
+}
+
+func Min(first Decimal, rest ...Decimal) Decimal {
+	m := first
+	for _, d := range rest {
+		if d.LessThan(m) {
+			m = d
+		}
+	}
+	return m
+}
+
+func Max(first Decimal, rest ...Decimal) Decimal {
+	m := first
+	for _, d := range rest {
+		if d.GreaterThan(m) {
+			m = d
+		}
+	}
+	return m
+}
+
+func Sum(values ...Decimal) Decimal {
+	total := Zero
+	for _, d := range values {
+		total = total.Add(d)
+	}
+	return total
+}
+
+// MarshalJSON writes a JSON number, keeping the API payloads unchanged.
+func (d Decimal) MarshalJSON() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+// UnmarshalJSON accepts numbers, quoted numbers and null.
+func (d *Decimal) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	if bytes.Equal(data, []byte("null")) || bytes.Equal(data, []byte(`""`)) {
+		*d = Zero
+		return nil
+	}
+
+	value, err := NewFromString(string(bytes.Trim(data, `"`)))
+	if err != nil {
+		return err
+	}
+	*d = value
+	return nil
+}
+
+// Value stores the exact string, sqlite NUMERIC affinity converts it on insert.
+func (d Decimal) Value() (driver.Value, error) {
+	return d.String(), nil
+}
+
+func (d *Decimal) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case nil:
+		*d = Zero
+	case int64:
+		*d = NewFromInt(v)
+	case float64:
+		*d = NewFromFloat(v)
+	case []byte:
+		return d.UnmarshalJSON(v)
+	case string:
+		return d.UnmarshalJSON([]byte(v))
+	default:
+		return fmt.Errorf("unsupported decimal source: %T", src)
+	}
+	return nil
+}
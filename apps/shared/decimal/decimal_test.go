@@ -0,0 +1,68 @@
+package decimal
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestRounding(t *testing.T) {
+	value := RequireFromString("10.4449999")
+
+	if got := value.Money().String(); got != "10.44" {
+		t.Errorf("expected money 10.44, got %s", got)
+	}
+	if got := RequireFromString("0.125").Money().String(); got != "0.13" {
+		t.Errorf("expected money 0.13, got %s", got)
+	}
+	if got := value.Price().String(); got != "10.445" {
+		t.Errorf("expected price 10.445, got %s", got)
+	}
+	if got := NewFromInt(10).Div(NewFromInt(3)).Quantity().String(); got != "3.333333" {
+		t.Errorf("expected quantity 3.333333, got %s", got)
+	}
+}
+
+func TestDivByZero(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("expected a division by zero to panic")
+		}
+	}()
+
+	NewFromInt(10).Div(Zero)
+}
+
+func TestExactArithmetic(t *testing.T) {
+	total := Zero
+	for i := 0; i < 10; i++ {
+		total = total.Add(RequireFromString("0.1"))
+	}
+
+	if !total.Equal(NewFromInt(1)) {
+		t.Errorf("expected 1, got %s", total)
+	}
+	if !total.Sub(NewFromInt(1)).IsZero() {
+		t.Errorf("expected no residue, got %s", total.Sub(NewFromInt(1)))
+	}
+}
+
+func TestJSON(t *testing.T) {
+	var payload struct {
+		Quantity   Decimal `json:"quantity"`
+		TotalValue Decimal `json:"totalValue"`
+		BondRate   Decimal `json:"bondRate,omitzero"`
+	}
+
+	if err := json.Unmarshal([]byte(`{"quantity": 1.5, "totalValue": "1002.97", "bondRate": null}`), &payload); err != nil {
+		t.Fatalf("failure to unmarshal: %v", err)
+	}
+
+	encoded, err := json.Marshal(payload)
+	if err != nil {
+		t.Fatalf("failure to marshal: %v", err)
+	}
+
+	if string(encoded) != `{"quantity":1.5,"totalValue":1002.97}` {
+		t.Errorf("unexpected json: %s", encoded)
+	}
+}
@@ -0,0 +1,96 @@
+package outbox
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	"github.com/silasstoffel/invest-tracker/apps/shared/repository"
+)
+
+// destinations
+const CalculateAveragePrice = "calculate-average-price"
+
+type Sender interface {
+	SendMessage(ctx context.Context, params *sqs.SendMessageInput, optFns ...func(*sqs.Options)) (*sqs.SendMessageOutput, error)
+}
+
+// Relay publishes outbox messages to their queues and records the outcome.
+type Relay struct {
+	sender     Sender
+	repository repository.OutboxRepository
+	// destination name -> queue url
+	queues map[string]string
+}
+
+type RelayResult struct {
+	Delivered int
+	Failed    []repository.OutboxMessage
+}
+
+func NewRelay(sender Sender, outboxRepository repository.OutboxRepository, queues map[string]string) *Relay {
+	return &Relay{
+		sender:     sender,
+		repository: outboxRepository,
+		queues:     queues,
+	}
+}
+
+// Deliver sends a single message. Failures keep the message pending so the
+// next relay run retries it.
+func (r *Relay) Deliver(ctx context.Context, message repository.OutboxMessage) error {
+	queueURL, ok := r.queues[message.Destination]
+	if !ok || queueURL == "" {
+		err := fmt.Errorf("no queue configured for destination %s", message.Destination)
+		r.repository.MarkFailed(ctx, message.ID, err.Error())
+		return err
+	}
+
+	input := &sqs.SendMessageInput{
+		QueueUrl:    aws.String(queueURL),
+		MessageBody: aws.String(message.Payload),
+	}
+
+	// fifo queues deduplicate by aggregate, so a message sent twice by the
+	// producer and by the relay is only processed once
+	if message.MessageGroupID != "" {
+		input.MessageGroupId = aws.String(message.MessageGroupID)
+		input.MessageDeduplicationId = aws.String(message.AggregateID)
+	}
+
+	if _, err := r.sender.SendMessage(ctx, input); err != nil {
+		if markErr := r.repository.MarkFailed(ctx, message.ID, err.Error()); markErr != nil {
+			log.Print(markErr)
+		}
+		return fmt.Errorf("failure to send outbox message %s to %s: %w", message.ID, message.Destination, err)
+	}
+
+	return r.repository.MarkDelivered(ctx, message.ID)
+}
+
+// DeliverPending sends the pending messages older than gracePeriod, leaving the
+// most recent ones to the producer that is still delivering them.
+func (r *Relay) DeliverPending(ctx context.Context, gracePeriod time.Duration, limit int) (RelayResult, error) {
+	result := RelayResult{}
+
+	messages, err := r.repository.Pending(ctx, time.Now().Add(-gracePeriod), limit)
+	if err != nil {
+		return result, fmt.Errorf("failure to read pending outbox messages: %w", err)
+	}
+
+	for _, message := range messages {
+		if err := r.Deliver(ctx, message); err != nil {
+			log.Print(err)
+			message.Attempts++
+			message.LastError = err.Error()
+			result.Failed = append(result.Failed, message)
+			continue
+		}
+		result.Delivered++
+	}
+
+	return result, nil
+}
@@ -0,0 +1,91 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	investment_core "github.com/silasstoffel/invest-tracker/apps/investments/core"
+	"github.com/silasstoffel/invest-tracker/apps/shared/database"
+	"github.com/silasstoffel/invest-tracker/apps/shared/decimal"
+	"github.com/silasstoffel/invest-tracker/apps/shared/repository"
+)
+
+type fakeSender struct {
+	err  error
+	sent []*sqs.SendMessageInput
+}
+
+func (f *fakeSender) SendMessage(ctx context.Context, params *sqs.SendMessageInput, optFns ...func(*sqs.Options)) (*sqs.SendMessageOutput, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	f.sent = append(f.sent, params)
+	return &sqs.SendMessageOutput{}, nil
+}
+
+func TestDeliverPending(t *testing.T) {
+	ctx := context.Background()
+	db, err := database.NewSQLite(":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	investment := investment_core.InvestmentEntity{
+		ID:            "inv-01",
+		Type:          investment_core.StockInvestmentType,
+		OperationType: investment_core.BuyOperationType,
+		Brokerage:     "xp",
+		Symbol:        "VALE3",
+		Quantity:      decimal.NewFromInt(10),
+		UnitPrice:     decimal.NewFromInt(60),
+		TotalValue:    decimal.NewFromInt(600),
+		OperationDate: "2025-01-10",
+		CreatedAt:     time.Now(),
+	}
+	err = repository.NewInvestmentRepository(db).CreateWithOutbox(ctx, investment, repository.OutboxMessage{
+		ID:             "01",
+		AggregateID:    investment.ID,
+		Destination:    CalculateAveragePrice,
+		MessageGroupID: investment.Symbol,
+		Payload:        "{}",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	outboxRepository := repository.NewOutboxRepository(db)
+	sender := &fakeSender{err: errors.New("queue unavailable")}
+	relay := NewRelay(sender, outboxRepository, map[string]string{CalculateAveragePrice: "https://queue"})
+
+	result, err := relay.DeliverPending(ctx, -time.Minute, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Delivered != 0 || len(result.Failed) != 1 || result.Failed[0].Attempts != 1 {
+		t.Fatalf("expected one failed attempt, got %+v", result)
+	}
+
+	sender.err = nil
+	result, err = relay.DeliverPending(ctx, -time.Minute, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Delivered != 1 || len(result.Failed) != 0 {
+		t.Fatalf("expected message to be delivered on retry, got %+v", result)
+	}
+	if *sender.sent[0].MessageDeduplicationId != "inv-01" || *sender.sent[0].MessageGroupId != "VALE3" {
+		t.Errorf("unexpected fifo attributes: %+v", sender.sent[0])
+	}
+
+	pending, err := outboxRepository.Pending(ctx, time.Now().Add(time.Minute), 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pending) != 0 {
+		t.Errorf("expected no pending messages, got %d", len(pending))
+	}
+}
@@ -0,0 +1,135 @@
+package quotes
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/silasstoffel/invest-tracker/apps/shared/decimal"
+)
+
+// CSVProvider reads quotes from a csv file or url, such as a spreadsheet
+// published as csv. The header names the columns symbol, price and, optionally,
+// date (YYYY-MM-DD). When a symbol has more than one line the latest date wins.
+type CSVProvider struct {
+	source string
+	client *http.Client
+}
+
+func NewCSVProvider(source string) *CSVProvider {
+	return &CSVProvider{
+		source: source,
+		client: &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+func (p *CSVProvider) open(ctx context.Context) (io.ReadCloser, error) {
+	if !strings.HasPrefix(p.source, "http://") && !strings.HasPrefix(p.source, "https://") {
+		return os.Open(p.source)
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, p.source, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := p.client.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	if response.StatusCode != http.StatusOK {
+		response.Body.Close()
+		return nil, fmt.Errorf("unexpected status %d", response.StatusCode)
+	}
+
+	return response.Body, nil
+}
+
+func (p *CSVProvider) Quotes(ctx context.Context, symbols []string) (map[string]Quote, error) {
+	reader, err := p.open(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failure to read quotes from %s: %w", p.source, err)
+	}
+	defer reader.Close()
+
+	return parseQuotes(reader, symbols)
+}
+
+func parseQuotes(reader io.Reader, symbols []string) (map[string]Quote, error) {
+	wanted := map[string]bool{}
+	for _, symbol := range symbols {
+		wanted[strings.ToUpper(symbol)] = true
+	}
+
+	lines, err := csv.NewReader(reader).ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("invalid quotes file: %w", err)
+	}
+	if len(lines) == 0 {
+		return map[string]Quote{}, nil
+	}
+
+	columns := map[string]int{}
+	for i, name := range lines[0] {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	symbolColumn, hasSymbol := columns["symbol"]
+	priceColumn, hasPrice := columns["price"]
+	dateColumn, hasDate := columns["date"]
+	if !hasSymbol || !hasPrice {
+		return nil, fmt.Errorf("quotes file must have the columns symbol and price")
+	}
+
+	quotes := map[string]Quote{}
+	for n, line := range lines[1:] {
+		if len(line) <= symbolColumn || len(line) <= priceColumn {
+			continue
+		}
+
+		symbol := strings.ToUpper(strings.TrimSpace(line[symbolColumn]))
+		if !wanted[symbol] {
+			continue
+		}
+
+		price, err := parsePrice(line[priceColumn])
+		if err != nil {
+			return nil, fmt.Errorf("invalid price of %s in line %d: %w", symbol, n+2, err)
+		}
+
+		quote := Quote{Symbol: symbol, Price: price}
+		if hasDate && len(line) > dateColumn {
+			quote.Date = strings.TrimSpace(line[dateColumn])
+		}
+
+		if current, ok := quotes[symbol]; ok && current.Date > quote.Date {
+			continue
+		}
+		quotes[symbol] = quote
+	}
+
+	return quotes, nil
+}
+
+// parsePrice accepts "12.34" and the brazilian formats "12,34", "1.234,56" and "R$ 12,34".
+func parsePrice(value string) (decimal.Decimal, error) {
+	value = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(value), "R$"))
+	if strings.Contains(value, ",") {
+		value = strings.ReplaceAll(value, ".", "")
+		value = strings.ReplaceAll(value, ",", ".")
+	}
+
+	price, err := decimal.NewFromString(value)
+	if err != nil {
+		return decimal.Zero, err
+	}
+	if !price.IsPositive() {
+		return decimal.Zero, fmt.Errorf("price must be greater than zero")
+	}
+
+	return price, nil
+}
@@ -0,0 +1,37 @@
+package quotes
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/silasstoffel/invest-tracker/apps/shared/decimal"
+)
+
+func TestCSVProvider(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "quotes.csv")
+	content := "Symbol,Price,Date\n" +
+		"VALE3,60.10,2025-03-09\n" +
+		"vale3,61.25,2025-03-10\n" +
+		"HGLG11,\"R$ 1.160,50\",2025-03-10\n" +
+		"ITSA4,10.00,2025-03-10\n"
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	quotes, err := NewCSVProvider(path).Quotes(context.Background(), []string{"VALE3", "HGLG11", "BBAS3"})
+	if err != nil {
+		t.Fatalf("failure to read quotes: %v", err)
+	}
+
+	if len(quotes) != 2 {
+		t.Fatalf("expected only the quotes asked for, got %+v", quotes)
+	}
+	if !quotes["VALE3"].Price.Equal(decimal.RequireFromString("61.25")) || quotes["VALE3"].Date != "2025-03-10" {
+		t.Errorf("expected the latest quote of VALE3, got %+v", quotes["VALE3"])
+	}
+	if !quotes["HGLG11"].Price.Equal(decimal.RequireFromString("1160.50")) {
+		t.Errorf("expected the brazilian price format to be parsed, got %s", quotes["HGLG11"].Price)
+	}
+}
@@ -0,0 +1,38 @@
+// Package quotes provides market prices of stocks, FIIs, ETFs and REITs.
+package quotes
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/silasstoffel/invest-tracker/apps/shared/decimal"
+	appConfig "github.com/silasstoffel/invest-tracker/config"
+)
+
+const CSVProviderName = "csv"
+
+type Quote struct {
+	Symbol string
+	Price  decimal.Decimal
+	// YYYY-MM-DD, empty when the source does not tell
+	Date string
+}
+
+// QuoteProvider returns the latest quote of each symbol. Symbols without a
+// quote are left out of the result.
+type QuoteProvider interface {
+	Quotes(ctx context.Context, symbols []string) (map[string]Quote, error)
+}
+
+// NewFromConfig returns the provider selected by QUOTES_PROVIDER, reading QUOTES_SOURCE.
+func NewFromConfig(env *appConfig.Config) (QuoteProvider, error) {
+	switch env.Quotes.Provider {
+	case CSVProviderName, "":
+		if env.Quotes.Source == "" {
+			return nil, fmt.Errorf("quotes source is required for the csv provider")
+		}
+		return NewCSVProvider(env.Quotes.Source), nil
+	default:
+		return nil, fmt.Errorf("unsupported quotes provider: %s", env.Quotes.Provider)
+	}
+}
@@ -0,0 +1,51 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/silasstoffel/invest-tracker/apps/shared/database"
+	"github.com/silasstoffel/invest-tracker/apps/shared/decimal"
+)
+
+type BondSettlementRepository interface {
+	// Find returns the amount confirmed for the maturity of a position, or ErrNotFound.
+	Find(ctx context.Context, summaryID string) (decimal.Decimal, error)
+	// Confirm sets the amount received on the maturity of a position.
+	Confirm(ctx context.Context, summaryID string, amount decimal.Decimal) error
+}
+
+type bondSettlementRepository struct {
+	db database.DB
+}
+
+func NewBondSettlementRepository(db database.DB) BondSettlementRepository {
+	return &bondSettlementRepository{db: db}
+}
+
+type bondSettlementRow struct {
+	Amount decimal.Decimal `json:"amount"`
+}
+
+func (r *bondSettlementRepository) Find(ctx context.Context, summaryID string) (decimal.Decimal, error) {
+	rows, err := r.db.Query(ctx, "SELECT amount FROM bond_settlements WHERE summary_id = ? LIMIT 1", summaryID)
+	if err != nil {
+		return decimal.Zero, err
+	}
+
+	records := []bondSettlementRow{}
+	if err := database.Scan(rows, &records); err != nil {
+		return decimal.Zero, err
+	}
+
+	if len(records) == 0 {
+		return decimal.Zero, ErrNotFound
+	}
+
+	return records[0].Amount, nil
+}
+
+func (r *bondSettlementRepository) Confirm(ctx context.Context, summaryID string, amount decimal.Decimal) error {
+	command := `INSERT INTO bond_settlements (summary_id, amount) VALUES (?, ?)
+		ON CONFLICT(summary_id) DO UPDATE SET amount = excluded.amount`
+	return r.db.Exec(ctx, command, summaryID, amount.Money().String())
+}
@@ -0,0 +1,74 @@
+package repository
+
+import (
+	"context"
+	"strings"
+
+	fixed_income_core "github.com/silasstoffel/invest-tracker/apps/fixed_income/core"
+	"github.com/silasstoffel/invest-tracker/apps/shared/database"
+	"github.com/silasstoffel/invest-tracker/apps/shared/decimal"
+)
+
+// indexSeriesBatchSize keeps each batch of the import under the D1 limit of
+// bound parameters per query.
+const indexSeriesBatchSize = 30
+
+type IndexSeriesRepository interface {
+	// Find returns the rates of an index sorted by date.
+	Find(ctx context.Context, index string) ([]fixed_income_core.IndexRate, error)
+	// Save inserts the rates of an index, replacing the ones already stored for the same dates.
+	Save(ctx context.Context, index string, rates []fixed_income_core.IndexRate) error
+}
+
+type indexSeriesRepository struct {
+	db database.DB
+}
+
+func NewIndexSeriesRepository(db database.DB) IndexSeriesRepository {
+	return &indexSeriesRepository{db: db}
+}
+
+type indexRateRow struct {
+	ReferenceDate string          `json:"reference_date"`
+	Value         decimal.Decimal `json:"value"`
+}
+
+func (r *indexSeriesRepository) Find(ctx context.Context, index string) ([]fixed_income_core.IndexRate, error) {
+	rows, err := r.db.Query(ctx, "SELECT reference_date, value FROM index_series WHERE index_name = ? ORDER BY reference_date", index)
+	if err != nil {
+		return nil, err
+	}
+
+	records := []indexRateRow{}
+	if err := database.Scan(rows, &records); err != nil {
+		return nil, err
+	}
+
+	rates := make([]fixed_income_core.IndexRate, 0, len(records))
+	for _, record := range records {
+		rates = append(rates, fixed_income_core.IndexRate{Date: record.ReferenceDate, Rate: record.Value})
+	}
+
+	return rates, nil
+}
+
+func (r *indexSeriesRepository) Save(ctx context.Context, index string, rates []fixed_income_core.IndexRate) error {
+	for start := 0; start < len(rates); start += indexSeriesBatchSize {
+		end := min(start+indexSeriesBatchSize, len(rates))
+
+		values := []string{}
+		params := []string{}
+		for _, rate := range rates[start:end] {
+			values = append(values, "(?, ?, ?)")
+			params = append(params, index, rate.Date, rate.Rate.String())
+		}
+
+		command := `INSERT INTO index_series (index_name, reference_date, value) VALUES ` + strings.Join(values, ", ") + `
+			ON CONFLICT(index_name, reference_date) DO UPDATE SET value = excluded.value`
+		if err := r.db.Exec(ctx, command, params...); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,308 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	investment_core "github.com/silasstoffel/invest-tracker/apps/investments/core"
+	investment_summary_core "github.com/silasstoffel/invest-tracker/apps/investments_summary/core"
+	"github.com/silasstoffel/invest-tracker/apps/shared/database"
+	"github.com/silasstoffel/invest-tracker/apps/shared/decimal"
+)
+
+const investmentColumns = `id, type, symbol, bond_index, bond_rate, quantity, unit_price, total_value, cost,
+	operation_type, operation_subtype, ratio, operation_date, operation_year, operation_month, due_date, brokerage, note,
+	redemption_policy_type, issuer, product_kind, grace_period_date, sell_investment_id, idempotency_key, pnl, average_selling_price, day_trade_quantity, day_trade_pnl,
+	tax_withheld, iof, net_proceeds, created_at, updated_at`
+
+// investmentRow mirrors the investments columns.
+type investmentRow struct {
+	ID                   string          `json:"id"`
+	Type                 string          `json:"type"`
+	Symbol               string          `json:"symbol"`
+	BondIndex            string          `json:"bond_index"`
+	BondRate             decimal.Decimal `json:"bond_rate"`
+	Quantity             decimal.Decimal `json:"quantity"`
+	UnitPrice            decimal.Decimal `json:"unit_price"`
+	TotalValue           decimal.Decimal `json:"total_value"`
+	Cost                 decimal.Decimal `json:"cost"`
+	OperationType        string          `json:"operation_type"`
+	OperationSubtype     string          `json:"operation_subtype"`
+	Ratio                decimal.Decimal `json:"ratio"`
+	OperationDate        string          `json:"operation_date"`
+	OperationYear        int             `json:"operation_year"`
+	OperationMonth       int             `json:"operation_month"`
+	DueDate              string          `json:"due_date"`
+	Brokerage            string          `json:"brokerage"`
+	Note                 string          `json:"note"`
+	RedemptionPolicyType string          `json:"redemption_policy_type"`
+	Issuer               string          `json:"issuer"`
+	ProductKind          string          `json:"product_kind"`
+	GracePeriodDate      string          `json:"grace_period_date"`
+	SellInvestmentId     string          `json:"sell_investment_id"`
+	IdempotencyKey       string          `json:"idempotency_key"`
+	Pnl                  decimal.Decimal `json:"pnl"`
+	AverageSellingPrice  decimal.Decimal `json:"average_selling_price"`
+	DayTradeQuantity     decimal.Decimal `json:"day_trade_quantity"`
+	DayTradePnl          decimal.Decimal `json:"day_trade_pnl"`
+	TaxWithheld          decimal.Decimal `json:"tax_withheld"`
+	Iof                  decimal.Decimal `json:"iof"`
+	NetProceeds          decimal.Decimal `json:"net_proceeds"`
+	CreatedAt            string          `json:"created_at"`
+	UpdatedAt            string          `json:"updated_at"`
+}
+
+func (r investmentRow) toEntity() investment_core.InvestmentEntity {
+	return investment_core.InvestmentEntity{
+		ID:                   r.ID,
+		Type:                 r.Type,
+		Symbol:               r.Symbol,
+		BondIndex:            r.BondIndex,
+		BondRate:             r.BondRate,
+		Quantity:             r.Quantity,
+		UnitPrice:            r.UnitPrice,
+		TotalValue:           r.TotalValue,
+		Cost:                 r.Cost,
+		OperationType:        r.OperationType,
+		OperationSubtype:     r.OperationSubtype,
+		Ratio:                r.Ratio,
+		OperationDate:        r.OperationDate,
+		OperationYear:        r.OperationYear,
+		OperationMonth:       r.OperationMonth,
+		DueDate:              r.DueDate,
+		Brokerage:            r.Brokerage,
+		Note:                 r.Note,
+		RedemptionPolicyType: r.RedemptionPolicyType,
+		Issuer:               r.Issuer,
+		ProductKind:          r.ProductKind,
+		GracePeriodDate:      r.GracePeriodDate,
+		SellInvestmentId:     r.SellInvestmentId,
+		IdempotencyKey:       r.IdempotencyKey,
+		Pnl:                  r.Pnl,
+		AverageSellingPrice:  r.AverageSellingPrice,
+		DayTradeQuantity:     r.DayTradeQuantity,
+		DayTradePnl:          r.DayTradePnl,
+		TaxWithheld:          r.TaxWithheld,
+		Iof:                  r.Iof,
+		NetProceeds:          r.NetProceeds,
+		CreatedAt:            parseDate(r.CreatedAt),
+		UpdatedAt:            parseDate(r.UpdatedAt),
+	}
+}
+
+type investmentRepository struct {
+	db database.DB
+}
+
+func NewInvestmentRepository(db database.DB) InvestmentRepository {
+	return &investmentRepository{db: db}
+}
+
+func (r *investmentRepository) Create(ctx context.Context, entity investment_core.InvestmentEntity) error {
+	statement := createInvestmentStatement(entity)
+	return r.db.Exec(ctx, statement.Sql, statement.Params...)
+}
+
+func (r *investmentRepository) CreateWithOutbox(ctx context.Context, entity investment_core.InvestmentEntity, message OutboxMessage) error {
+	return r.db.Batch(ctx, []database.Statement{
+		createInvestmentStatement(entity),
+		outboxStatement(message),
+	})
+}
+
+func createInvestmentStatement(entity investment_core.InvestmentEntity) database.Statement {
+	command := `INSERT INTO investments (
+		id, type, symbol, quantity, unit_price, total_value, cost, operation_type, operation_date,
+		operation_year, operation_month, due_date, created_at, updated_at, brokerage, note, redemption_policy_type, sell_investment_id,
+		idempotency_key, operation_subtype, ratio, tax_withheld, issuer, product_kind, grace_period_date {add_column_name}) VALUES (
+			?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,NULLIF(?, ''),NULLIF(?, ''),?,?,NULLIF(?, ''),NULLIF(?, ''),NULLIF(?, ''){add_column_value})`
+
+	params := []string{
+		entity.ID,
+		entity.Type,
+		entity.Symbol,
+		entity.Quantity.Quantity().String(),
+		entity.UnitPrice.Price().String(),
+		entity.TotalValue.Money().String(),
+		entity.Cost.Money().String(),
+		entity.OperationType,
+		entity.OperationDate,
+		fmt.Sprintf("%v", entity.OperationYear),
+		fmt.Sprintf("%v", entity.OperationMonth),
+		entity.DueDate,
+		entity.CreatedAt.Format(time.RFC3339),
+		entity.UpdatedAt.Format(time.RFC3339),
+		entity.Brokerage,
+		entity.Note,
+		entity.RedemptionPolicyType,
+		entity.SellInvestmentId,
+		entity.IdempotencyKey,
+		entity.OperationSubtype,
+		entity.Ratio.Quantity().String(),
+		entity.TaxWithheld.Money().String(),
+		entity.Issuer,
+		entity.ProductKind,
+		entity.GracePeriodDate,
+	}
+
+	if entity.BondIndex != "" {
+		command = strings.Replace(command, "{add_column_name}", ", bond_index, bond_rate", 1)
+		command = strings.Replace(command, "{add_column_value}", ",?,?", 1)
+		params = append(params, entity.BondIndex, entity.BondRate.Rate().String())
+	} else {
+		command = strings.Replace(command, "{add_column_name}", "", 1)
+		command = strings.Replace(command, "{add_column_value}", "", 1)
+	}
+
+	return database.Statement{Sql: command, Params: params}
+}
+
+func investmentConditions(filter InvestmentFilter) (string, []string) {
+	conditions := " WHERE 1 = 1"
+	params := []string{}
+
+	if filter.Symbol != "" {
+		conditions += " AND symbol = ? COLLATE NOCASE"
+		params = append(params, filter.Symbol)
+	}
+
+	if filter.Type != "" {
+		conditions += " AND type = ?"
+		params = append(params, filter.Type)
+	}
+
+	if filter.Brokerage != "" {
+		conditions += " AND brokerage = ? COLLATE NOCASE"
+		params = append(params, filter.Brokerage)
+	}
+
+	if filter.OperationType != "" {
+		conditions += " AND operation_type = ?"
+		params = append(params, filter.OperationType)
+	}
+
+	if filter.Year != 0 {
+		conditions += " AND operation_year = ?"
+		params = append(params, fmt.Sprintf("%d", filter.Year))
+	}
+
+	if filter.Month != 0 {
+		conditions += " AND operation_month = ?"
+		params = append(params, fmt.Sprintf("%d", filter.Month))
+	}
+
+	if filter.From != "" {
+		conditions += " AND operation_date >= ?"
+		params = append(params, filter.From)
+	}
+
+	if filter.To != "" {
+		conditions += " AND operation_date <= ?"
+		params = append(params, filter.To)
+	}
+
+	if filter.Note != "" {
+		conditions += " AND note = ?"
+		params = append(params, filter.Note)
+	}
+
+	return conditions, params
+}
+
+func (r *investmentRepository) Find(ctx context.Context, filter InvestmentFilter) ([]investment_core.InvestmentEntity, error) {
+	conditions, params := investmentConditions(filter)
+	command := "SELECT " + investmentColumns + " FROM investments" + conditions + " ORDER BY operation_date, id"
+
+	return r.query(ctx, command, params...)
+}
+
+func (r *investmentRepository) List(ctx context.Context, filter InvestmentFilter, after string, limit int) ([]investment_core.InvestmentEntity, error) {
+	conditions, params := investmentConditions(filter)
+
+	if after != "" {
+		conditions += " AND id > ?"
+		params = append(params, after)
+	}
+
+	command := "SELECT " + investmentColumns + " FROM investments" + conditions + " ORDER BY id LIMIT " + fmt.Sprintf("%d", limit)
+
+	return r.query(ctx, command, params...)
+}
+
+func (r *investmentRepository) FindByID(ctx context.Context, id string) (investment_core.InvestmentEntity, error) {
+	command := "SELECT " + investmentColumns + " FROM investments WHERE id = ? LIMIT 1"
+
+	investments, err := r.query(ctx, command, id)
+	if err != nil {
+		return investment_core.InvestmentEntity{}, err
+	}
+
+	if len(investments) == 0 {
+		return investment_core.InvestmentEntity{}, ErrNotFound
+	}
+
+	return investments[0], nil
+}
+
+func (r *investmentRepository) FindByIdempotencyKey(ctx context.Context, key string) (investment_core.InvestmentEntity, error) {
+	command := "SELECT " + investmentColumns + " FROM investments WHERE idempotency_key = ? LIMIT 1"
+
+	investments, err := r.query(ctx, command, key)
+	if err != nil {
+		return investment_core.InvestmentEntity{}, err
+	}
+
+	if len(investments) == 0 {
+		return investment_core.InvestmentEntity{}, ErrNotFound
+	}
+
+	return investments[0], nil
+}
+
+func (r *investmentRepository) query(ctx context.Context, command string, params ...string) ([]investment_core.InvestmentEntity, error) {
+	rows, err := r.db.Query(ctx, command, params...)
+	if err != nil {
+		return nil, err
+	}
+
+	var investmentRows []investmentRow
+	if err := database.Scan(rows, &investmentRows); err != nil {
+		return nil, err
+	}
+
+	investments := make([]investment_core.InvestmentEntity, 0, len(investmentRows))
+	for _, row := range investmentRows {
+		investments = append(investments, row.toEntity())
+	}
+
+	return investments, nil
+}
+
+func (r *investmentRepository) UpdateProfitAndLoss(ctx context.Context, sale investment_summary_core.SaleResult) error {
+	command := `UPDATE investments SET pnl = ?, updated_at = ?, average_selling_price = ?, day_trade_quantity = ?, day_trade_pnl = ?{taxes} WHERE id = ?`
+
+	params := []string{
+		sale.Pnl.Money().String(),
+		time.Now().UTC().Format(time.RFC3339),
+		sale.AverageSellingPrice.Price().String(),
+		sale.DayTradeQuantity.Quantity().String(),
+		sale.DayTradePnl.Money().String(),
+	}
+
+	taxes := ""
+	if sale.BondRedemption {
+		// other sells keep the tax withheld informed when they were scheduled
+		taxes = ", tax_withheld = ?, iof = ?, net_proceeds = ?"
+		params = append(params, sale.IncomeTax.Money().String(), sale.Iof.Money().String(), sale.NetProceeds.Money().String())
+	}
+	command = strings.Replace(command, "{taxes}", taxes, 1)
+	params = append(params, sale.InvestmentID)
+
+	if err := r.db.Exec(ctx, command, params...); err != nil {
+		return fmt.Errorf("failure to update profit and loss: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,90 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	investment_core "github.com/silasstoffel/invest-tracker/apps/investments/core"
+	investment_summary_core "github.com/silasstoffel/invest-tracker/apps/investments_summary/core"
+	"github.com/silasstoffel/invest-tracker/apps/shared/database"
+	"github.com/silasstoffel/invest-tracker/apps/shared/decimal"
+)
+
+func TestListInvestments(t *testing.T) {
+	ctx := context.Background()
+	db, err := database.NewSQLite(":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	repository := NewInvestmentRepository(db)
+	operations := []struct {
+		id            string
+		operationType string
+		operationDate string
+	}{
+		{"01JGZ5V6K3Q1W2E3R4T5Y6U7A1", investment_core.BuyOperationType, "2024-03-10"},
+		{"01JGZ5V6K3Q1W2E3R4T5Y6U7A2", investment_core.BuyOperationType, "2024-04-10"},
+		{"01JGZ5V6K3Q1W2E3R4T5Y6U7A3", investment_core.SellOperationType, "2024-04-20"},
+		{"01JGZ5V6K3Q1W2E3R4T5Y6U7A4", investment_core.BuyOperationType, "2025-01-05"},
+	}
+
+	for _, operation := range operations {
+		od, _ := time.Parse("2006-01-02", operation.operationDate)
+		err := repository.Create(ctx, investment_core.InvestmentEntity{
+			ID:             operation.id,
+			Type:           investment_core.StockInvestmentType,
+			Symbol:         "VALE3",
+			Quantity:       decimal.NewFromInt(10),
+			UnitPrice:      decimal.NewFromInt(60),
+			TotalValue:     decimal.NewFromInt(600),
+			OperationType:  operation.operationType,
+			OperationDate:  operation.operationDate,
+			OperationYear:  od.Year(),
+			OperationMonth: int(od.Month()),
+			Brokerage:      "xp",
+			Note:           "inter_import",
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := repository.UpdateProfitAndLoss(ctx, investment_summary_core.SaleResult{
+		InvestmentID:        operations[2].id,
+		Pnl:                 decimal.RequireFromString("25.5"),
+		AverageSellingPrice: decimal.NewFromInt(62),
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	filter := InvestmentFilter{Symbol: "VALE3", Year: 2024, Note: "inter_import"}
+	page, err := repository.List(ctx, filter, "", 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(page) != 2 || page[0].ID != operations[0].id || page[1].ID != operations[1].id {
+		t.Fatalf("unexpected first page: %+v", page)
+	}
+
+	page, err = repository.List(ctx, filter, page[1].ID, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(page) != 1 || page[0].ID != operations[2].id {
+		t.Fatalf("unexpected second page: %+v", page)
+	}
+	if !page[0].Pnl.Equal(decimal.RequireFromString("25.5")) || !page[0].AverageSellingPrice.Equal(decimal.NewFromInt(62)) {
+		t.Errorf("expected pnl 25.5 and average selling price 62, got %s and %s", page[0].Pnl, page[0].AverageSellingPrice)
+	}
+
+	sells, err := repository.List(ctx, InvestmentFilter{OperationType: investment_core.SellOperationType, From: "2024-04-01", To: "2024-04-30"}, "", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sells) != 1 || sells[0].ID != operations[2].id {
+		t.Errorf("unexpected sells: %+v", sells)
+	}
+}
@@ -0,0 +1,47 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/silasstoffel/invest-tracker/apps/shared/database"
+)
+
+// IssuerConglomerate maps a bond issuer to the financial conglomerate it belongs to.
+type IssuerConglomerate struct {
+	Issuer       string `json:"issuer"`
+	Conglomerate string `json:"conglomerate"`
+}
+
+type IssuerRepository interface {
+	FindAll(ctx context.Context) ([]IssuerConglomerate, error)
+	// Save sets the conglomerate of an issuer.
+	Save(ctx context.Context, issuer IssuerConglomerate) error
+}
+
+type issuerRepository struct {
+	db database.DB
+}
+
+func NewIssuerRepository(db database.DB) IssuerRepository {
+	return &issuerRepository{db: db}
+}
+
+func (r *issuerRepository) FindAll(ctx context.Context) ([]IssuerConglomerate, error) {
+	rows, err := r.db.Query(ctx, "SELECT issuer, conglomerate FROM issuer_conglomerates ORDER BY issuer")
+	if err != nil {
+		return nil, err
+	}
+
+	issuers := []IssuerConglomerate{}
+	if err := database.Scan(rows, &issuers); err != nil {
+		return nil, err
+	}
+
+	return issuers, nil
+}
+
+func (r *issuerRepository) Save(ctx context.Context, issuer IssuerConglomerate) error {
+	command := `INSERT INTO issuer_conglomerates (issuer, conglomerate) VALUES (?, ?)
+		ON CONFLICT(issuer) DO UPDATE SET conglomerate = excluded.conglomerate`
+	return r.db.Exec(ctx, command, issuer.Issuer, issuer.Conglomerate)
+}
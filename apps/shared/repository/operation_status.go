@@ -0,0 +1,94 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	investment_core "github.com/silasstoffel/invest-tracker/apps/investments/core"
+	"github.com/silasstoffel/invest-tracker/apps/shared/database"
+)
+
+const operationStatusColumns = "id, idempotency_key, status, reason, created_at, updated_at"
+
+type operationStatusRow struct {
+	ID             string `json:"id"`
+	IdempotencyKey string `json:"idempotency_key"`
+	Status         string `json:"status"`
+	Reason         string `json:"reason"`
+	CreatedAt      string `json:"created_at"`
+	UpdatedAt      string `json:"updated_at"`
+}
+
+func (r operationStatusRow) toEntity() investment_core.OperationStatusEntity {
+	return investment_core.OperationStatusEntity{
+		ID:             r.ID,
+		IdempotencyKey: r.IdempotencyKey,
+		Status:         r.Status,
+		Reason:         r.Reason,
+		CreatedAt:      parseDate(r.CreatedAt),
+		UpdatedAt:      parseDate(r.UpdatedAt),
+	}
+}
+
+type operationStatusRepository struct {
+	db database.DB
+}
+
+func NewOperationStatusRepository(db database.DB) OperationStatusRepository {
+	return &operationStatusRepository{db: db}
+}
+
+func (r *operationStatusRepository) first(ctx context.Context, command string, params ...string) (investment_core.OperationStatusEntity, error) {
+	rows, err := r.db.Query(ctx, command, params...)
+	if err != nil {
+		return investment_core.OperationStatusEntity{}, err
+	}
+
+	var statusRows []operationStatusRow
+	if err := database.Scan(rows, &statusRows); err != nil {
+		return investment_core.OperationStatusEntity{}, err
+	}
+
+	if len(statusRows) == 0 {
+		return investment_core.OperationStatusEntity{}, ErrNotFound
+	}
+
+	return statusRows[0].toEntity(), nil
+}
+
+func (r *operationStatusRepository) Find(ctx context.Context, id string) (investment_core.OperationStatusEntity, error) {
+	command := "SELECT " + operationStatusColumns + " FROM operation_status WHERE id = ? LIMIT 1"
+	return r.first(ctx, command, id)
+}
+
+func (r *operationStatusRepository) FindByIdempotencyKey(ctx context.Context, key string) (investment_core.OperationStatusEntity, error) {
+	command := "SELECT " + operationStatusColumns + " FROM operation_status WHERE idempotency_key = ? ORDER BY id DESC LIMIT 1"
+	return r.first(ctx, command, key)
+}
+
+func (r *operationStatusRepository) Queue(ctx context.Context, id string, idempotencyKey string) error {
+	now := time.Now().UTC().Format(time.RFC3339)
+	command := `INSERT INTO operation_status (id, idempotency_key, status, reason, created_at, updated_at)
+		VALUES (?, NULLIF(?, ''), ?, NULL, ?, ?)
+		ON CONFLICT(id) DO UPDATE SET status = excluded.status, reason = NULL, updated_at = excluded.updated_at`
+
+	if err := r.db.Exec(ctx, command, id, idempotencyKey, investment_core.QueuedOperationStatus, now, now); err != nil {
+		return fmt.Errorf("failure to queue operation %s: %w", id, err)
+	}
+
+	return nil
+}
+
+func (r *operationStatusRepository) UpdateStatus(ctx context.Context, id string, status string, reason string) error {
+	now := time.Now().UTC().Format(time.RFC3339)
+	command := `INSERT INTO operation_status (id, status, reason, created_at, updated_at)
+		VALUES (?, ?, NULLIF(?, ''), ?, ?)
+		ON CONFLICT(id) DO UPDATE SET status = excluded.status, reason = excluded.reason, updated_at = excluded.updated_at`
+
+	if err := r.db.Exec(ctx, command, id, status, reason, now, now); err != nil {
+		return fmt.Errorf("failure to update operation %s status to %s: %w", id, status, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,57 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	investment_core "github.com/silasstoffel/invest-tracker/apps/investments/core"
+	"github.com/silasstoffel/invest-tracker/apps/shared/database"
+)
+
+func TestOperationStatus(t *testing.T) {
+	ctx := context.Background()
+	db, err := database.NewSQLite(":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	repository := NewOperationStatusRepository(db)
+	id := "01JGZ5V6K3Q1W2E3R4T5Y6U7I8"
+
+	if _, err := repository.Find(ctx, id); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected not found, got %v", err)
+	}
+
+	if err := repository.Queue(ctx, id, "key-1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := repository.UpdateStatus(ctx, id, investment_core.FailedOperationStatus, "queue unavailable"); err != nil {
+		t.Fatal(err)
+	}
+
+	operation, err := repository.FindByIdempotencyKey(ctx, "key-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if operation.ID != id || operation.Status != investment_core.FailedOperationStatus || operation.Reason != "queue unavailable" {
+		t.Fatalf("unexpected operation: %+v", operation)
+	}
+
+	// sent again, the failure reason is cleared and the key is kept
+	if err := repository.Queue(ctx, id, "key-1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := repository.UpdateStatus(ctx, id, investment_core.PersistedOperationStatus, ""); err != nil {
+		t.Fatal(err)
+	}
+
+	operation, err = repository.Find(ctx, id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if operation.Status != investment_core.PersistedOperationStatus || operation.Reason != "" || operation.IdempotencyKey != "key-1" {
+		t.Errorf("unexpected operation: %+v", operation)
+	}
+}
@@ -0,0 +1,105 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/silasstoffel/invest-tracker/apps/shared/database"
+)
+
+const (
+	OutboxPending   = "pending"
+	OutboxDelivered = "delivered"
+)
+
+// OutboxMessage is a message that must reach a queue once the row that
+// produced it is committed. It is written in the same batch as that row.
+type OutboxMessage struct {
+	ID          string `json:"id"`
+	AggregateID string `json:"aggregate_id"`
+	// logical queue name, resolved to an url by the relay
+	Destination    string `json:"destination"`
+	MessageGroupID string `json:"message_group_id"`
+	Payload        string `json:"payload"`
+	Status         string `json:"status"`
+	Attempts       int    `json:"attempts"`
+	LastError      string `json:"last_error"`
+	CreatedAt      string `json:"created_at"`
+}
+
+type OutboxRepository interface {
+	// Pending returns undelivered messages created before olderThan, oldest first.
+	Pending(ctx context.Context, olderThan time.Time, limit int) ([]OutboxMessage, error)
+	MarkDelivered(ctx context.Context, id string) error
+	// MarkFailed keeps the message pending and records the attempt.
+	MarkFailed(ctx context.Context, id string, reason string) error
+}
+
+func outboxStatement(message OutboxMessage) database.Statement {
+	now := time.Now().UTC().Format(time.RFC3339)
+	return database.Statement{
+		Sql: `INSERT INTO outbox (id, aggregate_id, destination, message_group_id, payload, status, created_at, updated_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+		Params: []string{
+			message.ID,
+			message.AggregateID,
+			message.Destination,
+			message.MessageGroupID,
+			message.Payload,
+			OutboxPending,
+			now,
+			now,
+		},
+	}
+}
+
+type outboxRepository struct {
+	db database.DB
+}
+
+func NewOutboxRepository(db database.DB) OutboxRepository {
+	return &outboxRepository{db: db}
+}
+
+func (r *outboxRepository) Pending(ctx context.Context, olderThan time.Time, limit int) ([]OutboxMessage, error) {
+	command := `SELECT id, aggregate_id, destination, message_group_id, payload, status, attempts, last_error, created_at
+		FROM outbox
+		WHERE status = ? AND created_at <= ?
+		ORDER BY created_at, id
+		LIMIT ` + fmt.Sprintf("%d", limit)
+
+	rows, err := r.db.Query(ctx, command, OutboxPending, olderThan.UTC().Format(time.RFC3339))
+	if err != nil {
+		return nil, err
+	}
+
+	messages := []OutboxMessage{}
+	if err := database.Scan(rows, &messages); err != nil {
+		return nil, err
+	}
+
+	return messages, nil
+}
+
+func (r *outboxRepository) MarkDelivered(ctx context.Context, id string) error {
+	now := time.Now().UTC().Format(time.RFC3339)
+	command := "UPDATE outbox SET status = ?, attempts = attempts + 1, last_error = NULL, delivered_at = ?, updated_at = ? WHERE id = ?"
+
+	if err := r.db.Exec(ctx, command, OutboxDelivered, now, now, id); err != nil {
+		return fmt.Errorf("failure to mark outbox message %s as delivered: %w", id, err)
+	}
+
+	return nil
+}
+
+func (r *outboxRepository) MarkFailed(ctx context.Context, id string, reason string) error {
+	now := time.Now().UTC().Format(time.RFC3339)
+	command := "UPDATE outbox SET attempts = attempts + 1, last_error = ?, updated_at = ? WHERE id = ?"
+
+	if err := r.db.Exec(ctx, command, reason, now, id); err != nil {
+		return fmt.Errorf("failure to mark outbox message %s as failed: %w", id, err)
+	}
+
+	return nil
+}
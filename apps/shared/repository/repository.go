@@ -0,0 +1,96 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	investment_core "github.com/silasstoffel/invest-tracker/apps/investments/core"
+	investment_summary_core "github.com/silasstoffel/invest-tracker/apps/investments_summary/core"
+	"github.com/silasstoffel/invest-tracker/apps/shared/decimal"
+)
+
+var ErrNotFound = errors.New("record not found")
+
+type InvestmentFilter struct {
+	Symbol        string
+	Type          string
+	Brokerage     string
+	OperationType string
+	Year          int
+	Month         int
+	// operation date range (YYYY-MM-DD)
+	From string
+	To   string
+	Note string
+}
+
+type InvestmentRepository interface {
+	Create(ctx context.Context, entity investment_core.InvestmentEntity) error
+	// CreateWithOutbox writes the investment and the outbox message atomically.
+	CreateWithOutbox(ctx context.Context, entity investment_core.InvestmentEntity, message OutboxMessage) error
+	// Find returns the operations matching the filter in chronological order.
+	Find(ctx context.Context, filter InvestmentFilter) ([]investment_core.InvestmentEntity, error)
+	// List returns up to limit operations with an id greater than after, in id (creation) order.
+	List(ctx context.Context, filter InvestmentFilter, after string, limit int) ([]investment_core.InvestmentEntity, error)
+	FindByID(ctx context.Context, id string) (investment_core.InvestmentEntity, error)
+	// FindByIdempotencyKey returns the operation created for a scheduling request.
+	FindByIdempotencyKey(ctx context.Context, key string) (investment_core.InvestmentEntity, error)
+	// UpdateProfitAndLoss stores the result of a sell, including its day trade part
+	// and the taxes withheld on bond redemptions.
+	UpdateProfitAndLoss(ctx context.Context, sale investment_summary_core.SaleResult) error
+}
+
+type OperationStatusRepository interface {
+	Find(ctx context.Context, id string) (investment_core.OperationStatusEntity, error)
+	// FindByIdempotencyKey returns the latest operation scheduled with the key.
+	FindByIdempotencyKey(ctx context.Context, key string) (investment_core.OperationStatusEntity, error)
+	// Queue records a scheduled operation, resetting a failed one that is sent again.
+	Queue(ctx context.Context, id string, idempotencyKey string) error
+	// UpdateStatus records a transition, creating the row for operations that
+	// were not scheduled through the api.
+	UpdateStatus(ctx context.Context, id string, status string, reason string) error
+}
+
+type SummaryFilter struct {
+	Symbol    string
+	Type      string
+	Brokerage string
+	// due date range (YYYY-MM-DD), only rows with a due date are returned when set
+	DueDateFrom string
+	DueDateTo   string
+}
+
+type SummaryRepository interface {
+	// FindByPosition returns the position of a symbol in a brokerage (non bond investments).
+	FindByPosition(ctx context.Context, symbol, investmentType, brokerage string) (investment_summary_core.InvestmentSummaryEntity, error)
+	// FindByInvestmentID returns the position created by a bond purchase.
+	FindByInvestmentID(ctx context.Context, investmentId string) (investment_summary_core.InvestmentSummaryEntity, error)
+	Find(ctx context.Context, filter SummaryFilter) ([]investment_summary_core.InvestmentSummaryEntity, error)
+	Create(ctx context.Context, entity investment_summary_core.InvestmentSummaryEntity) error
+	Update(ctx context.Context, entity investment_summary_core.InvestmentSummaryEntity) error
+	// Delete removes a summary together with its history.
+	Delete(ctx context.Context, summaryId string) error
+	// SaveHistory copies the current state of the summary into investments_summary_history.
+	SaveHistory(ctx context.Context, summaryId string) error
+	// FindHistory returns the snapshots of a summary, one per operation, in chronological order.
+	FindHistory(ctx context.Context, summaryId string) ([]investment_summary_core.InvestmentSummaryEntity, error)
+	// ReplaceHistory drops the history of operations of a summary and writes the
+	// given snapshots instead. Valuation snapshots are kept.
+	ReplaceHistory(ctx context.Context, summaryId string, snapshots []investment_summary_core.InvestmentSummaryEntity) error
+	// UpdateMarketValue stores the market value of a position and a valuation
+	// snapshot dated date (YYYY-MM-DD) in its history, one per day.
+	UpdateMarketValue(ctx context.Context, summaryId string, marketValue decimal.Decimal, date string) error
+}
+
+// parseDate accepts both the RFC3339 timestamps written by the lambdas and
+// the "YYYY-MM-DD HH:MM:SS" format produced by sqlite datetime('now').
+func parseDate(value string) time.Time {
+	layouts := []string{time.RFC3339, "2006-01-02 15:04:05", "2006-01-02"}
+	for _, layout := range layouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t
+		}
+	}
+	return time.Time{}
+}
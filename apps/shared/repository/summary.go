@@ -0,0 +1,395 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	investment_summary_core "github.com/silasstoffel/invest-tracker/apps/investments_summary/core"
+	"github.com/silasstoffel/invest-tracker/apps/shared/database"
+	"github.com/silasstoffel/invest-tracker/apps/shared/decimal"
+)
+
+const summaryColumns = `id, investment_id, type, symbol, bond_index, bond_rate, quantity, average_price,
+	total_value, market_value, cost, last_operation_date, last_operation_type, due_date, brokerage,
+	redemption_policy_type, created_at, updated_at`
+
+// summaryRow mirrors the investments_summary columns.
+type summaryRow struct {
+	ID                   string          `json:"id"`
+	InvestmentID         string          `json:"investment_id"`
+	Type                 string          `json:"type"`
+	Symbol               string          `json:"symbol"`
+	BondIndex            string          `json:"bond_index"`
+	BondRate             decimal.Decimal `json:"bond_rate"`
+	Quantity             decimal.Decimal `json:"quantity"`
+	AveragePrice         decimal.Decimal `json:"average_price"`
+	TotalValue           decimal.Decimal `json:"total_value"`
+	MarketValue          decimal.Decimal `json:"market_value"`
+	Cost                 decimal.Decimal `json:"cost"`
+	LastOperationDate    string          `json:"last_operation_date"`
+	LastOperationType    string          `json:"last_operation_type"`
+	DueDate              string          `json:"due_date"`
+	Brokerage            string          `json:"brokerage"`
+	RedemptionPolicyType string          `json:"redemption_policy_type"`
+	CreatedAt            string          `json:"created_at"`
+	UpdatedAt            string          `json:"updated_at"`
+}
+
+func (r summaryRow) toEntity() investment_summary_core.InvestmentSummaryEntity {
+	return investment_summary_core.InvestmentSummaryEntity{
+		ID:                   r.ID,
+		InvestmentID:         r.InvestmentID,
+		Type:                 r.Type,
+		Symbol:               r.Symbol,
+		BondIndex:            r.BondIndex,
+		BondRate:             r.BondRate,
+		Quantity:             r.Quantity,
+		AveragePrice:         r.AveragePrice,
+		TotalValue:           r.TotalValue,
+		MarketValue:          r.MarketValue,
+		Cost:                 r.Cost,
+		LastTransactionDate:  parseDate(r.LastOperationDate),
+		LastTransactionType:  r.LastOperationType,
+		DueDate:              r.DueDate,
+		Brokerage:            r.Brokerage,
+		RedemptionPolicyType: r.RedemptionPolicyType,
+		CreatedAt:            parseDate(r.CreatedAt),
+		UpdatedAt:            parseDate(r.UpdatedAt),
+	}
+}
+
+type summaryRepository struct {
+	db database.DB
+}
+
+func NewSummaryRepository(db database.DB) SummaryRepository {
+	return &summaryRepository{db: db}
+}
+
+func (r *summaryRepository) query(ctx context.Context, command string, params ...string) ([]investment_summary_core.InvestmentSummaryEntity, error) {
+	rows, err := r.db.Query(ctx, command, params...)
+	if err != nil {
+		return nil, err
+	}
+
+	var summaryRows []summaryRow
+	if err := database.Scan(rows, &summaryRows); err != nil {
+		return nil, err
+	}
+
+	summaries := make([]investment_summary_core.InvestmentSummaryEntity, 0, len(summaryRows))
+	for _, row := range summaryRows {
+		summaries = append(summaries, row.toEntity())
+	}
+
+	return summaries, nil
+}
+
+func (r *summaryRepository) first(ctx context.Context, command string, params ...string) (investment_summary_core.InvestmentSummaryEntity, error) {
+	summaries, err := r.query(ctx, command, params...)
+	if err != nil {
+		return investment_summary_core.InvestmentSummaryEntity{}, err
+	}
+
+	if len(summaries) == 0 {
+		return investment_summary_core.InvestmentSummaryEntity{}, ErrNotFound
+	}
+
+	return summaries[0], nil
+}
+
+func (r *summaryRepository) FindByPosition(ctx context.Context, symbol, investmentType, brokerage string) (investment_summary_core.InvestmentSummaryEntity, error) {
+	command := "SELECT " + summaryColumns + " FROM investments_summary WHERE symbol = ? AND type = ? AND brokerage = ? LIMIT 1"
+	return r.first(ctx, command, symbol, investmentType, brokerage)
+}
+
+func (r *summaryRepository) FindByInvestmentID(ctx context.Context, investmentId string) (investment_summary_core.InvestmentSummaryEntity, error) {
+	command := "SELECT " + summaryColumns + " FROM investments_summary WHERE investment_id = ? LIMIT 1"
+	return r.first(ctx, command, investmentId)
+}
+
+func (r *summaryRepository) Find(ctx context.Context, filter SummaryFilter) ([]investment_summary_core.InvestmentSummaryEntity, error) {
+	command := "SELECT " + summaryColumns + " FROM investments_summary WHERE 1 = 1"
+	params := []string{}
+
+	if filter.Symbol != "" {
+		command += " AND symbol = ? COLLATE NOCASE"
+		params = append(params, filter.Symbol)
+	}
+
+	if filter.Type != "" {
+		command += " AND type = ?"
+		params = append(params, filter.Type)
+	}
+
+	if filter.Brokerage != "" {
+		command += " AND brokerage = ? COLLATE NOCASE"
+		params = append(params, filter.Brokerage)
+	}
+
+	if filter.DueDateFrom != "" || filter.DueDateTo != "" {
+		command += " AND due_date <> ''"
+	}
+
+	if filter.DueDateFrom != "" {
+		command += " AND due_date >= ?"
+		params = append(params, filter.DueDateFrom)
+	}
+
+	if filter.DueDateTo != "" {
+		command += " AND due_date <= ?"
+		params = append(params, filter.DueDateTo)
+	}
+
+	command += " ORDER BY due_date, brokerage, investment_id"
+
+	return r.query(ctx, command, params...)
+}
+
+func (r *summaryRepository) Create(ctx context.Context, entity investment_summary_core.InvestmentSummaryEntity) error {
+	command := `insert into investments_summary(
+		id, investment_id, brokerage, type, symbol,
+		quantity, average_price, total_value, cost,
+		redemption_policy_type, created_at, updated_at,
+		last_operation_date, last_operation_type, due_date {add_column_name}
+	) values (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, NULLIF(?, ''), ? {add_column_value})`
+
+	params := []string{
+		entity.ID,
+		entity.InvestmentID,
+		entity.Brokerage,
+		entity.Type,
+		entity.Symbol,
+		entity.Quantity.Quantity().String(),
+		entity.AveragePrice.Price().String(),
+		entity.TotalValue.Money().String(),
+		entity.Cost.Money().String(),
+		entity.RedemptionPolicyType,
+		entity.CreatedAt.Format(time.RFC3339),
+		entity.UpdatedAt.Format(time.RFC3339),
+		entity.LastTransactionDate.Format("2006-01-02"),
+		entity.LastTransactionType,
+		entity.DueDate,
+	}
+
+	if entity.BondIndex != "" {
+		command = strings.Replace(command, "{add_column_name}", ", bond_index, bond_rate", 1)
+		command = strings.Replace(command, "{add_column_value}", ",?,?", 1)
+		params = append(params, entity.BondIndex, entity.BondRate.Rate().String())
+	} else {
+		command = strings.Replace(command, "{add_column_name}", "", 1)
+		command = strings.Replace(command, "{add_column_value}", "", 1)
+	}
+
+	return r.db.Exec(ctx, command, params...)
+}
+
+func (r *summaryRepository) Update(ctx context.Context, entity investment_summary_core.InvestmentSummaryEntity) error {
+	params := []string{
+		entity.Quantity.Quantity().String(),
+		entity.AveragePrice.Price().String(),
+		entity.TotalValue.Money().String(),
+		entity.Cost.Money().String(),
+		entity.UpdatedAt.Format(time.RFC3339),
+		entity.LastTransactionDate.Format("2006-01-02"),
+		entity.LastTransactionType,
+		entity.ID,
+	}
+
+	command := "update investments_summary set quantity = ?, average_price = ?, total_value = ?, cost = ?, updated_at = ?, last_operation_date = ?, last_operation_type = NULLIF(?, '') where id = ?"
+
+	if err := r.db.Exec(ctx, command, params...); err != nil {
+		return fmt.Errorf("failure to update summarized investment: %w", err)
+	}
+
+	return nil
+}
+
+func (r *summaryRepository) Delete(ctx context.Context, summaryId string) error {
+	err := r.db.Batch(ctx, []database.Statement{
+		{Sql: "DELETE FROM investments_summary_history WHERE investment_summary_id = ?", Params: []string{summaryId}},
+		{Sql: "DELETE FROM investments_summary WHERE id = ?", Params: []string{summaryId}},
+	})
+
+	if err != nil {
+		return fmt.Errorf("failure to delete summarized investment: %w", err)
+	}
+
+	return nil
+}
+
+func (r *summaryRepository) SaveHistory(ctx context.Context, summaryId string) error {
+	command := `INSERT INTO investments_summary_history(
+    investment_id,
+    last_operation_date,
+    operation_month,
+    operation_year, 
+    brokerage,
+    type,
+    symbol,
+    bond_index,
+    bond_rate,
+    quantity,
+    average_price,
+    total_value,
+    market_value,
+    cost,
+    redemption_policy_type,
+    due_date,
+    last_operation_type,
+    investment_summary_id   
+) SELECT 
+    investment_id,
+    last_operation_date,
+    strftime('%m', last_operation_date) as operation_month,
+    strftime('%Y', last_operation_date) as operation_year,
+    brokerage,
+    type,
+    symbol,
+    bond_index,
+    bond_rate,
+    quantity,
+    average_price,
+    total_value,
+    market_value,
+    cost,
+    redemption_policy_type,
+    due_date,
+    last_operation_type,
+    id as investment_summary_id 
+  FROM investments_summary
+  WHERE id = ?`
+
+	if err := r.db.Exec(ctx, command, summaryId); err != nil {
+		return fmt.Errorf("[save-history]: error when save history. Detail: %w", err)
+	}
+
+	return nil
+}
+
+func (r *summaryRepository) FindHistory(ctx context.Context, summaryId string) ([]investment_summary_core.InvestmentSummaryEntity, error) {
+	command := `SELECT investment_summary_id AS id, investment_id, type, symbol, bond_index, bond_rate, quantity,
+		average_price, total_value, market_value, cost, last_operation_date, last_operation_type, due_date, brokerage,
+		redemption_policy_type, created_at, created_at AS updated_at
+		FROM investments_summary_history
+		WHERE investment_summary_id = ?
+		ORDER BY last_operation_date, id`
+
+	return r.query(ctx, command, summaryId)
+}
+
+// historySnapshot is a history row of ReplaceHistory, sent as JSON.
+type historySnapshot struct {
+	InvestmentID         string `json:"investment_id"`
+	LastOperationDate    string `json:"last_operation_date"`
+	OperationMonth       int    `json:"operation_month"`
+	OperationYear        int    `json:"operation_year"`
+	Brokerage            string `json:"brokerage"`
+	Type                 string `json:"type"`
+	Symbol               string `json:"symbol"`
+	BondIndex            string `json:"bond_index"`
+	BondRate             string `json:"bond_rate"`
+	Quantity             string `json:"quantity"`
+	AveragePrice         string `json:"average_price"`
+	TotalValue           string `json:"total_value"`
+	MarketValue          string `json:"market_value"`
+	Cost                 string `json:"cost"`
+	RedemptionPolicyType string `json:"redemption_policy_type"`
+	DueDate              string `json:"due_date"`
+	LastOperationType    string `json:"last_operation_type"`
+}
+
+func (r *summaryRepository) ReplaceHistory(ctx context.Context, summaryId string, snapshots []investment_summary_core.InvestmentSummaryEntity) error {
+	rows := make([]historySnapshot, 0, len(snapshots))
+	for _, snapshot := range snapshots {
+		rows = append(rows, historySnapshot{
+			InvestmentID:         snapshot.InvestmentID,
+			LastOperationDate:    snapshot.LastTransactionDate.Format("2006-01-02"),
+			OperationMonth:       int(snapshot.LastTransactionDate.Month()),
+			OperationYear:        snapshot.LastTransactionDate.Year(),
+			Brokerage:            snapshot.Brokerage,
+			Type:                 snapshot.Type,
+			Symbol:               snapshot.Symbol,
+			BondIndex:            snapshot.BondIndex,
+			BondRate:             snapshot.BondRate.Rate().String(),
+			Quantity:             snapshot.Quantity.Quantity().String(),
+			AveragePrice:         snapshot.AveragePrice.Price().String(),
+			TotalValue:           snapshot.TotalValue.Money().String(),
+			MarketValue:          snapshot.MarketValue.Money().String(),
+			Cost:                 snapshot.Cost.Money().String(),
+			RedemptionPolicyType: snapshot.RedemptionPolicyType,
+			DueDate:              snapshot.DueDate,
+			LastOperationType:    snapshot.LastTransactionType,
+		})
+	}
+
+	content, err := json.Marshal(rows)
+	if err != nil {
+		return fmt.Errorf("[replace-history]: failure to convert history to json. Detail: %w", err)
+	}
+
+	// the snapshots go as a single JSON parameter, so any history fits in the
+	// parameter limit of D1 and is replaced in one atomic batch
+	command := `INSERT INTO investments_summary_history(
+		investment_id, last_operation_date, operation_month, operation_year, brokerage, type, symbol,
+		bond_index, bond_rate, quantity, average_price, total_value, market_value, cost,
+		redemption_policy_type, due_date, last_operation_type, investment_summary_id
+	) SELECT
+		json_extract(value, '$.investment_id'), json_extract(value, '$.last_operation_date'), json_extract(value, '$.operation_month'),
+		json_extract(value, '$.operation_year'), json_extract(value, '$.brokerage'), json_extract(value, '$.type'), json_extract(value, '$.symbol'),
+		json_extract(value, '$.bond_index'), json_extract(value, '$.bond_rate'), json_extract(value, '$.quantity'), json_extract(value, '$.average_price'),
+		json_extract(value, '$.total_value'), json_extract(value, '$.market_value'), json_extract(value, '$.cost'),
+		json_extract(value, '$.redemption_policy_type'), json_extract(value, '$.due_date'), NULLIF(json_extract(value, '$.last_operation_type'), ''), ?
+	FROM json_each(?)
+	ORDER BY key`
+
+	err = r.db.Batch(ctx, []database.Statement{
+		{
+			Sql:    "DELETE FROM investments_summary_history WHERE investment_summary_id = ? AND last_operation_type IS NOT ?",
+			Params: []string{summaryId, investment_summary_core.ValuationSnapshot},
+		},
+		{Sql: command, Params: []string{summaryId, string(content)}},
+	})
+	if err != nil {
+		return fmt.Errorf("[replace-history]: error when replace history. Detail: %w", err)
+	}
+
+	return nil
+}
+
+func (r *summaryRepository) UpdateMarketValue(ctx context.Context, summaryId string, marketValue decimal.Decimal, date string) error {
+	snapshot := `INSERT INTO investments_summary_history(
+		investment_id, last_operation_date, operation_month, operation_year, brokerage, type, symbol,
+		bond_index, bond_rate, quantity, average_price, total_value, market_value, cost,
+		redemption_policy_type, due_date, last_operation_type, investment_summary_id
+	) SELECT
+		investment_id, ?, strftime('%m', ?), strftime('%Y', ?), brokerage, type, symbol,
+		bond_index, bond_rate, quantity, average_price, total_value, market_value, cost,
+		redemption_policy_type, due_date, ?, id
+	FROM investments_summary
+	WHERE id = ?`
+
+	err := r.db.Batch(ctx, []database.Statement{
+		{
+			Sql:    "UPDATE investments_summary SET market_value = ?, updated_at = ? WHERE id = ?",
+			Params: []string{marketValue.Money().String(), time.Now().UTC().Format(time.RFC3339), summaryId},
+		},
+		{
+			Sql:    "DELETE FROM investments_summary_history WHERE investment_summary_id = ? AND last_operation_type = ? AND last_operation_date = ?",
+			Params: []string{summaryId, investment_summary_core.ValuationSnapshot, date},
+		},
+		{
+			Sql:    snapshot,
+			Params: []string{date, date, date, investment_summary_core.ValuationSnapshot, summaryId},
+		},
+	})
+
+	if err != nil {
+		return fmt.Errorf("failure to update market value of %s: %w", summaryId, err)
+	}
+
+	return nil
+}
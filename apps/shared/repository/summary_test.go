@@ -0,0 +1,62 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	investment_summary_core "github.com/silasstoffel/invest-tracker/apps/investments_summary/core"
+	"github.com/silasstoffel/invest-tracker/apps/shared/database"
+	"github.com/silasstoffel/invest-tracker/apps/shared/decimal"
+)
+
+func TestReplaceHistory(t *testing.T) {
+	ctx := context.Background()
+	sqlite, err := database.NewSQLite(":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sqlite.Close()
+
+	repository := NewSummaryRepository(sqlite)
+	summary := investment_summary_core.InvestmentSummaryEntity{
+		ID: "1", InvestmentID: "01", Type: "stock", Symbol: "VALE3", Brokerage: "xp",
+		Quantity: decimal.NewFromInt(12), AveragePrice: decimal.NewFromInt(60), TotalValue: decimal.NewFromInt(720),
+	}
+	if err := repository.Create(ctx, summary); err != nil {
+		t.Fatal(err)
+	}
+
+	// more snapshots than statements of their own would fit in a D1 query
+	snapshots := []investment_summary_core.InvestmentSummaryEntity{}
+	for i := range 12 {
+		snapshot := summary
+		snapshot.Quantity = decimal.NewFromInt(int64(i + 1))
+		snapshot.LastTransactionDate = time.Date(2025, 1, i+1, 0, 0, 0, 0, time.UTC)
+		snapshot.LastTransactionType = "buy"
+		snapshots = append(snapshots, snapshot)
+	}
+
+	if err := repository.ReplaceHistory(ctx, "1", snapshots); err != nil {
+		t.Fatalf("failure to replace history: %v", err)
+	}
+
+	history, err := repository.FindHistory(ctx, "1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(history) != 12 || !history[11].Quantity.Equal(decimal.NewFromInt(12)) || history[11].LastTransactionDate.Format("2006-01-02") != "2025-01-12" {
+		t.Fatalf("expected the 12 snapshots, got %d", len(history))
+	}
+	if !history[0].TotalValue.Equal(decimal.NewFromInt(720)) || history[0].Symbol != "VALE3" || history[0].LastTransactionType != "buy" {
+		t.Errorf("expected the snapshot values kept, got %+v", history[0])
+	}
+
+	// replaying again swaps the whole history
+	if err := repository.ReplaceHistory(ctx, "1", snapshots[:3]); err != nil {
+		t.Fatalf("failure to replace history: %v", err)
+	}
+	if history, err := repository.FindHistory(ctx, "1"); err != nil || len(history) != 3 {
+		t.Errorf("expected 3 snapshots after the second replay, got %d (%v)", len(history), err)
+	}
+}
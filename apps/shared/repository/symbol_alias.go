@@ -0,0 +1,141 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/silasstoffel/invest-tracker/apps/shared/database"
+	"github.com/silasstoffel/invest-tracker/apps/shared/decimal"
+)
+
+// SymbolAlias maps a ticker that changed (merger, rebranding) to the current one.
+type SymbolAlias struct {
+	OldSymbol string `json:"old_symbol"`
+	NewSymbol string `json:"new_symbol"`
+	// new shares for each old share
+	Ratio         decimal.Decimal `json:"ratio"`
+	EffectiveDate string          `json:"effective_date"`
+}
+
+type SymbolAliasRepository interface {
+	// FindByOldSymbol returns the alias of a ticker that changed.
+	FindByOldSymbol(ctx context.Context, symbol string) (SymbolAlias, error)
+	FindAll(ctx context.Context) ([]SymbolAlias, error)
+	// ChangeSymbol records the alias and moves the operations, positions and
+	// history of the old ticker to the new one in a single batch. Quantities
+	// and unit prices of the operations are converted by the ratio.
+	ChangeSymbol(ctx context.Context, alias SymbolAlias) error
+}
+
+type symbolAliasRepository struct {
+	db database.DB
+}
+
+func NewSymbolAliasRepository(db database.DB) SymbolAliasRepository {
+	return &symbolAliasRepository{db: db}
+}
+
+const symbolAliasColumns = "old_symbol, new_symbol, ratio, effective_date"
+
+func (r *symbolAliasRepository) query(ctx context.Context, command string, params ...string) ([]SymbolAlias, error) {
+	rows, err := r.db.Query(ctx, command, params...)
+	if err != nil {
+		return nil, err
+	}
+
+	aliases := []SymbolAlias{}
+	if err := database.Scan(rows, &aliases); err != nil {
+		return nil, err
+	}
+
+	return aliases, nil
+}
+
+func (r *symbolAliasRepository) FindByOldSymbol(ctx context.Context, symbol string) (SymbolAlias, error) {
+	aliases, err := r.query(ctx, "SELECT "+symbolAliasColumns+" FROM symbol_aliases WHERE old_symbol = ?", symbol)
+	if err != nil {
+		return SymbolAlias{}, err
+	}
+
+	if len(aliases) == 0 {
+		return SymbolAlias{}, ErrNotFound
+	}
+
+	return aliases[0], nil
+}
+
+func (r *symbolAliasRepository) FindAll(ctx context.Context) ([]SymbolAlias, error) {
+	return r.query(ctx, "SELECT "+symbolAliasColumns+" FROM symbol_aliases ORDER BY old_symbol")
+}
+
+func (r *symbolAliasRepository) ChangeSymbol(ctx context.Context, alias SymbolAlias) error {
+	now := time.Now().UTC().Format(time.RFC3339)
+
+	// aliases of older tickers follow the change, so redirects take a single hop
+	statements := []database.Statement{
+		{
+			Sql:    "UPDATE symbol_aliases SET new_symbol = ?, ratio = ROUND(ratio * ?, 6) WHERE new_symbol = ?",
+			Params: []string{alias.NewSymbol, alias.Ratio.Quantity().String(), alias.OldSymbol},
+		},
+	}
+
+	statements = append(statements,
+		database.Statement{
+			Sql: `INSERT INTO symbol_aliases (old_symbol, new_symbol, ratio, effective_date) VALUES (?, ?, ?, ?)
+				ON CONFLICT(old_symbol) DO UPDATE SET new_symbol = excluded.new_symbol, ratio = excluded.ratio, effective_date = excluded.effective_date`,
+			Params: []string{alias.OldSymbol, alias.NewSymbol, alias.Ratio.Quantity().String(), alias.EffectiveDate},
+		},
+		// a ticker renamed back is current again
+		database.Statement{Sql: "DELETE FROM symbol_aliases WHERE old_symbol = ?", Params: []string{alias.NewSymbol}},
+	)
+
+	if !alias.Ratio.Equal(decimal.NewFromInt(1)) {
+		// only trades and corporate actions of shares are measured in shares,
+		// incomes and bonds keep their values; converted in a single statement,
+		// so the batch does not grow with the number of operations, and rounded
+		// to the scale of Quantity and Price
+		ratio := alias.Ratio.Quantity().String()
+		statements = append(statements, database.Statement{
+			Sql: `UPDATE investments SET quantity = ROUND(quantity * ?, 6), unit_price = ROUND(unit_price / ?, 6),
+				day_trade_quantity = ROUND(day_trade_quantity * ?, 6)
+				WHERE symbol = ? AND type != 'bond' AND operation_type IN ('buy', 'sell', 'corporate_action')`,
+			Params: []string{ratio, ratio, ratio, alias.OldSymbol},
+		})
+	}
+
+	statements = append(statements, database.Statement{
+		Sql:    "UPDATE investments SET symbol = ?, updated_at = ? WHERE symbol = ?",
+		Params: []string{alias.NewSymbol, now, alias.OldSymbol},
+	})
+
+	// positions the new ticker already has on the same type and brokerage are
+	// kept, the old ones are dropped and must be rebuilt from the operations
+	conflicting := `SELECT o.id FROM investments_summary o WHERE o.symbol = ? AND o.type != 'bond' AND EXISTS (
+		SELECT 1 FROM investments_summary n WHERE n.symbol = ? AND n.type = o.type AND n.brokerage IS o.brokerage)`
+
+	statements = append(statements,
+		database.Statement{
+			Sql:    "DELETE FROM investments_summary_history WHERE investment_summary_id IN (" + conflicting + ")",
+			Params: []string{alias.OldSymbol, alias.NewSymbol},
+		},
+		database.Statement{
+			Sql:    "DELETE FROM investments_summary WHERE id IN (" + conflicting + ")",
+			Params: []string{alias.OldSymbol, alias.NewSymbol},
+		},
+		database.Statement{
+			Sql:    "UPDATE investments_summary SET symbol = ?, updated_at = ? WHERE symbol = ?",
+			Params: []string{alias.NewSymbol, now, alias.OldSymbol},
+		},
+		database.Statement{
+			Sql:    "UPDATE investments_summary_history SET symbol = ? WHERE symbol = ?",
+			Params: []string{alias.NewSymbol, alias.OldSymbol},
+		},
+	)
+
+	if err := r.db.Batch(ctx, statements); err != nil {
+		return fmt.Errorf("failure to change symbol %s to %s: %w", alias.OldSymbol, alias.NewSymbol, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,81 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	investment_core "github.com/silasstoffel/invest-tracker/apps/investments/core"
+	"github.com/silasstoffel/invest-tracker/apps/shared/database"
+	"github.com/silasstoffel/invest-tracker/apps/shared/decimal"
+)
+
+func TestChangeSymbolWithRatio(t *testing.T) {
+	ctx := context.Background()
+	sqlite, err := database.NewSQLite(":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sqlite.Close()
+
+	// sqlite refuses batches over the D1 limit of bound parameters
+	investments := NewInvestmentRepository(sqlite)
+	aliases := NewSymbolAliasRepository(sqlite)
+
+	// more operations than a statement per operation would fit in a D1 query
+	for i := range 20 {
+		err := investments.Create(ctx, investment_core.InvestmentEntity{
+			ID:            fmt.Sprintf("%02d", i),
+			Type:          "stock",
+			Symbol:        "OLDS3",
+			Brokerage:     "xp",
+			Quantity:      decimal.NewFromInt(10),
+			UnitPrice:     decimal.NewFromInt(10),
+			TotalValue:    decimal.NewFromInt(100),
+			OperationType: investment_core.BuyOperationType,
+			OperationDate: "2025-01-10",
+			CreatedAt:     time.Now().UTC(),
+			UpdatedAt:     time.Now().UTC(),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// a dividend is not measured in shares and keeps its values
+	err = investments.Create(ctx, investment_core.InvestmentEntity{
+		ID: "99", Type: "stock", Symbol: "OLDS3", Brokerage: "xp", Quantity: decimal.NewFromInt(200), UnitPrice: decimal.RequireFromString("0.5"),
+		TotalValue: decimal.NewFromInt(100), OperationType: investment_core.IncomeOperationType, OperationSubtype: "dividend",
+		OperationDate: "2025-02-10", CreatedAt: time.Now().UTC(), UpdatedAt: time.Now().UTC(),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ratio, _ := decimal.NewFromString("0.333333")
+	if err := aliases.ChangeSymbol(ctx, SymbolAlias{OldSymbol: "OLDS3", NewSymbol: "NEWS3", Ratio: ratio, EffectiveDate: "2025-03-01"}); err != nil {
+		t.Fatalf("failure to change symbol: %v", err)
+	}
+
+	operations, err := investments.Find(ctx, InvestmentFilter{Symbol: "NEWS3"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(operations) != 21 {
+		t.Fatalf("expected 21 operations moved, got %d", len(operations))
+	}
+	quantity, _ := decimal.NewFromString("3.33333")
+	price, _ := decimal.NewFromString("30.00003")
+	for _, operation := range operations {
+		if operation.OperationType == investment_core.IncomeOperationType {
+			if !operation.Quantity.Equal(decimal.NewFromInt(200)) || !operation.UnitPrice.Equal(decimal.RequireFromString("0.5")) {
+				t.Errorf("expected the dividend kept, got %s at %s", operation.Quantity, operation.UnitPrice)
+			}
+			continue
+		}
+		if !operation.Quantity.Equal(quantity) || !operation.UnitPrice.Equal(price) {
+			t.Fatalf("expected %s shares at %s, got %s at %s", quantity, price, operation.Quantity, operation.UnitPrice)
+		}
+	}
+}
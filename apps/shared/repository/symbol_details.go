@@ -0,0 +1,41 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/silasstoffel/invest-tracker/apps/shared/database"
+)
+
+// SymbolDetails classifies a symbol, '#' marks values not filled in yet.
+type SymbolDetails struct {
+	ID         string `json:"id"`
+	Type       string `json:"type"`
+	Segment    string `json:"segment"`
+	SubSegment string `json:"sub_segment"`
+}
+
+type SymbolDetailsRepository interface {
+	FindAll(ctx context.Context) ([]SymbolDetails, error)
+}
+
+type symbolDetailsRepository struct {
+	db database.DB
+}
+
+func NewSymbolDetailsRepository(db database.DB) SymbolDetailsRepository {
+	return &symbolDetailsRepository{db: db}
+}
+
+func (r *symbolDetailsRepository) FindAll(ctx context.Context) ([]SymbolDetails, error) {
+	rows, err := r.db.Query(ctx, "SELECT id, type, segment, sub_segment FROM symbol_details ORDER BY id")
+	if err != nil {
+		return nil, err
+	}
+
+	details := []SymbolDetails{}
+	if err := database.Scan(rows, &details); err != nil {
+		return nil, err
+	}
+
+	return details, nil
+}
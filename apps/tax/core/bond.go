@@ -0,0 +1,124 @@
+package tax_core
+
+import (
+	"strings"
+	"time"
+
+	investment_core "github.com/silasstoffel/invest-tracker/apps/investments/core"
+	"github.com/silasstoffel/invest-tracker/apps/shared/decimal"
+)
+
+// iofRates is the share of the gain due as IOF by the number of days the bond
+// was held, redemptions from the 30th day on are exempt.
+var iofRates = []int64{
+	100, 96, 93, 90, 86, 83, 80, 76, 73, 70,
+	66, 63, 60, 56, 53, 50, 46, 43, 40, 36,
+	33, 30, 26, 23, 20, 16, 13, 10, 6, 3,
+}
+
+// exemptBondProducts are exempt from income tax for individuals.
+var exemptBondProducts = map[string]bool{
+	investment_core.LciProduct: true,
+	investment_core.LcaProduct: true,
+	investment_core.CriProduct: true,
+	investment_core.CraProduct: true,
+}
+
+type BondRedemption struct {
+	Symbol string
+	// empty for bonds bought before the product was informed
+	ProductKind string
+	// YYYY-MM-DD
+	PurchaseDate   string
+	RedemptionDate string
+	CostBasis      decimal.Decimal
+	Proceeds       decimal.Decimal
+}
+
+type BondRedemptionTax struct {
+	HoldingDays   int             `json:"holdingDays"`
+	Gain          decimal.Decimal `json:"gain"`
+	Exempt        bool            `json:"exempt"`
+	IofRate       decimal.Decimal `json:"iofRate"`
+	Iof           decimal.Decimal `json:"iof"`
+	IncomeTaxRate decimal.Decimal `json:"incomeTaxRate"`
+	IncomeTax     decimal.Decimal `json:"incomeTax"`
+	NetProceeds   decimal.Decimal `json:"netProceeds"`
+}
+
+// IsExemptBond tells whether the bond is exempt from income tax: LCI, LCA, CRI,
+// CRA and incentivized debentures ("DEBENTURE INCENTIVADA VALE"). Without a
+// product kind, the product is the first word of the symbol ("LCA PRE BTG").
+func IsExemptBond(productKind, symbol string) bool {
+	words := strings.Fields(strings.ToLower(symbol))
+	if productKind == "" && len(words) > 0 {
+		productKind = words[0]
+	}
+	if exemptBondProducts[productKind] {
+		return true
+	}
+
+	if productKind != investment_core.DebentureProduct {
+		return false
+	}
+	for _, word := range words {
+		if word == "incentivada" || word == "incentivadas" {
+			return true
+		}
+	}
+	return false
+}
+
+// IofRate returns the IOF due on the gain of a redemption after days held.
+func IofRate(days int) decimal.Decimal {
+	if days < 0 || days >= len(iofRates) {
+		return decimal.Zero
+	}
+	return decimal.NewFromInt(iofRates[days]).Div(decimal.NewFromInt(100))
+}
+
+// IncomeTaxRate is the regressive table of fixed income by days held.
+func IncomeTaxRate(days int) decimal.Decimal {
+	switch {
+	case days <= 180:
+		return decimal.RequireFromString("0.225")
+	case days <= 360:
+		return decimal.RequireFromString("0.20")
+	case days <= 720:
+		return decimal.RequireFromString("0.175")
+	default:
+		return decimal.RequireFromString("0.15")
+	}
+}
+
+// RedemptionTax computes the taxes withheld at source on a bond redemption.
+// IOF is charged on the gain of redemptions within 30 days, exempt products
+// included, and income tax on the gain left after the IOF.
+func RedemptionTax(redemption BondRedemption) BondRedemptionTax {
+	purchase, _ := time.Parse("2006-01-02", redemption.PurchaseDate)
+	redeemed, _ := time.Parse("2006-01-02", redemption.RedemptionDate)
+	days := int(redeemed.Sub(purchase).Hours() / 24)
+
+	tax := BondRedemptionTax{
+		HoldingDays:   days,
+		Gain:          redemption.Proceeds.Sub(redemption.CostBasis).Money(),
+		Exempt:        IsExemptBond(redemption.ProductKind, redemption.Symbol),
+		IofRate:       IofRate(days),
+		Iof:           decimal.Zero,
+		IncomeTaxRate: IncomeTaxRate(days),
+		IncomeTax:     decimal.Zero,
+	}
+
+	if tax.Gain.IsPositive() {
+		tax.Iof = tax.Gain.Mul(tax.IofRate).Money()
+		if !tax.Exempt {
+			tax.IncomeTax = tax.Gain.Sub(tax.Iof).Mul(tax.IncomeTaxRate).Money()
+		}
+	}
+	if tax.Exempt {
+		tax.IncomeTaxRate = decimal.Zero
+	}
+
+	tax.NetProceeds = redemption.Proceeds.Sub(tax.Iof).Sub(tax.IncomeTax).Money()
+	return tax
+}
@@ -0,0 +1,59 @@
+package tax_core
+
+import (
+	"testing"
+
+	"github.com/silasstoffel/invest-tracker/apps/shared/decimal"
+)
+
+func TestRedemptionTax(t *testing.T) {
+	cases := []struct {
+		name       string
+		redemption BondRedemption
+		iof        string
+		incomeTax  string
+		net        string
+	}{
+		{
+			name:       "cdb within 30 days pays iof and 22.5%",
+			redemption: BondRedemption{Symbol: "CDB BTG", PurchaseDate: "2025-01-01", RedemptionDate: "2025-01-11", CostBasis: decimal.NewFromInt(1000), Proceeds: decimal.NewFromInt(1100)},
+			// 66% of 100, then 22.5% of 34
+			iof: "66", incomeTax: "7.65", net: "1026.35",
+		},
+		{
+			name:       "cdb held for a year pays 17.5%",
+			redemption: BondRedemption{Symbol: "CDB BTG", PurchaseDate: "2024-01-01", RedemptionDate: "2025-01-01", CostBasis: decimal.NewFromInt(1000), Proceeds: decimal.NewFromInt(1100)},
+			iof:        "0", incomeTax: "17.5", net: "1082.5",
+		},
+		{
+			name:       "cdb held for more than two years pays 15%",
+			redemption: BondRedemption{Symbol: "CDB BTG", PurchaseDate: "2022-01-01", RedemptionDate: "2025-01-01", CostBasis: decimal.NewFromInt(1000), Proceeds: decimal.NewFromInt(1300)},
+			iof:        "0", incomeTax: "45", net: "1255",
+		},
+		{
+			name:       "lca is exempt",
+			redemption: BondRedemption{Symbol: "LCA PRE BTG", PurchaseDate: "2024-06-01", RedemptionDate: "2025-01-01", CostBasis: decimal.NewFromInt(1000), Proceeds: decimal.NewFromInt(1100)},
+			iof:        "0", incomeTax: "0", net: "1100",
+		},
+		{
+			name:       "a loss pays nothing",
+			redemption: BondRedemption{Symbol: "CDB BTG", PurchaseDate: "2025-01-01", RedemptionDate: "2025-01-05", CostBasis: decimal.NewFromInt(1000), Proceeds: decimal.NewFromInt(990)},
+			iof:        "0", incomeTax: "0", net: "990",
+		},
+	}
+
+	for _, c := range cases {
+		tax := RedemptionTax(c.redemption)
+		if !tax.Iof.Equal(decimal.RequireFromString(c.iof)) || !tax.IncomeTax.Equal(decimal.RequireFromString(c.incomeTax)) || !tax.NetProceeds.Equal(decimal.RequireFromString(c.net)) {
+			t.Errorf("%s: expected iof %s, income tax %s and net %s, got %s, %s and %s",
+				c.name, c.iof, c.incomeTax, c.net, tax.Iof, tax.IncomeTax, tax.NetProceeds)
+		}
+	}
+
+	if !IsExemptBond("", "Debenture Incentivada Vale") || IsExemptBond("", "DEBENTURE VALE") {
+		t.Error("expected only incentivized debentures to be exempt")
+	}
+	if !IsExemptBond("lca", "BTG PRE 2028") || IsExemptBond("cdb", "LCA PRE BTG") {
+		t.Error("expected the product kind to win over the symbol")
+	}
+}
@@ -0,0 +1,158 @@
+package tax_core
+
+import (
+	"sort"
+
+	investment_core "github.com/silasstoffel/invest-tracker/apps/investments/core"
+	"github.com/silasstoffel/invest-tracker/apps/shared/decimal"
+)
+
+// CategoryOf classifies a sale. Bonds are taxed at source and have no category.
+func CategoryOf(sale Sale) (string, bool) {
+	if sale.Type == investment_core.BondInvestmentType {
+		return "", false
+	}
+	if sale.DayTrade {
+		return DayTradeCategory, true
+	}
+	if sale.Type == investment_core.FiiInvestmentType {
+		return FiiCategory, true
+	}
+	return SwingTradeCategory, true
+}
+
+var rates = map[string]decimal.Decimal{
+	SwingTradeCategory: SwingTradeRate,
+	FiiCategory:        FiiRate,
+	DayTradeCategory:   DayTradeRate,
+}
+
+// Calculate computes the tax of each month with sales, in chronological order.
+// Losses, withholding and DARFs below the minimum are carried between months,
+// so sales must include the whole history, not only the months to report.
+func Calculate(sales []Sale) []MonthlyTax {
+	months := map[string][]Sale{}
+	for _, sale := range sales {
+		if _, ok := CategoryOf(sale); !ok {
+			continue
+		}
+		month := sale.OperationDate[:7]
+		months[month] = append(months[month], sale)
+	}
+
+	keys := make([]string, 0, len(months))
+	for month := range months {
+		keys = append(keys, month)
+	}
+	sort.Strings(keys)
+
+	losses := map[string]decimal.Decimal{}
+	withheldCarried := decimal.Zero
+	carriedDue := decimal.Zero
+	result := make([]MonthlyTax, 0, len(keys))
+
+	for _, month := range keys {
+		monthly := MonthlyTax{
+			Month:       month,
+			Tax:         decimal.Zero,
+			Withheld:    decimal.Zero,
+			PreviousDue: carriedDue,
+		}
+
+		for _, category := range []string{SwingTradeCategory, FiiCategory, DayTradeCategory} {
+			categoryTax, ok := calculateCategory(category, months[month], losses)
+			if !ok {
+				continue
+			}
+			monthly.Categories = append(monthly.Categories, categoryTax)
+			monthly.Tax = monthly.Tax.Add(categoryTax.Tax)
+			monthly.Withheld = monthly.Withheld.Add(categoryTax.Withheld)
+		}
+
+		available := withheldCarried.Add(monthly.Withheld)
+		monthly.WithheldUsed = decimal.Min(available, monthly.Tax)
+		monthly.WithheldCarried = available.Sub(monthly.WithheldUsed)
+		withheldCarried = monthly.WithheldCarried
+
+		due := monthly.Tax.Sub(monthly.WithheldUsed).Add(carriedDue)
+		if due.LessThan(MinimumDarf) {
+			monthly.Darf = decimal.Zero
+			monthly.CarriedDue = due
+		} else {
+			monthly.Darf = due
+			monthly.CarriedDue = decimal.Zero
+		}
+		carriedDue = monthly.CarriedDue
+
+		result = append(result, monthly)
+	}
+
+	return result
+}
+
+func calculateCategory(category string, sales []Sale, losses map[string]decimal.Decimal) (CategoryTax, bool) {
+	tax := CategoryTax{
+		Category:     category,
+		Sales:        decimal.Zero,
+		Result:       decimal.Zero,
+		ExemptResult: decimal.Zero,
+		LossUsed:     decimal.Zero,
+		TaxableBase:  decimal.Zero,
+		Rate:         rates[category],
+		Withheld:     decimal.Zero,
+	}
+
+	stockSales := decimal.Zero
+	stockResult := decimal.Zero
+	found := false
+
+	for _, sale := range sales {
+		if c, _ := CategoryOf(sale); c != category {
+			continue
+		}
+		found = true
+		tax.Sales = tax.Sales.Add(sale.Proceeds)
+		tax.Result = tax.Result.Add(sale.Result)
+
+		if sale.Type == investment_core.StockInvestmentType {
+			stockSales = stockSales.Add(sale.Proceeds)
+			stockResult = stockResult.Add(sale.Result)
+		}
+	}
+
+	if !found {
+		return tax, false
+	}
+
+	result := tax.Result
+	if category == SwingTradeCategory && stockResult.IsPositive() && !stockSales.GreaterThan(StockExemptionLimit) {
+		tax.ExemptResult = stockResult
+		result = result.Sub(stockResult)
+	}
+
+	loss := losses[category]
+	if result.IsNegative() {
+		loss = loss.Add(result.Neg())
+	} else {
+		tax.LossUsed = decimal.Min(loss, result)
+		loss = loss.Sub(tax.LossUsed)
+		tax.TaxableBase = result.Sub(tax.LossUsed)
+	}
+	losses[category] = loss
+	tax.LossCarried = loss
+
+	tax.Tax = tax.TaxableBase.Mul(tax.Rate).Money()
+
+	if category == DayTradeCategory {
+		if tax.Result.IsPositive() {
+			tax.Withheld = tax.Result.Mul(DayTradeWithholdingRate).Money()
+		}
+	} else {
+		withheld := tax.Sales.Mul(SalesWithholdingRate).Money()
+		if !withheld.LessThan(MinimumWithholding) {
+			tax.Withheld = withheld
+		}
+	}
+
+	return tax, true
+}
@@ -0,0 +1,81 @@
+package tax_core
+
+import (
+	"testing"
+
+	"github.com/silasstoffel/invest-tracker/apps/shared/decimal"
+)
+
+func sale(date, investmentType string, dayTrade bool, proceeds, result string) Sale {
+	return Sale{
+		OperationDate: date,
+		Type:          investmentType,
+		DayTrade:      dayTrade,
+		Proceeds:      decimal.RequireFromString(proceeds),
+		Result:        decimal.RequireFromString(result),
+	}
+}
+
+func TestCalculate(t *testing.T) {
+	sales := []Sale{
+		// exempt stock gain, fii loss carried
+		sale("2025-01-10", "stock", false, "15000", "2000"),
+		sale("2025-01-15", "fii", false, "3000", "-500"),
+		// bonds are taxed at source
+		sale("2025-01-20", "bond", false, "10000", "800"),
+		// stock sales above R$20k, fii gain compensates january loss
+		sale("2025-02-10", "stock", false, "30000", "3000"),
+		sale("2025-02-12", "fii", false, "10000", "1500"),
+		// day trade taxed apart from the swing trade loss
+		sale("2025-03-05", "etf", false, "5000", "-1000"),
+		sale("2025-03-06", "stock", true, "2000", "100"),
+		// DARF below R$10 is carried to the next month
+		sale("2025-04-07", "stock", true, "2000", "40"),
+		sale("2025-05-07", "stock", true, "2000", "30"),
+	}
+
+	months := Calculate(sales)
+	if len(months) != 5 {
+		t.Fatalf("expected 5 months, got %d", len(months))
+	}
+
+	expected := []struct {
+		month      string
+		tax        string
+		darf       string
+		carriedDue string
+	}{
+		{"2025-01", "0", "0", "0"},
+		{"2025-02", "650", "648.5", "0"},
+		{"2025-03", "20", "19", "0"},
+		{"2025-04", "8", "0", "7.6"},
+		{"2025-05", "6", "13.3", "0"},
+	}
+
+	for i, e := range expected {
+		m := months[i]
+		if m.Month != e.month || !m.Tax.Equal(decimal.RequireFromString(e.tax)) ||
+			!m.Darf.Equal(decimal.RequireFromString(e.darf)) || !m.CarriedDue.Equal(decimal.RequireFromString(e.carriedDue)) {
+			t.Errorf("expected %s with tax %s, darf %s and carried %s, got %s with tax %s, darf %s and carried %s",
+				e.month, e.tax, e.darf, e.carriedDue, m.Month, m.Tax, m.Darf, m.CarriedDue)
+		}
+	}
+
+	january := months[0].Categories
+	if !january[0].ExemptResult.Equal(decimal.NewFromInt(2000)) {
+		t.Errorf("expected exempt stock gain of 2000, got %s", january[0].ExemptResult)
+	}
+	if !january[1].LossCarried.Equal(decimal.NewFromInt(500)) {
+		t.Errorf("expected fii loss of 500 carried, got %s", january[1].LossCarried)
+	}
+
+	february := months[1].Categories
+	if !february[1].LossUsed.Equal(decimal.NewFromInt(500)) || !february[1].Tax.Equal(decimal.NewFromInt(200)) {
+		t.Errorf("expected fii loss of 500 used and tax of 200, got %s and %s", february[1].LossUsed, february[1].Tax)
+	}
+
+	march := months[2].Categories
+	if !march[0].LossCarried.Equal(decimal.NewFromInt(1000)) || !march[1].Tax.Equal(decimal.NewFromInt(20)) {
+		t.Errorf("expected swing trade loss of 1000 carried and day trade tax of 20, got %s and %s", march[0].LossCarried, march[1].Tax)
+	}
+}
@@ -0,0 +1,72 @@
+package tax_core
+
+import (
+	"github.com/silasstoffel/invest-tracker/apps/shared/decimal"
+)
+
+const (
+	// taxation categories, losses are only compensated inside the same category
+	SwingTradeCategory = "swing_trade"
+	FiiCategory        = "fii"
+	DayTradeCategory   = "day_trade"
+)
+
+var (
+	// common stock sales up to this amount in a month have exempt gains
+	StockExemptionLimit = decimal.NewFromInt(20000)
+	// a DARF below this amount is paid together with the next one
+	MinimumDarf = decimal.NewFromInt(10)
+
+	SwingTradeRate = decimal.RequireFromString("0.15")
+	FiiRate        = decimal.RequireFromString("0.20")
+	DayTradeRate   = decimal.RequireFromString("0.20")
+
+	// IRRF withheld by the brokerage: 0.005% of the sales ("dedo-duro") and 1% of day trade gains
+	SalesWithholdingRate    = decimal.RequireFromString("0.00005")
+	DayTradeWithholdingRate = decimal.RequireFromString("0.01")
+	// the sales withholding is not retained below this amount
+	MinimumWithholding = decimal.NewFromInt(1)
+)
+
+// Sale is a realized sell already classified for taxation.
+type Sale struct {
+	OperationDate string
+	Type          string
+	DayTrade      bool
+	Proceeds      decimal.Decimal
+	// result after fees
+	Result decimal.Decimal
+}
+
+type CategoryTax struct {
+	Category string          `json:"category"`
+	Sales    decimal.Decimal `json:"sales"`
+	Result   decimal.Decimal `json:"result"`
+	// gains of common stock sales exempt by the R$20k limit
+	ExemptResult decimal.Decimal `json:"exemptResult"`
+	LossUsed     decimal.Decimal `json:"lossUsed"`
+	// loss left to compensate in the next months
+	LossCarried decimal.Decimal `json:"lossCarried"`
+	TaxableBase decimal.Decimal `json:"taxableBase"`
+	Rate        decimal.Decimal `json:"rate"`
+	Tax         decimal.Decimal `json:"tax"`
+	Withheld    decimal.Decimal `json:"withheld"`
+}
+
+type MonthlyTax struct {
+	// YYYY-MM
+	Month      string          `json:"month"`
+	Categories []CategoryTax   `json:"categories"`
+	Tax        decimal.Decimal `json:"tax"`
+	Withheld   decimal.Decimal `json:"withheld"`
+	// withholding of previous months deducted this month
+	WithheldUsed decimal.Decimal `json:"withheldUsed"`
+	// withholding left to deduct in the next months
+	WithheldCarried decimal.Decimal `json:"withheldCarried"`
+	// tax below the minimum DARF brought from previous months
+	PreviousDue decimal.Decimal `json:"previousDue"`
+	// amount to pay, zero when below the minimum DARF
+	Darf decimal.Decimal `json:"darf"`
+	// amount left for the next DARF
+	CarriedDue decimal.Decimal `json:"carriedDue"`
+}
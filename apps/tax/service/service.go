@@ -0,0 +1,48 @@
+package tax_service
+
+import (
+	"context"
+	"fmt"
+
+	reports_service "github.com/silasstoffel/invest-tracker/apps/reports/service"
+	"github.com/silasstoffel/invest-tracker/apps/shared/repository"
+	tax_core "github.com/silasstoffel/invest-tracker/apps/tax/core"
+)
+
+type Service struct {
+	reports *reports_service.Service
+}
+
+func NewService(investments repository.InvestmentRepository) *Service {
+	return &Service{reports: reports_service.NewService(investments)}
+}
+
+// MonthlyTax returns the tax of the months of year. The whole history is
+// calculated so the losses and withholding carried into the year are right.
+func (s *Service) MonthlyTax(ctx context.Context, year int) ([]tax_core.MonthlyTax, error) {
+	realized, err := s.reports.RealizedSales(ctx, repository.InvestmentFilter{})
+	if err != nil {
+		return nil, err
+	}
+
+	sales := make([]tax_core.Sale, 0, len(realized))
+	for _, sale := range realized {
+		sales = append(sales, tax_core.Sale{
+			OperationDate: sale.OperationDate,
+			Type:          sale.Type,
+			DayTrade:      sale.DayTrade,
+			Proceeds:      sale.Proceeds,
+			Result:        sale.NetResult,
+		})
+	}
+
+	months := []tax_core.MonthlyTax{}
+	prefix := fmt.Sprintf("%d-", year)
+	for _, month := range tax_core.Calculate(sales) {
+		if year == 0 || month.Month[:5] == prefix {
+			months = append(months, month)
+		}
+	}
+
+	return months, nil
+}
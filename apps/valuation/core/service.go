@@ -0,0 +1,95 @@
+package valuation_core
+
+import (
+	"fmt"
+	"sort"
+
+	investment_core "github.com/silasstoffel/invest-tracker/apps/investments/core"
+	investment_summary_core "github.com/silasstoffel/invest-tracker/apps/investments_summary/core"
+	"github.com/silasstoffel/invest-tracker/apps/shared/decimal"
+)
+
+// IsQuoted tells whether the position is valued by market quotes. Bonds are
+// valued by the accrual of their index instead.
+func IsQuoted(summary investment_summary_core.InvestmentSummaryEntity) bool {
+	switch summary.Type {
+	case investment_core.StockInvestmentType, investment_core.FiiInvestmentType, investment_core.EtfInvestmentType, investment_core.ReitInvestmentType:
+		return true
+	default:
+		return false
+	}
+}
+
+func percentage(value, total decimal.Decimal) decimal.Decimal {
+	if total.IsZero() {
+		return decimal.Zero
+	}
+	return value.Div(total).Mul(decimal.NewFromInt(100)).Round(2)
+}
+
+// Value prices a position at the given unit price.
+func Value(summary investment_summary_core.InvestmentSummaryEntity, price decimal.Decimal) PositionValuation {
+	valuation := ValueAt(summary, summary.Quantity.Mul(price))
+	valuation.Price = price.Price()
+	return valuation
+}
+
+// ValueAt values a position at a known market value, such as the one stored by
+// the last revaluation.
+func ValueAt(summary investment_summary_core.InvestmentSummaryEntity, marketValue decimal.Decimal) PositionValuation {
+	marketValue = marketValue.Money()
+	unrealized := marketValue.Sub(summary.TotalValue).Money()
+	price := decimal.Zero
+	if summary.Quantity.IsPositive() {
+		price = marketValue.Div(summary.Quantity).Price()
+	}
+
+	return PositionValuation{
+		SummaryID:               summary.ID,
+		Symbol:                  summary.Symbol,
+		Type:                    summary.Type,
+		Brokerage:               summary.Brokerage,
+		Quantity:                summary.Quantity,
+		AveragePrice:            summary.AveragePrice,
+		TotalValue:              summary.TotalValue,
+		Price:                   price,
+		MarketValue:             marketValue,
+		UnrealizedPnl:           unrealized,
+		UnrealizedPnlPercentage: percentage(unrealized, summary.TotalValue),
+	}
+}
+
+// Describe names a position in the list of unpriced positions.
+func Describe(summary investment_summary_core.InvestmentSummaryEntity) string {
+	return fmt.Sprintf("%s (%s) at %s", summary.Symbol, summary.Type, summary.Brokerage)
+}
+
+// Build sums the valuations of the positions of the portfolio.
+func Build(date string, positions []PositionValuation, unpriced []string) Valuation {
+	valuation := Valuation{
+		Date:          date,
+		Positions:     positions,
+		Unpriced:      unpriced,
+		TotalValue:    decimal.Zero,
+		MarketValue:   decimal.Zero,
+		UnrealizedPnl: decimal.Zero,
+	}
+
+	for _, position := range positions {
+		valuation.TotalValue = valuation.TotalValue.Add(position.TotalValue)
+		valuation.MarketValue = valuation.MarketValue.Add(position.MarketValue)
+		valuation.UnrealizedPnl = valuation.UnrealizedPnl.Add(position.UnrealizedPnl)
+	}
+	valuation.UnrealizedPnlPercentage = percentage(valuation.UnrealizedPnl, valuation.TotalValue)
+
+	sort.Slice(valuation.Positions, func(i, j int) bool {
+		a, b := valuation.Positions[i], valuation.Positions[j]
+		if a.Symbol != b.Symbol {
+			return a.Symbol < b.Symbol
+		}
+		return a.Brokerage < b.Brokerage
+	})
+	sort.Strings(valuation.Unpriced)
+
+	return valuation
+}
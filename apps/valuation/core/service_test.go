@@ -0,0 +1,42 @@
+package valuation_core
+
+import (
+	"testing"
+
+	investment_summary_core "github.com/silasstoffel/invest-tracker/apps/investments_summary/core"
+	"github.com/silasstoffel/invest-tracker/apps/shared/decimal"
+)
+
+func TestBuild(t *testing.T) {
+	vale := investment_summary_core.InvestmentSummaryEntity{
+		ID: "1", Symbol: "VALE3", Type: "stock", Brokerage: "xp",
+		Quantity: decimal.NewFromInt(10), AveragePrice: decimal.NewFromInt(50), TotalValue: decimal.NewFromInt(500),
+	}
+	hglg := investment_summary_core.InvestmentSummaryEntity{
+		ID: "2", Symbol: "HGLG11", Type: "fii", Brokerage: "xp",
+		Quantity: decimal.NewFromInt(2), AveragePrice: decimal.NewFromInt(250), TotalValue: decimal.NewFromInt(500),
+	}
+
+	positions := []PositionValuation{
+		Value(vale, decimal.RequireFromString("61.25")),
+		Value(hglg, decimal.RequireFromString("200")),
+	}
+	valuation := Build("2025-03-10", positions, []string{"ITSA4 (stock) at xp"})
+
+	first := valuation.Positions[0]
+	if first.Symbol != "HGLG11" || !first.UnrealizedPnl.Equal(decimal.NewFromInt(-100)) || !first.UnrealizedPnlPercentage.Equal(decimal.NewFromInt(-20)) {
+		t.Errorf("expected HGLG11 first with a loss of 100 (-20%%), got %+v", first)
+	}
+
+	second := valuation.Positions[1]
+	if !second.MarketValue.Equal(decimal.RequireFromString("612.5")) || !second.UnrealizedPnl.Equal(decimal.RequireFromString("112.5")) {
+		t.Errorf("expected VALE3 worth 612.50 with a gain of 112.50, got %+v", second)
+	}
+
+	if !valuation.MarketValue.Equal(decimal.RequireFromString("1012.5")) || !valuation.UnrealizedPnlPercentage.Equal(decimal.RequireFromString("1.25")) {
+		t.Errorf("expected the portfolio worth 1012.50 (+1.25%%), got %s (%s%%)", valuation.MarketValue, valuation.UnrealizedPnlPercentage)
+	}
+	if len(valuation.Unpriced) != 1 {
+		t.Errorf("expected the unpriced position to be reported, got %v", valuation.Unpriced)
+	}
+}
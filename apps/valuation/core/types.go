@@ -0,0 +1,32 @@
+package valuation_core
+
+import (
+	"github.com/silasstoffel/invest-tracker/apps/shared/decimal"
+)
+
+type PositionValuation struct {
+	SummaryID    string          `json:"summaryId"`
+	Symbol       string          `json:"symbol"`
+	Type         string          `json:"type"`
+	Brokerage    string          `json:"brokerage"`
+	Quantity     decimal.Decimal `json:"quantity"`
+	AveragePrice decimal.Decimal `json:"averagePrice"`
+	// invested value, quantity times average price
+	TotalValue    decimal.Decimal `json:"totalValue"`
+	Price         decimal.Decimal `json:"price"`
+	MarketValue   decimal.Decimal `json:"marketValue"`
+	UnrealizedPnl decimal.Decimal `json:"unrealizedPnl"`
+	// unrealized pnl over the total value, in percent
+	UnrealizedPnlPercentage decimal.Decimal `json:"unrealizedPnlPercentage"`
+}
+
+type Valuation struct {
+	Date      string              `json:"date"`
+	Positions []PositionValuation `json:"positions"`
+	// open positions without a price, left out of the totals
+	Unpriced                []string        `json:"unpriced"`
+	TotalValue              decimal.Decimal `json:"totalValue"`
+	MarketValue             decimal.Decimal `json:"marketValue"`
+	UnrealizedPnl           decimal.Decimal `json:"unrealizedPnl"`
+	UnrealizedPnlPercentage decimal.Decimal `json:"unrealizedPnlPercentage"`
+}
@@ -0,0 +1,113 @@
+package valuation_service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	investment_core "github.com/silasstoffel/invest-tracker/apps/investments/core"
+	investment_summary_core "github.com/silasstoffel/invest-tracker/apps/investments_summary/core"
+	portfolio_core "github.com/silasstoffel/invest-tracker/apps/portfolio/core"
+	"github.com/silasstoffel/invest-tracker/apps/shared/quotes"
+	"github.com/silasstoffel/invest-tracker/apps/shared/repository"
+	valuation_core "github.com/silasstoffel/invest-tracker/apps/valuation/core"
+)
+
+type Service struct {
+	summaries repository.SummaryRepository
+	quotes    quotes.QuoteProvider
+}
+
+// NewService builds the valuation service. provider may be nil when only the
+// stored valuation is read.
+func NewService(summaries repository.SummaryRepository, provider quotes.QuoteProvider) *Service {
+	return &Service{summaries: summaries, quotes: provider}
+}
+
+// openPositions returns the open positions, bonds included when withBonds is set.
+func (s *Service) openPositions(ctx context.Context, today time.Time, withBonds bool) ([]investment_summary_core.InvestmentSummaryEntity, error) {
+	summaries, err := s.summaries.Find(ctx, repository.SummaryFilter{})
+	if err != nil {
+		return nil, fmt.Errorf("failure to read investments summary: %w", err)
+	}
+
+	positions := []investment_summary_core.InvestmentSummaryEntity{}
+	for _, summary := range summaries {
+		quoted := valuation_core.IsQuoted(summary)
+		if (quoted || withBonds && summary.Type == investment_core.BondInvestmentType) && portfolio_core.IsOpen(summary, today) {
+			positions = append(positions, summary)
+		}
+	}
+	return positions, nil
+}
+
+// Revalue prices every open stock, FII, ETF and REIT position with the latest
+// quote, stores its market value and a valuation snapshot.
+func (s *Service) Revalue(ctx context.Context, today time.Time) (valuation_core.Valuation, error) {
+	if s.quotes == nil {
+		return valuation_core.Valuation{}, fmt.Errorf("a quote provider is required to revalue positions")
+	}
+
+	positions, err := s.openPositions(ctx, today, false)
+	if err != nil {
+		return valuation_core.Valuation{}, err
+	}
+
+	symbols := []string{}
+	seen := map[string]bool{}
+	for _, position := range positions {
+		if !seen[position.Symbol] {
+			seen[position.Symbol] = true
+			symbols = append(symbols, position.Symbol)
+		}
+	}
+
+	prices, err := s.quotes.Quotes(ctx, symbols)
+	if err != nil {
+		return valuation_core.Valuation{}, fmt.Errorf("failure to read quotes: %w", err)
+	}
+
+	date := today.Format("2006-01-02")
+	valuations := []valuation_core.PositionValuation{}
+	unpriced := []string{}
+	for _, position := range positions {
+		quote, ok := prices[position.Symbol]
+		if !ok || !quote.Price.IsPositive() {
+			unpriced = append(unpriced, valuation_core.Describe(position))
+			continue
+		}
+
+		valuation := valuation_core.Value(position, quote.Price)
+		quoteDate := quote.Date
+		if quoteDate == "" {
+			quoteDate = date
+		}
+		if err := s.summaries.UpdateMarketValue(ctx, position.ID, valuation.MarketValue, quoteDate); err != nil {
+			return valuation_core.Valuation{}, fmt.Errorf("failure to update market value of %s: %w", valuation_core.Describe(position), err)
+		}
+		valuations = append(valuations, valuation)
+	}
+
+	return valuation_core.Build(date, valuations, unpriced), nil
+}
+
+// Current values the open positions with the market value stored by the last
+// revaluation, or by the accrual for bonds.
+func (s *Service) Current(ctx context.Context, today time.Time) (valuation_core.Valuation, error) {
+	positions, err := s.openPositions(ctx, today, true)
+	if err != nil {
+		return valuation_core.Valuation{}, err
+	}
+
+	valuations := []valuation_core.PositionValuation{}
+	unpriced := []string{}
+	for _, position := range positions {
+		if !position.MarketValue.IsPositive() {
+			unpriced = append(unpriced, valuation_core.Describe(position))
+			continue
+		}
+		valuations = append(valuations, valuation_core.ValueAt(position, position.MarketValue))
+	}
+
+	return valuation_core.Build(today.Format("2006-01-02"), valuations, unpriced), nil
+}
@@ -0,0 +1,80 @@
+package valuation_service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	investment_summary_core "github.com/silasstoffel/invest-tracker/apps/investments_summary/core"
+	"github.com/silasstoffel/invest-tracker/apps/shared/database"
+	"github.com/silasstoffel/invest-tracker/apps/shared/decimal"
+	"github.com/silasstoffel/invest-tracker/apps/shared/quotes"
+	"github.com/silasstoffel/invest-tracker/apps/shared/repository"
+)
+
+type fixedQuotes map[string]quotes.Quote
+
+func (f fixedQuotes) Quotes(ctx context.Context, symbols []string) (map[string]quotes.Quote, error) {
+	return f, nil
+}
+
+func TestRevalue(t *testing.T) {
+	ctx := context.Background()
+	db, err := database.NewSQLite(":memory:")
+	if err != nil {
+		t.Fatalf("failure to open database: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	summaries := repository.NewSummaryRepository(db)
+
+	positions := []investment_summary_core.InvestmentSummaryEntity{
+		{ID: "1", Type: "stock", Symbol: "VALE3", Brokerage: "xp", Quantity: decimal.NewFromInt(10), AveragePrice: decimal.NewFromInt(50), TotalValue: decimal.NewFromInt(500), LastTransactionDate: time.Date(2025, 1, 10, 0, 0, 0, 0, time.UTC)},
+		{ID: "2", Type: "fii", Symbol: "HGLG11", Brokerage: "xp", Quantity: decimal.NewFromInt(2), AveragePrice: decimal.NewFromInt(250), TotalValue: decimal.NewFromInt(500), LastTransactionDate: time.Date(2025, 1, 10, 0, 0, 0, 0, time.UTC)},
+		{ID: "3", Type: "cdb", Symbol: "CDB BTG", Brokerage: "btg", Quantity: decimal.NewFromInt(1), AveragePrice: decimal.NewFromInt(1000), TotalValue: decimal.NewFromInt(1000), LastTransactionDate: time.Date(2025, 1, 10, 0, 0, 0, 0, time.UTC)},
+	}
+	for _, position := range positions {
+		if err := summaries.Create(ctx, position); err != nil {
+			t.Fatalf("failure to create summary: %v", err)
+		}
+	}
+
+	provider := fixedQuotes{"VALE3": {Symbol: "VALE3", Price: decimal.NewFromInt(55), Date: "2025-03-07"}}
+	service := NewService(summaries, provider)
+	today := time.Date(2025, 3, 10, 0, 0, 0, 0, time.UTC)
+
+	valuation, err := service.Revalue(ctx, today)
+	if err != nil {
+		t.Fatalf("failure to revalue: %v", err)
+	}
+	if len(valuation.Positions) != 1 || len(valuation.Unpriced) != 1 {
+		t.Fatalf("expected VALE3 priced and HGLG11 unpriced, got %+v", valuation)
+	}
+	if !valuation.UnrealizedPnl.Equal(decimal.NewFromInt(50)) {
+		t.Errorf("expected an unrealized gain of 50, got %s", valuation.UnrealizedPnl)
+	}
+
+	summary, err := summaries.FindByPosition(ctx, "VALE3", "stock", "xp")
+	if err != nil || !summary.MarketValue.Equal(decimal.NewFromInt(550)) {
+		t.Fatalf("expected the market value to be stored, got %s (%v)", summary.MarketValue, err)
+	}
+
+	// a second run on the same day replaces the snapshot
+	if _, err := service.Revalue(ctx, today); err != nil {
+		t.Fatalf("failure to revalue: %v", err)
+	}
+	history, err := summaries.FindHistory(ctx, "1")
+	if err != nil {
+		t.Fatalf("failure to read history: %v", err)
+	}
+	if len(history) != 1 || history[0].LastTransactionDate.Format("2006-01-02") != "2025-03-07" || history[0].LastTransactionType != investment_summary_core.ValuationSnapshot {
+		t.Errorf("expected one valuation snapshot on the quote date, got %+v", history)
+	}
+
+	current, err := NewService(summaries, nil).Current(ctx, today)
+	if err != nil {
+		t.Fatalf("failure to read valuation: %v", err)
+	}
+	if !current.MarketValue.Equal(decimal.NewFromInt(550)) || len(current.Unpriced) != 1 {
+		t.Errorf("expected the stored valuation, got %+v", current)
+	}
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/silasstoffel/invest-tracker/apps/shared/database"
+	"github.com/silasstoffel/invest-tracker/apps/shared/quotes"
+	"github.com/silasstoffel/invest-tracker/apps/shared/repository"
+	"github.com/silasstoffel/invest-tracker/apps/shared/telegram"
+	valuation_service "github.com/silasstoffel/invest-tracker/apps/valuation/service"
+	appConfig "github.com/silasstoffel/invest-tracker/config"
+)
+
+var (
+	env     *appConfig.Config
+	service *valuation_service.Service
+)
+
+func init() {
+	env = appConfig.NewConfigFromEnvVars()
+
+	db, err := database.NewFromConfig(env)
+	if err != nil {
+		m := fmt.Sprintf("Failure to connect to database: %v", err)
+		log.Println(m)
+		panic(m)
+	}
+
+	provider, err := quotes.NewFromConfig(env)
+	if err != nil {
+		m := fmt.Sprintf("Failure to create quote provider: %v", err)
+		log.Println(m)
+		panic(m)
+	}
+
+	service = valuation_service.NewService(repository.NewSummaryRepository(db), provider)
+}
+
+func Handler(ctx context.Context) error {
+	prefix := os.Getenv("AWS_LAMBDA_FUNCTION_NAME")
+	tb := telegram.NewTelegramBot(env)
+
+	valuation, err := service.Revalue(ctx, time.Now())
+	if err != nil {
+		log.Printf("Failure to update market values: %v", err)
+		tb.SendMessage(fmt.Sprintf("*[%s] Failure to update market values* ```%s```", prefix, err.Error()))
+		return err
+	}
+
+	log.Printf("Updated the market value of %d position(s), %d without price", len(valuation.Positions), len(valuation.Unpriced))
+
+	message := fmt.Sprintf("*[%s] Portfolio valuation %s*\nInvested: R$ %s\nMarket value: R$ %s\nUnrealized: R$ %s (%s%%)",
+		prefix, valuation.Date,
+		valuation.TotalValue.StringFixed(2), valuation.MarketValue.StringFixed(2),
+		valuation.UnrealizedPnl.StringFixed(2), valuation.UnrealizedPnlPercentage.StringFixed(2))
+	if len(valuation.Unpriced) > 0 {
+		message += fmt.Sprintf("\nNo price for: %s", strings.Join(valuation.Unpriced, ", "))
+	}
+	tb.SendMessage(message)
+
+	return nil
+}
+
+func main() {
+	lambda.Start(Handler)
+}
@@ -16,6 +16,19 @@ type CloudflareConfig struct {
 	ApiKey              string
 }
 
+type DatabaseConfig struct {
+	// d1 (default) or sqlite
+	Driver     string
+	SQLitePath string
+}
+
+type QuotesConfig struct {
+	// csv (default)
+	Provider string
+	// path or http(s) url of the quotes file
+	Source string
+}
+
 type Aws struct{}
 
 type TelegramConfig struct {
@@ -28,6 +41,8 @@ type Config struct {
 	CreateInvestmentQueueURL      string
 	CalculateAveragePriceQueueURL string
 	Cloudflare                    CloudflareConfig
+	Database                      DatabaseConfig
+	Quotes                        QuotesConfig
 	Aws                           *Aws
 	TelegramConfig                *TelegramConfig
 }
@@ -49,6 +64,14 @@ func NewConfigFromEnvVars() *Config {
 			InvestmentTrackDbId: os.Getenv("CLOUDFLARE_DB_ID"),
 			ApiKey:              os.Getenv("CLOUDFLARE_API_KEY"),
 		},
+		Database: DatabaseConfig{
+			Driver:     strings.ToLower(os.Getenv("DATABASE_DRIVER")),
+			SQLitePath: os.Getenv("SQLITE_DATABASE_PATH"),
+		},
+		Quotes: QuotesConfig{
+			Provider: strings.ToLower(os.Getenv("QUOTES_PROVIDER")),
+			Source:   os.Getenv("QUOTES_SOURCE"),
+		},
 		Aws: &Aws{},
 		TelegramConfig: &TelegramConfig{
 			Token:  os.Getenv("TELEGRAM_TOKEN"),